@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -99,8 +100,18 @@ type App struct {
 	brewService       brew.Service
 	i18nManager       *i18n.Manager
 	eventEmitter      *wailsEventEmitter
+	outdatedScheduler *brew.OutdatedScheduler
+	autoUpgrader      *brew.AutoUpgrader
+	autoUpgradeLog    *brew.AutoUpgradeHistory
+	snapshots         *brew.SnapshotStore
+	journal           *brew.OperationJournal
+	operationQueue    *brew.OperationQueue
 }
 
+// outdatedCheckStartupDelay gives the frontend time to load and subscribe
+// before the first scheduled outdated check reports its result.
+const outdatedCheckStartupDelay = 10 * time.Second
+
 // detectBrewPathByArchitecture detects the brew binary path based on system architecture
 // Returns the default path for the architecture, prioritizing architecture-specific paths
 func detectBrewPathByArchitecture() string {
@@ -342,9 +353,49 @@ func (a *App) startup(ctx context.Context) {
 	// Wails requires this exact context instance for EventsEmit to work
 	a.eventEmitter = &wailsEventEmitter{ctx: ctx}
 
+	// Every queued operation is journaled next to the config file, so the
+	// history survives restarts.
+	a.journal = brew.NewOperationJournal(a.configSibling("operation-journal.jsonl"))
+
+	// One queue for the app's lifetime: reconfigureBrew replaces the service,
+	// and a second queue would let its jobs run alongside the old one's.
+	a.operationQueue = brew.NewOperationQueue(a.eventEmitter, a.GetTranslation)
+
 	// Initialize brew executor + service with all dependencies
 	a.reconfigureBrew()
 
+	// Keep the outdated list and dock badge current in the background. The
+	// closures look up brewService on every call since reconfigureBrew
+	// replaces it when settings change.
+	a.outdatedScheduler = brew.NewOutdatedScheduler(
+		func() error { return a.brewService.UpdateBrewDatabase() },
+		func() ([]brew.OutdatedPackage, error) { return a.brewService.GetOutdatedPackages() },
+		a.outdatedCheckInterval,
+		a.eventEmitter,
+		func(diff brew.OutdatedDiff) { system.SetDockBadgeCount(diff.Count) },
+		a.sessionLogManager.Append,
+	)
+	a.outdatedScheduler.Start(ctx, outdatedCheckStartupDelay)
+
+	// Unattended upgrades run through the same queued upgrade as the UI.
+	// Their history sits next to the config file.
+	a.snapshots = brew.NewSnapshotStore(a.configSibling("snapshots"))
+	a.autoUpgradeLog = brew.NewAutoUpgradeHistory(a.configSibling("auto-upgrade-history.json"))
+	a.autoUpgrader = brew.NewAutoUpgrader(
+		a.autoUpgradeSettings,
+		func() error { return a.brewService.UpdateBrewDatabase() },
+		func() ([]brew.OutdatedPackage, error) { return a.brewService.GetOutdatedPackages() },
+		func(ctx context.Context, packageNames []string) brew.UpgradeResult {
+			return a.brewService.UpgradeSelectedPackages(ctx, packageNames)
+		},
+		a.GetAutoCleanupAfterUpgrade,
+		func() string { return a.brewService.RunBrewCleanup() },
+		a.autoUpgradeLog,
+		a.eventEmitter,
+		a.sessionLogManager.Append,
+	)
+	a.autoUpgrader.Start(ctx)
+
 	// Restore last-known window position. Width/Height (and maximized state)
 	// are already applied via options.App in main.go to avoid first-frame
 	// flicker; Wails v2 has no initial-position option, so position is
@@ -387,6 +438,12 @@ func (a *App) shutdown(ctx context.Context) {
 		}
 	}
 
+	if a.autoUpgrader != nil {
+		a.autoUpgrader.Stop()
+	}
+	if a.outdatedScheduler != nil {
+		a.outdatedScheduler.Stop()
+	}
 	if a.askpassManager != nil {
 		a.askpassManager.Cleanup()
 	}
@@ -496,19 +553,19 @@ func (a *App) GetAllBrewCasks() [][]string {
 }
 
 func (a *App) GetBrewPackages() [][]string {
-	return a.brewService.GetBrewPackages()
+	return brew.FormulaRows(a.brewService.GetInstalledFormulae())
 }
 
 func (a *App) GetBrewCasks() [][]string {
-	return a.brewService.GetBrewCasks()
+	return brew.CaskRows(a.brewService.GetInstalledCasks())
 }
 
 func (a *App) GetBrewLeaves() []string {
-	return a.brewService.GetBrewLeaves()
+	return brew.LeafNames(a.brewService.GetLeaves())
 }
 
 func (a *App) GetBrewTaps() [][]string {
-	return a.brewService.GetBrewTaps()
+	return brew.TapRows(a.brewService.GetTaps())
 }
 
 func (a *App) GetBrewTapInfo(repositoryName string) string {
@@ -542,7 +599,7 @@ func (a *App) CheckForNewPackages() (*NewPackagesInfo, error) {
 func (a *App) GetBrewUpdatablePackages() [][]string {
 	// Note: Database update is now handled separately via GetStartupDataWithUpdate
 	// or UpdateBrewDatabase to avoid redundant calls during startup
-	return a.brewService.GetBrewUpdatablePackages()
+	return brew.OutdatedRows(a.brewService.GetOutdatedPackages())
 }
 
 // GetBrewUpdatablePackagesWithUpdate updates the database first, then gets updatable packages
@@ -594,17 +651,33 @@ func (a *App) GetBrewUpdatablePackagesWithUpdate() [][]string {
 	_ = updateOutput // Suppress unused variable warning
 	_ = err          // Suppress unused variable warning
 
-	return a.brewService.GetBrewUpdatablePackages()
+	return brew.OutdatedRows(a.brewService.GetOutdatedPackages())
 }
 
 func (a *App) InstallBrewPackage(packageName string) string {
 	return a.brewService.InstallBrewPackage(a.ctx, packageName)
 }
 
+// InstallBrewPackageVersion installs an older version of a formula through
+// `brew extract` into WailBrew's local tap, optionally pinning it. Progress
+// streams through "packageInstallProgress".
+func (a *App) InstallBrewPackageVersion(packageName string, version string, pin bool) string {
+	return a.brewService.InstallBrewPackageVersion(a.ctx, packageName, strings.TrimSpace(version), pin)
+}
+
 func (a *App) RemoveBrewPackage(packageName string, zap bool) string {
 	return a.brewService.RemoveBrewPackage(a.ctx, packageName, zap)
 }
 
+// UninstallPlan describes what removing packages would do
+type UninstallPlan = brew.UninstallPlan
+
+// PlanUninstall reports blocking dependents, orphaned dependencies, affected
+// services and reclaimed space before RemoveBrewPackage runs
+func (a *App) PlanUninstall(packageNames []string) (*UninstallPlan, error) {
+	return a.brewService.PlanUninstall(packageNames)
+}
+
 func (a *App) UpdateBrewPackage(packageName string) string {
 	return a.brewService.UpdateBrewPackage(a.ctx, packageName)
 }
@@ -613,10 +686,67 @@ func (a *App) UpdateSelectedBrewPackages(packageNames []string) string {
 	return a.brewService.UpdateSelectedBrewPackages(a.ctx, packageNames)
 }
 
+// UpdateAllBrewPackages upgrades everything outdated, taking an automatic
+// snapshot first so the previous state can be compared or restored.
 func (a *App) UpdateAllBrewPackages() string {
+	if _, err := a.saveSnapshot(a.GetTranslation("backend.snapshot.autoLabel", map[string]string{}), true); err != nil {
+		a.sessionLogManager.Append(fmt.Sprintf("Snapshot before upgrade failed: %v", err))
+	}
 	return a.brewService.UpdateAllBrewPackages(a.ctx)
 }
 
+// PinBrewPackage pins a formula at its installed version so upgrades skip it.
+func (a *App) PinBrewPackage(packageName string) string {
+	return a.brewService.PinBrewPackage(a.ctx, packageName)
+}
+
+// UnpinBrewPackage lets upgrades touch a pinned formula again.
+func (a *App) UnpinBrewPackage(packageName string) string {
+	return a.brewService.UnpinBrewPackage(a.ctx, packageName)
+}
+
+// ReinstallBrewPackage reinstalls a package, e.g. after its keg broke.
+// Progress streams through "packageReinstallProgress".
+func (a *App) ReinstallBrewPackage(packageName string) string {
+	return a.brewService.ReinstallBrewPackage(a.ctx, packageName)
+}
+
+// LinkBrewPackage symlinks a formula into the prefix. overwrite deletes
+// conflicting files; force is required for keg-only formulae. Progress
+// streams through "packageLinkProgress".
+func (a *App) LinkBrewPackage(packageName string, overwrite bool, force bool) string {
+	return a.brewService.LinkBrewPackage(a.ctx, packageName, overwrite, force)
+}
+
+// UnlinkBrewPackage removes a formula's symlinks from the prefix.
+func (a *App) UnlinkBrewPackage(packageName string) string {
+	return a.brewService.UnlinkBrewPackage(a.ctx, packageName)
+}
+
+// LinkPreview is what linking a formula would do
+type LinkPreview = brew.LinkPreview
+
+// PreviewLink lists the files linking a formula would create and the
+// existing files in their way, without changing anything.
+func (a *App) PreviewLink(packageName string) (*LinkPreview, error) {
+	return a.brewService.PreviewLink(packageName)
+}
+
+// FormulaFamily is a formula shipped in several versions side by side
+type FormulaFamily = brew.FormulaFamily
+
+// GetFormulaFamilies groups the installed versioned formulae, such as node
+// and node@18, and reports which member of each is linked.
+func (a *App) GetFormulaFamilies() ([]FormulaFamily, error) {
+	return a.brewService.GetFormulaFamilies()
+}
+
+// SwitchFormulaVersion makes target the linked member of family, installing
+// it first if needed and moving any running service of the family over.
+func (a *App) SwitchFormulaVersion(family, target string) string {
+	return a.brewService.SwitchFormulaVersion(a.ctx, family, target)
+}
+
 func (a *App) TapBrewRepository(repositoryName, repositoryURL string) string {
 	return a.brewService.TapBrewRepository(a.ctx, repositoryName, repositoryURL)
 }
@@ -630,10 +760,55 @@ func (a *App) TrustBrewTap(tapName string) string {
 	return a.brewService.TrustBrewTap(a.ctx, tapName)
 }
 
+// CancelOperation stops a running brew operation or drops it from the queue if
+// it has not started yet. IDs come from the "operationStarted" and
+// "operationQueueChanged" events; false means it had already finished.
+func (a *App) CancelOperation(id string) bool {
+	return a.brewService.CancelOperation(id)
+}
+
+// Operation is a queued or running mutating brew command.
+type Operation = brew.Operation
+
+// GetOperationQueue returns the operation queue, running job first.
+func (a *App) GetOperationQueue() []Operation {
+	return a.brewService.GetOperationQueue()
+}
+
+// MoveQueuedOperation moves a pending operation to index among the pending jobs.
+func (a *App) MoveQueuedOperation(id string, index int) error {
+	return a.brewService.MoveQueuedOperation(id, index)
+}
+
+// RemoveQueuedOperation drops a pending operation from the queue.
+func (a *App) RemoveQueuedOperation(id string) error {
+	return a.brewService.RemoveQueuedOperation(id)
+}
+
+// GetOperationHistory pages through the journal of past operations, newest
+// first. It survives restarts, unlike the session log.
+func (a *App) GetOperationHistory(filter brew.JournalFilter) (*brew.JournalPage, error) {
+	return a.brewService.GetOperationHistory(filter)
+}
+
+// GetLastUndoableOperation returns the journal entry UndoLastOperation would
+// reverse, with its inverse, or nil when there is nothing to undo.
+func (a *App) GetLastUndoableOperation() (*brew.JournalEntry, error) {
+	return a.brewService.GetLastUndoableOperation()
+}
+
+// UndoLastOperation reverses the last operation. Progress streams through
+// "undoProgress" and the regular action events; "undoComplete" carries the
+// outcome.
+func (a *App) UndoLastOperation() string {
+	return a.brewService.UndoLastOperation(a.ctx)
+}
+
 // PreviewBrewCommand returns the exact brew command an action will run, so the
 // confirmation dialogs can show it. Supported actions: install, uninstall,
-// upgrade, upgrade-selected, upgrade-all, tap, untap, trust. For tap, targets is
-// [name] or [name, url]; all other single-target actions take [name].
+// upgrade, upgrade-selected, upgrade-all, tap, untap, trust, pin, unpin,
+// reinstall, link, unlink. For tap, targets is [name] or [name, url]; all
+// other single-target actions take [name].
 func (a *App) PreviewBrewCommand(action string, targets []string, isCask bool, zap bool) string {
 	target := ""
 	if len(targets) > 0 {
@@ -662,7 +837,11 @@ func (a *App) PreviewBrewCommand(action string, targets []string, isCask bool, z
 		}
 		return brew.FormatCommand(brew.BuildUpgradeSelectedArgs(targets))
 	case "upgrade-all":
-		return brew.FormatCommand(brew.BuildUpgradeAllArgs(a.GetOutdatedFlag()))
+		args, err := a.brewService.UpgradeAllArgs()
+		if err != nil || args == nil {
+			return ""
+		}
+		return brew.FormatCommand(args)
 	case "tap":
 		if target == "" {
 			return ""
@@ -682,14 +861,58 @@ func (a *App) PreviewBrewCommand(action string, targets []string, isCask bool, z
 			return ""
 		}
 		return brew.FormatCommand(brew.BuildTrustArgs(target))
+	case "pin":
+		if target == "" {
+			return ""
+		}
+		return brew.FormatCommand(brew.BuildPinArgs(target))
+	case "unpin":
+		if target == "" {
+			return ""
+		}
+		return brew.FormatCommand(brew.BuildUnpinArgs(target))
+	case "reinstall":
+		if target == "" {
+			return ""
+		}
+		return brew.FormatCommand(brew.BuildReinstallArgs(target))
+	case "link":
+		if target == "" {
+			return ""
+		}
+		return brew.FormatCommand(brew.BuildLinkArgs(target, false, false))
+	case "unlink":
+		if target == "" {
+			return ""
+		}
+		return brew.FormatCommand(brew.BuildUnlinkArgs(target))
 	default:
 		return ""
 	}
 }
 
+// InstallPreview is what an install would add
+type InstallPreview = brew.InstallPreview
+
+// PreviewInstall lists the package and its missing dependencies with bottle
+// availability, known download sizes and caveats before installing.
+func (a *App) PreviewInstall(packageName string) (*InstallPreview, error) {
+	return a.brewService.PreviewInstall(a.ctx, packageName)
+}
+
+// UpgradePreview is what an upgrade would change
+type UpgradePreview = brew.UpgradePreview
+
+// PreviewUpgrade dry-runs an upgrade of packageNames, or of everything
+// outdated when empty, so the confirmation dialog can list each version
+// change including the dependencies it pulls in.
+func (a *App) PreviewUpgrade(packageNames []string) (*UpgradePreview, error) {
+	return a.brewService.PreviewUpgrade(packageNames)
+}
+
 // GetBrewServices returns all Homebrew-managed services as rows of [name, status, user].
 func (a *App) GetBrewServices() [][]string {
-	return a.brewService.GetBrewServices()
+	return brew.ServiceRows(a.brewService.GetServices())
 }
 
 // GetBrewServiceInfo returns the raw `brew services info <name>` output.
@@ -738,6 +961,23 @@ func (a *App) GetInstalledDependents(packageName string) []string {
 	return a.brewService.GetInstalledDependents(packageName)
 }
 
+// GetTransitiveDependents returns every installed package that would break
+// if packageName were removed, directly or through other dependencies.
+func (a *App) GetTransitiveDependents(packageName string) []string {
+	dependents, err := a.brewService.GetDependents(packageName, true)
+	if err != nil {
+		return []string{}
+	}
+	return dependents
+}
+
+// DependencyNode is one package in a dependency tree
+type DependencyNode = brew.DependencyNode
+
+func (a *App) GetDependencyTree(packageName string) (*DependencyNode, error) {
+	return a.brewService.GetDependencyTree(packageName)
+}
+
 func (a *App) RunBrewDoctor() string {
 	return a.brewService.RunBrewDoctor()
 }
@@ -758,6 +998,24 @@ func (a *App) RunBrewCleanup() string {
 	return a.brewService.RunBrewCleanup()
 }
 
+// OrphanedFormula is a dependency nothing installed needs anymore
+type OrphanedFormula = brew.OrphanedFormula
+
+// GetAutoremoveDryRun lists the formulae RunAutoremove would uninstall
+func (a *App) GetAutoremoveDryRun() ([]OrphanedFormula, error) {
+	return a.brewService.GetAutoremoveDryRun()
+}
+
+func (a *App) RunAutoremove() string {
+	return a.brewService.RunAutoremove(a.ctx)
+}
+
+// MarkInstalledOnRequest keeps packages out of autoremove by recording them
+// as explicitly installed
+func (a *App) MarkInstalledOnRequest(packageNames []string) string {
+	return a.brewService.MarkInstalledOnRequest(a.ctx, packageNames)
+}
+
 func (a *App) GetHomebrewVersion() (string, error) {
 	return a.brewService.GetHomebrewVersion()
 }
@@ -778,6 +1036,176 @@ func (a *App) ExportBrewfile(filePath string) error {
 	return a.brewService.ExportBrewfile(filePath)
 }
 
+// ExportFilteredBrewfile writes a Brewfile with only leaf formulae, only
+// favorites, or both, without running brew bundle.
+func (a *App) ExportFilteredBrewfile(filePath string, leavesOnly bool, favoritesOnly bool) error {
+	options := brew.BrewfileExportOptions{LeavesOnly: leavesOnly}
+	if favoritesOnly {
+		if len(a.config.Favorites) == 0 {
+			return fmt.Errorf("no favorites to export")
+		}
+		options.Only = a.config.Favorites
+	}
+	return a.brewService.ExportFilteredBrewfile(filePath, options)
+}
+
+// PreviewBrewfile reports which entries of the Brewfile at filePath are
+// missing or outdated, without changing anything.
+func (a *App) PreviewBrewfile(filePath string) (*brew.BrewfilePreview, error) {
+	return a.brewService.PreviewBrewfile(filePath)
+}
+
+// ApplyBrewfile installs everything the Brewfile at filePath lists, streaming
+// progress through "brewfileApplyProgress".
+func (a *App) ApplyBrewfile(filePath string) string {
+	return a.brewService.ApplyBrewfile(a.ctx, filePath)
+}
+
+// GetBrewfileCleanup lists the installed formulae, casks and taps the
+// Brewfile at filePath does not mention.
+func (a *App) GetBrewfileCleanup(filePath string) ([]brew.BrewfileCleanupItem, error) {
+	return a.brewService.GetBrewfileCleanup(filePath)
+}
+
+// RunBrewfileCleanup removes the items of GetBrewfileCleanup the user
+// confirmed. Names that were not offered for removal are refused.
+func (a *App) RunBrewfileCleanup(filePath string, confirmed []string) string {
+	return a.brewService.RunBrewfileCleanup(a.ctx, filePath, confirmed)
+}
+
+// ListSnapshots returns the stored snapshots, newest first.
+func (a *App) ListSnapshots() ([]brew.SnapshotSummary, error) {
+	if a.snapshots == nil {
+		return []brew.SnapshotSummary{}, nil
+	}
+	return a.snapshots.List()
+}
+
+// CreateSnapshot records what is installed right now under label.
+func (a *App) CreateSnapshot(label string) (*brew.SnapshotSummary, error) {
+	return a.saveSnapshot(strings.TrimSpace(label), false)
+}
+
+func (a *App) saveSnapshot(label string, auto bool) (*brew.SnapshotSummary, error) {
+	if a.snapshots == nil {
+		return nil, fmt.Errorf("snapshots are not available yet")
+	}
+	snapshot, err := a.brewService.CaptureSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Label = label
+	snapshot.Auto = auto
+	if err := a.snapshots.Save(snapshot); err != nil {
+		return nil, err
+	}
+	summary := snapshot.Summary()
+	return &summary, nil
+}
+
+// DeleteSnapshot removes a stored snapshot.
+func (a *App) DeleteSnapshot(id string) error {
+	if a.snapshots == nil {
+		return brew.ErrSnapshotNotFound
+	}
+	return a.snapshots.Delete(id)
+}
+
+// DiffSnapshots compares two snapshots. An empty ID stands for the live
+// system, so DiffSnapshots(id, "") shows what changed since id was taken.
+func (a *App) DiffSnapshots(fromID string, toID string) (*brew.SnapshotDiff, error) {
+	from, err := a.loadSnapshot(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := a.loadSnapshot(toID)
+	if err != nil {
+		return nil, err
+	}
+	return brew.DiffSnapshots(from, to), nil
+}
+
+func (a *App) loadSnapshot(id string) (*brew.Snapshot, error) {
+	if id == "" {
+		return a.brewService.CaptureSnapshot()
+	}
+	return a.storedSnapshot(id)
+}
+
+func (a *App) storedSnapshot(id string) (*brew.Snapshot, error) {
+	if a.snapshots == nil || id == "" {
+		return nil, brew.ErrSnapshotNotFound
+	}
+	return a.snapshots.Load(id)
+}
+
+// RestoreSnapshot re-taps and reinstalls what the snapshot has and the
+// system lacks, streaming progress through "snapshotRestoreProgress".
+func (a *App) RestoreSnapshot(id string) string {
+	snapshot, err := a.storedSnapshot(id)
+	if err != nil {
+		message := a.GetTranslation("backend.snapshot.restoreFailed", map[string]string{"error": err.Error()})
+		a.eventEmitter.Emit("snapshotRestoreProgress", message)
+		a.eventEmitter.Emit("snapshotRestoreComplete", message)
+		return message
+	}
+	return a.brewService.RestoreSnapshot(a.ctx, snapshot)
+}
+
+// ImportSnapshot asks for a snapshot file, typically exported on another
+// machine, and adds it to the store. It returns nil when cancelled.
+func (a *App) ImportSnapshot() (*brew.SnapshotSummary, error) {
+	if a.snapshots == nil {
+		return nil, fmt.Errorf("snapshots are not available yet")
+	}
+	path, err := rt.OpenFileDialog(a.ctx, rt.OpenDialogOptions{
+		Title:   a.GetTranslation("dialogs.snapshots.importTitle", map[string]string{}),
+		Filters: []rt.FileFilter{{DisplayName: "JSON (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return nil, err
+	}
+	snapshot, err := a.snapshots.Import(path)
+	if err != nil {
+		return nil, err
+	}
+	summary := snapshot.Summary()
+	return &summary, nil
+}
+
+// ExportSnapshot asks where to save a copy of the snapshot, for importing
+// on another machine. It returns an empty path when cancelled.
+func (a *App) ExportSnapshot(id string) (string, error) {
+	snapshot, err := a.storedSnapshot(id)
+	if err != nil {
+		return "", err
+	}
+	path, err := rt.SaveFileDialog(a.ctx, rt.SaveDialogOptions{
+		Title:           a.GetTranslation("dialogs.snapshots.exportTitle", map[string]string{}),
+		DefaultFilename: fmt.Sprintf("wailbrew-snapshot-%s.json", snapshot.ID),
+		Filters:         []rt.FileFilter{{DisplayName: "JSON (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// ExportDependencyGraph writes the installed dependency graph to filePath as
+// "json", "dot" or "mermaid". With packageNames set, only those packages and
+// their dependencies are exported.
+func (a *App) ExportDependencyGraph(filePath string, format string, packageNames []string, includeBuild bool) error {
+	return a.brewService.ExportDependencyGraph(filePath, brew.GraphExportOptions{
+		Format:       format,
+		Roots:        packageNames,
+		IncludeBuild: includeBuild,
+	})
+}
+
 func (a *App) OpenConfigFile() error {
 	configPath, err := a.config.ResolvedPath()
 	if err != nil {
@@ -864,14 +1292,111 @@ func (a *App) SetAutoRelaunch(val bool) error {
 	return a.config.Save()
 }
 
+// outdatedCheckInterval is the configured background check interval.
+func (a *App) outdatedCheckInterval() time.Duration {
+	return time.Duration(a.config.OutdatedCheckMinutes()) * time.Minute
+}
+
+// GetOutdatedCheckInterval returns the minutes between background outdated
+// checks, 0 when they are disabled.
+func (a *App) GetOutdatedCheckInterval() int {
+	return a.config.OutdatedCheckMinutes()
+}
+
+// SetOutdatedCheckInterval changes the minutes between background outdated
+// checks and restarts the wait for the next one. 0 disables them.
+func (a *App) SetOutdatedCheckInterval(minutes int) error {
+	if minutes < 0 {
+		return fmt.Errorf("invalid outdated check interval: %d", minutes)
+	}
+	a.config.SetOutdatedCheckMinutes(minutes)
+	if err := a.config.Save(); err != nil {
+		return err
+	}
+	if a.outdatedScheduler != nil {
+		a.outdatedScheduler.Reschedule()
+	}
+	return nil
+}
+
+// GetNextOutdatedCheck returns when the next background outdated check runs
+// in Unix milliseconds, or 0 when none is scheduled.
+func (a *App) GetNextOutdatedCheck() int64 {
+	if a.outdatedScheduler == nil {
+		return 0
+	}
+	next := a.outdatedScheduler.NextCheck()
+	if next.IsZero() {
+		return 0
+	}
+	return next.UnixMilli()
+}
+
+// autoUpgradeSettings converts the configured auto-upgrade settings.
+func (a *App) autoUpgradeSettings() brew.AutoUpgradeSettings {
+	c := a.config.AutoUpgradeSettings()
+	return brew.AutoUpgradeSettings{
+		Enabled:     c.Enabled,
+		Days:        c.Days,
+		WindowStart: c.WindowStart,
+		WindowEnd:   c.WindowEnd,
+		Allow:       c.Allow,
+		Deny:        c.Deny,
+		MaxPackages: c.MaxPackages,
+	}
+}
+
+// GetAutoUpgrade returns the unattended upgrade settings.
+func (a *App) GetAutoUpgrade() config.AutoUpgrade {
+	return a.config.AutoUpgradeSettings()
+}
+
+// SetAutoUpgrade validates and saves the unattended upgrade settings.
+func (a *App) SetAutoUpgrade(settings config.AutoUpgrade) error {
+	if _, err := brew.ParseMaintenanceWindow(settings.Days, settings.WindowStart, settings.WindowEnd); err != nil {
+		return err
+	}
+	if settings.MaxPackages < 0 {
+		return fmt.Errorf("invalid package limit: %d", settings.MaxPackages)
+	}
+	a.config.SetAutoUpgrade(settings)
+	return a.config.Save()
+}
+
+// GetAutoUpgradeRuns returns the recorded unattended upgrade runs, oldest
+// first.
+func (a *App) GetAutoUpgradeRuns() ([]brew.AutoUpgradeRun, error) {
+	if a.autoUpgradeLog == nil {
+		return []brew.AutoUpgradeRun{}, nil
+	}
+	return a.autoUpgradeLog.Runs()
+}
+
+// GetAutoUpgradeFailures returns failed unattended upgrade runs the user has
+// not dismissed yet.
+func (a *App) GetAutoUpgradeFailures() ([]brew.AutoUpgradeRun, error) {
+	if a.autoUpgradeLog == nil {
+		return []brew.AutoUpgradeRun{}, nil
+	}
+	return a.autoUpgradeLog.Failures()
+}
+
+// AcknowledgeAutoUpgradeFailures dismisses the failed-run notification.
+func (a *App) AcknowledgeAutoUpgradeFailures() error {
+	if a.autoUpgradeLog == nil {
+		return nil
+	}
+	return a.autoUpgradeLog.Acknowledge()
+}
+
 // GetAutoCleanupAfterUpgrade returns whether `brew cleanup` should run
 // automatically after an upgrade completes.
 func (a *App) GetAutoCleanupAfterUpgrade() bool {
-	return a.config.AutoCleanupAfterUpgrade
+	return a.config.CleanupAfterUpgrade()
 }
 
 func (a *App) SetAutoCleanupAfterUpgrade(val bool) error {
-	a.config.AutoCleanupAfterUpgrade = val
+	a.config.SetCleanupAfterUpgrade(val)
 	return a.config.Save()
 }
 
@@ -906,6 +1431,24 @@ func (a *App) ToggleFavorite(name string) error {
 	return a.config.Save()
 }
 
+// GetUpgradePolicy returns the upgrade policy configured for a package, or ""
+// when upgrade-all may install any version.
+func (a *App) GetUpgradePolicy(name string) string {
+	return a.config.UpgradePolicySnapshot()[name]
+}
+
+// SetUpgradePolicy limits which versions upgrade-all may move a package to:
+// "never", "patch", "minor" or "skip:<version>". An empty policy removes it.
+func (a *App) SetUpgradePolicy(name, policy string) error {
+	policy = strings.TrimSpace(policy)
+	if !brew.ValidUpgradePolicy(policy) {
+		return fmt.Errorf("invalid upgrade policy %q", policy)
+	}
+	a.config.SetUpgradePolicy(name, policy)
+	a.brewService.ClearCache()
+	return a.config.Save()
+}
+
 // GetSortFavoritesToTop returns whether favorited packages should be pinned to the top of package tables.
 func (a *App) GetSortFavoritesToTop() bool {
 	return a.config.SortFavoritesToTop
@@ -928,7 +1471,7 @@ func (a *App) SetProxy(proxy string) error {
 
 	// Update brew executor with new environment
 	if a.brewExecutor != nil {
-		a.brewExecutor = brew.NewExecutor(a.brewPath, a.getBrewEnv(), a.sessionLogManager.Append)
+		a.brewExecutor.SetEnvironment(a.getBrewEnv())
 	}
 
 	return nil
@@ -997,14 +1540,14 @@ func (a *App) SetMirrorSource(gitRemote string, bottleDomain string) error {
 
 	// Update brew executor with new environment
 	if a.brewExecutor != nil {
-		a.brewExecutor = brew.NewExecutor(a.brewPath, a.getBrewEnv(), a.sessionLogManager.Append)
+		a.brewExecutor.SetEnvironment(a.getBrewEnv())
 	}
 
 	return nil
 }
 
 func (a *App) GetOutdatedFlag() string {
-	flag := a.config.OutdatedFlag
+	flag := a.config.OutdatedMode()
 	if flag == "" {
 		return "greedy-auto-updates"
 	}
@@ -1021,7 +1564,7 @@ func (a *App) SetOutdatedFlag(flag string) error {
 		return fmt.Errorf("invalid outdated flag: must be 'none', 'greedy', or 'greedy-auto-updates'")
 	}
 
-	a.config.OutdatedFlag = flag
+	a.config.SetOutdatedMode(flag)
 
 	if err := a.config.Save(); err != nil {
 		return fmt.Errorf("failed to save config: %v", err)
@@ -1063,7 +1606,7 @@ func (a *App) SetCaskAppDir(appDir string) error {
 
 	// Update brew executor with new environment
 	if a.brewExecutor != nil {
-		a.brewExecutor = brew.NewExecutor(a.brewPath, a.getBrewEnv(), a.sessionLogManager.Append)
+		a.brewExecutor.SetEnvironment(a.getBrewEnv())
 	}
 
 	return nil
@@ -1105,7 +1648,7 @@ func (a *App) SetCustomCaskOpts(opts string) error {
 
 	// Update brew executor with new environment
 	if a.brewExecutor != nil {
-		a.brewExecutor = brew.NewExecutor(a.brewPath, a.getBrewEnv(), a.sessionLogManager.Append)
+		a.brewExecutor.SetEnvironment(a.getBrewEnv())
 	}
 
 	return nil
@@ -1155,11 +1698,26 @@ func (a *App) SetAdminUsername(username string) error {
 		}
 	}
 
+	// SUDO_ASKPASS points at the helper, so refresh the brew environment too
+	if a.brewExecutor != nil {
+		a.brewExecutor.SetEnvironment(a.getBrewEnv())
+	}
+
 	return nil
 }
 
+// configSibling resolves name next to the config file, falling back to the
+// working directory when the config path is unknown.
+func (a *App) configSibling(name string) string {
+	if configPath, err := a.config.ResolvedPath(); err == nil {
+		return filepath.Join(filepath.Dir(configPath), name)
+	}
+	return name
+}
+
 // reconfigureBrew (re)creates the brew executor and service from the current
-// a.brewPath. It must be called after a.eventEmitter is set. It is safe to call
+// a.brewPath. It must be called after a.eventEmitter and a.operationQueue are
+// set; the queue is shared by every service it creates. It is safe to call
 // again at runtime (e.g. after SetBrewPath) so a path change fully propagates to
 // the service layer without requiring an app restart.
 func (a *App) reconfigureBrew() {
@@ -1168,7 +1726,6 @@ func (a *App) reconfigureBrew() {
 	a.brewService = brew.NewService(
 		a.brewExecutor,
 		a.brewPath,
-		a.getBrewEnv,
 		a.sessionLogManager.Append,
 		func() error { return a.brewExecutor.ValidateInstallation() },
 		func(key string, params map[string]string) string {
@@ -1180,10 +1737,13 @@ func (a *App) reconfigureBrew() {
 		a.eventEmitter,
 		func() string { return a.GetOutdatedFlag() },
 		func() string { return a.GetCustomOutdatedArgs() },
+		a.config.UpgradePolicySnapshot,
 		brew.ExtractJSONFromOutput,
 		brew.ParseWarnings,
 		func() bool { return a.GetNoQuarantine() },
 		func() bool { return a.GetAutoRelaunch() },
+		a.operationQueue,
+		a.journal,
 	)
 }
 
@@ -1280,38 +1840,6 @@ func (a *App) GetSystemArchitecture() string {
 
 // UPDATE OPERATIONS - App-specific, not brew domain logic
 
-func compareVersions(version1, version2 string) bool {
-	v1 := strings.TrimPrefix(strings.TrimSpace(version1), "v")
-	v2 := strings.TrimPrefix(strings.TrimSpace(version2), "v")
-
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
-
-	maxLen := len(parts1)
-	if len(parts2) > maxLen {
-		maxLen = len(parts2)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		var part1, part2 int
-		if i < len(parts1) {
-			_, _ = fmt.Sscanf(parts1[i], "%d", &part1)
-		}
-		if i < len(parts2) {
-			_, _ = fmt.Sscanf(parts2[i], "%d", &part2)
-		}
-
-		if part1 > part2 {
-			return true
-		}
-		if part1 < part2 {
-			return false
-		}
-	}
-
-	return false
-}
-
 func (a *App) CheckForUpdates() (*UpdateInfo, error) {
 	currentVersion := Version
 
@@ -1331,7 +1859,7 @@ func (a *App) CheckForUpdates() (*UpdateInfo, error) {
 	currentVersionClean := strings.TrimPrefix(currentVersion, "v")
 	homebrewVersionClean := strings.TrimPrefix(homebrewCaskVersion, "v")
 
-	isUpdateAvailable := compareVersions(homebrewVersionClean, currentVersionClean)
+	isUpdateAvailable := brew.CompareVersions(homebrewVersionClean, currentVersionClean) > 0
 
 	return &UpdateInfo{
 		Available:      isUpdateAvailable,
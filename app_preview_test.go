@@ -3,9 +3,27 @@ package main
 import (
 	"testing"
 
+	"WailBrew/backend/brew"
 	"WailBrew/backend/config"
 )
 
+// upgradeAllService stands in for the brew service, which decides the
+// upgrade-all arguments from the outdated list. Only UpgradeAllArgs is
+// implemented: allowed, when set, are the packages left after pinned and
+// policy-held ones are excluded.
+type upgradeAllService struct {
+	brew.Service
+	outdatedFlag string
+	allowed      []string
+}
+
+func (s upgradeAllService) UpgradeAllArgs() ([]string, error) {
+	if s.allowed != nil {
+		return append(brew.BuildUpgradeAllArgs(s.outdatedFlag), s.allowed...), nil
+	}
+	return brew.BuildUpgradeAllArgs(s.outdatedFlag), nil
+}
+
 func TestPreviewBrewCommand(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -30,6 +48,11 @@ func TestPreviewBrewCommand(t *testing.T) {
 		{"tap with url", "", "tap", []string{"user/repo", "https://github.com/user/repo"}, false, false, "brew tap user/repo https://github.com/user/repo"},
 		{"untap", "", "untap", []string{"user/repo"}, false, false, "brew untap user/repo"},
 		{"trust", "", "trust", []string{"user/repo"}, false, false, "brew trust user/repo"},
+		{"pin", "", "pin", []string{"wget"}, false, false, "brew pin wget"},
+		{"unpin", "", "unpin", []string{"wget"}, false, false, "brew unpin wget"},
+		{"reinstall", "", "reinstall", []string{"wget"}, false, false, "brew reinstall wget"},
+		{"link", "", "link", []string{"python@3.12"}, false, false, "brew link python@3.12"},
+		{"unlink", "", "unlink", []string{"python@3.11"}, false, false, "brew unlink python@3.11"},
 		{"unknown action", "", "explode", []string{"wget"}, false, false, ""},
 		{"missing target", "", "uninstall", nil, false, false, ""},
 		{"empty selection", "", "upgrade-selected", nil, false, false, ""},
@@ -37,7 +60,10 @@ func TestPreviewBrewCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			app := &App{config: &config.Config{OutdatedFlag: tt.outdatedFlag}}
+			app := &App{
+				config:      &config.Config{OutdatedFlag: tt.outdatedFlag},
+				brewService: upgradeAllService{outdatedFlag: tt.outdatedFlag},
+			}
 			got := app.PreviewBrewCommand(tt.action, tt.targets, tt.isCask, tt.zap)
 			if got != tt.expected {
 				t.Errorf("PreviewBrewCommand(%q) = %q, want %q", tt.action, got, tt.expected)
@@ -45,3 +71,13 @@ func TestPreviewBrewCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewBrewCommand_upgradeAllExcludesHeldPackages(t *testing.T) {
+	app := &App{
+		config:      &config.Config{OutdatedFlag: "greedy"},
+		brewService: upgradeAllService{outdatedFlag: "greedy", allowed: []string{"wget", "jq"}},
+	}
+	if got, want := app.PreviewBrewCommand("upgrade-all", nil, false, false), "brew upgrade --greedy wget jq"; got != want {
+		t.Errorf("PreviewBrewCommand(upgrade-all) = %q, want %q", got, want)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"WailBrew/backend/system"
@@ -17,8 +16,8 @@ type EventEmitter interface {
 
 // ActionsService provides install/uninstall/update functionality
 type ActionsService struct {
+	runner           Runner
 	brewPath         string
-	getBrewEnvFunc   func() []string
 	getBackendMsg    func(string, map[string]string) string
 	eventEmitter     EventEmitter
 	isPackageCask    func(string) bool
@@ -29,12 +28,14 @@ type ActionsService struct {
 	getNoQuarantine  func() bool
 	getAutoRelaunch  func() bool
 	getCaskAppDir    func() string
+	getOutdated      func() ([]OutdatedPackage, error)
+	getPolicies      func() map[string]string
 }
 
 // NewActionsService creates a new actions service
 func NewActionsService(
+	runner Runner,
 	brewPath string,
-	getBrewEnvFunc func() []string,
 	getBackendMsg func(string, map[string]string) string,
 	eventEmitter EventEmitter,
 	isPackageCask func(string) bool,
@@ -44,10 +45,12 @@ func NewActionsService(
 	getOutdatedFlag func() string,
 	getNoQuarantine func() bool,
 	getAutoRelaunch func() bool,
+	getOutdated func() ([]OutdatedPackage, error),
+	getPolicies func() map[string]string,
 ) *ActionsService {
 	return &ActionsService{
+		runner:           runner,
 		brewPath:         brewPath,
-		getBrewEnvFunc:   getBrewEnvFunc,
 		getBackendMsg:    getBackendMsg,
 		eventEmitter:     eventEmitter,
 		isPackageCask:    isPackageCask,
@@ -57,6 +60,8 @@ func NewActionsService(
 		getOutdatedFlag:  getOutdatedFlag,
 		getNoQuarantine:  getNoQuarantine,
 		getAutoRelaunch:  getAutoRelaunch,
+		getOutdated:      getOutdated,
+		getPolicies:      getPolicies,
 		// getCaskAppDir is populated separately — the App-level setting is not
 		// available at construction time in the current wiring, so we use a
 		// safe no-op default that resolves to /Applications.
@@ -151,14 +156,17 @@ func (s *ActionsService) postInstallCask(packageName, progressEvent string) {
 
 // InstallBrewPackage installs a package with live progress updates
 func (s *ActionsService) InstallBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.installPackage(ctx, packageName)
+	return result
+}
+
+// installPackage is InstallBrewPackage also reporting whether it succeeded.
+func (s *ActionsService) installPackage(ctx context.Context, packageName string) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.install.start", map[string]string{"name": packageName})
 	s.eventEmitter.Emit("packageInstallProgress", startMessage)
 
-	cmd := exec.Command(s.brewPath, BuildInstallArgs(packageName)...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, stderrStr, err := runStreamingCommand(cmd,
+	phase, stderrStr, err := s.runner.Stream(ctx, BuildInstallArgs(packageName),
 		func(line string) { s.eventEmitter.Emit("packageInstallProgress", fmt.Sprintf("📦 %s", line)) },
 		func(line string) { s.eventEmitter.Emit("packageInstallProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -168,17 +176,22 @@ func (s *ActionsService) InstallBrewPackage(ctx context.Context, packageName str
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageInstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageInstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageInstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageInstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingInstall", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageInstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageInstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("packageInstallProgress", cancelMsg)
+		s.eventEmitter.Emit("packageInstallComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		// Homebrew 6: install can be blocked because the package's tap is not
 		// trusted. Surface a distinct event so the UI can offer to trust + retry.
@@ -191,7 +204,7 @@ func (s *ActionsService) InstallBrewPackage(ctx context.Context, packageName str
 		errorMsg := s.getBackendMsg("backend.install.failed", map[string]string{"name": packageName, "error": err.Error()})
 		s.eventEmitter.Emit("packageInstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageInstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	// Success
@@ -204,13 +217,19 @@ func (s *ActionsService) InstallBrewPackage(ctx context.Context, packageName str
 	}
 
 	s.eventEmitter.Emit("packageInstallComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
 
 // RemoveBrewPackage uninstalls a package with live progress updates.
 // When zap is true and the package is a cask, --zap is passed so Homebrew also
 // removes leftover preferences, caches and support files.
 func (s *ActionsService) RemoveBrewPackage(ctx context.Context, packageName string, zap bool) string {
+	result, _ := s.removePackage(ctx, packageName, zap)
+	return result
+}
+
+// removePackage is RemoveBrewPackage also reporting whether it succeeded.
+func (s *ActionsService) removePackage(ctx context.Context, packageName string, zap bool) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.uninstall.start", map[string]string{"name": packageName})
 	s.eventEmitter.Emit("packageUninstallProgress", startMessage)
@@ -219,10 +238,7 @@ func (s *ActionsService) RemoveBrewPackage(ctx context.Context, packageName stri
 	isCask := zap && s.isPackageCask(packageName)
 	args := BuildUninstallArgs(packageName, zap, isCask)
 
-	cmd := exec.Command(s.brewPath, args...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, args,
 		func(line string) { s.eventEmitter.Emit("packageUninstallProgress", fmt.Sprintf("🗑️ %s", line)) },
 		func(line string) { s.eventEmitter.Emit("packageUninstallProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -232,39 +248,41 @@ func (s *ActionsService) RemoveBrewPackage(ctx context.Context, packageName stri
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUninstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageUninstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUninstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageUninstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingUninstall", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUninstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageUninstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("packageUninstallProgress", cancelMsg)
+		s.eventEmitter.Emit("packageUninstallComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		errorMsg := s.getBackendMsg("backend.uninstall.failed", map[string]string{"name": packageName, "error": err.Error()})
 		s.eventEmitter.Emit("packageUninstallProgress", errorMsg)
 		s.eventEmitter.Emit("packageUninstallComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	// Success
 	successMsg := s.getBackendMsg("backend.uninstall.success", map[string]string{"name": packageName})
 	s.eventEmitter.Emit("packageUninstallProgress", successMsg)
 	s.eventEmitter.Emit("packageUninstallComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
 
 // RunUpdateCommand executes the brew upgrade command and returns the result
-func (s *ActionsService) RunUpdateCommand(packageName string, useForce bool) (finalMessage string, wailbrewUpdated bool, shouldRetry bool) {
+func (s *ActionsService) RunUpdateCommand(ctx context.Context, packageName string, useForce bool) (finalMessage string, upgraded, wailbrewUpdated, shouldRetry bool) {
 	args := BuildUpgradeArgs(packageName, s.isPackageCask(packageName), s.getOutdatedFlag(), useForce)
 
-	cmd := exec.Command(s.brewPath, args...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, stderrStr, err := runStreamingCommand(cmd,
+	phase, stderrStr, err := s.runner.Stream(ctx, args,
 		func(line string) { s.eventEmitter.Emit("packageUpdateProgress", fmt.Sprintf("📦 %s", line)) },
 		func(line string) { s.eventEmitter.Emit("packageUpdateProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -273,23 +291,28 @@ func (s *ActionsService) RunUpdateCommand(packageName string, useForce bool) (fi
 	case phaseStdoutPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
-		return errorMsg, false, false
+		return errorMsg, false, false, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
-		return errorMsg, false, false
+		return errorMsg, false, false, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingUpdate", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
-		return errorMsg, false, false
+		return errorMsg, false, false, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("packageUpdateProgress", cancelMsg)
+		return cancelMsg, false, false, false
 	case phaseRun:
-		// Check if this is the "app already exists" error and we haven't tried --force yet
-		if !useForce && s.isAppExistsError(stderrStr) {
-			return "", false, true
+		// Check if this is the "app already exists" error and we haven't tried --force yet.
+		// Only casks are retried, so a formula reports the failure right away.
+		if !useForce && s.isAppExistsError(stderrStr) && s.isPackageCask(packageName) {
+			return "", false, false, true
 		}
 		finalMessage = s.getBackendMsg("backend.update.failed", map[string]string{"name": packageName, "error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", finalMessage)
-		return finalMessage, false, false
+		return finalMessage, false, false, false
 	}
 
 	finalMessage = s.getBackendMsg("backend.update.success", map[string]string{"name": packageName})
@@ -305,22 +328,28 @@ func (s *ActionsService) RunUpdateCommand(packageName string, useForce bool) (fi
 		wailbrewUpdated = true
 	}
 
-	return finalMessage, wailbrewUpdated, false
+	return finalMessage, true, wailbrewUpdated, false
 }
 
 // UpdateBrewPackage upgrades a package with live progress updates
 func (s *ActionsService) UpdateBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.updatePackage(ctx, packageName)
+	return result
+}
+
+// updatePackage is UpdateBrewPackage also reporting whether it succeeded.
+func (s *ActionsService) updatePackage(ctx context.Context, packageName string) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.update.start", map[string]string{"name": packageName})
 	s.eventEmitter.Emit("packageUpdateProgress", startMessage)
 
 	// Try normal upgrade first
-	finalMessage, wailbrewUpdated, shouldRetry := s.RunUpdateCommand(packageName, false)
+	finalMessage, upgraded, wailbrewUpdated, shouldRetry := s.RunUpdateCommand(ctx, packageName, false)
 
 	// If update failed with "app already exists" error and it's a cask, retry with --force
 	if shouldRetry && s.isPackageCask(packageName) {
 		s.eventEmitter.Emit("packageUpdateProgress", s.getBackendMsg("backend.update.retryingWithForce", map[string]string{"name": packageName}))
-		finalMessage, wailbrewUpdated, _ = s.RunUpdateCommand(packageName, true)
+		finalMessage, upgraded, wailbrewUpdated, _ = s.RunUpdateCommand(ctx, packageName, true)
 	}
 
 	// Signal completion
@@ -331,36 +360,87 @@ func (s *ActionsService) UpdateBrewPackage(ctx context.Context, packageName stri
 		s.eventEmitter.Emit("wailbrewUpdated", "")
 	}
 
-	return finalMessage
+	return finalMessage, upgraded
+}
+
+// PackageUpgradeResult is the outcome of upgrading one package.
+type PackageUpgradeResult struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// UpgradeResult is the outcome of upgrading several packages: the message
+// shown to the user and how each package fared.
+type UpgradeResult struct {
+	Message  string                 `json:"message"`
+	Packages []PackageUpgradeResult `json:"packages"`
+}
+
+// Success reports whether every package was upgraded.
+func (r UpgradeResult) Success() bool {
+	for _, pkg := range r.Packages {
+		if !pkg.Success {
+			return false
+		}
+	}
+	return len(r.Packages) > 0
+}
+
+// Failed returns the names of the packages that were not upgraded.
+func (r UpgradeResult) Failed() []string {
+	failed := []string{}
+	for _, pkg := range r.Packages {
+		if !pkg.Success {
+			failed = append(failed, pkg.Name)
+		}
+	}
+	return failed
+}
+
+// uniformUpgradeResult reports the same outcome for every package.
+func uniformUpgradeResult(packageNames []string, message string, success bool) UpgradeResult {
+	result := UpgradeResult{Message: message, Packages: []PackageUpgradeResult{}}
+	for _, name := range packageNames {
+		pkg := PackageUpgradeResult{Name: name, Success: success}
+		if !success {
+			pkg.Error = message
+		}
+		result.Packages = append(result.Packages, pkg)
+	}
+	return result
 }
 
 // UpdateSelectedBrewPackages upgrades specific packages with live progress updates
 func (s *ActionsService) UpdateSelectedBrewPackages(ctx context.Context, packageNames []string) string {
-	// Validate brew installation first
-	if err := s.validateFunc(); err != nil {
-		msg := fmt.Sprintf("❌ Homebrew validation failed: %v", err)
+	return s.UpgradeSelectedPackages(ctx, packageNames).Message
+}
+
+// UpgradeSelectedPackages is UpdateSelectedBrewPackages reporting the outcome
+// of each package.
+func (s *ActionsService) UpgradeSelectedPackages(ctx context.Context, packageNames []string) UpgradeResult {
+	fail := func(msg string) UpgradeResult {
 		s.eventEmitter.Emit("packageUpdateProgress", msg)
 		s.eventEmitter.Emit("packageUpdateComplete", msg)
-		return msg
+		return uniformUpgradeResult(packageNames, msg, false)
+	}
+
+	// Validate brew installation first
+	if err := s.validateFunc(); err != nil {
+		return fail(fmt.Sprintf("❌ Homebrew validation failed: %v", err))
 	}
 
 	if len(packageNames) == 0 {
-		msg := "❌ No packages selected for update"
-		s.eventEmitter.Emit("packageUpdateProgress", msg)
-		s.eventEmitter.Emit("packageUpdateComplete", msg)
-		return msg
+		return fail("❌ No packages selected for update")
 	}
 
 	// Build brew upgrade command with specific packages
 	args := BuildUpgradeSelectedArgs(packageNames)
 
-	cmd := exec.Command(s.brewPath, args...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
 	// Track which packages were updated (especially wailbrew)
 	updatedPackages := make(map[string]bool)
 
-	phase, stderrStr, err := runStreamingCommand(cmd,
+	phase, stderrStr, err := s.runner.Stream(ctx, args,
 		func(line string) {
 			s.eventEmitter.Emit("packageUpdateProgress", fmt.Sprintf("📦 %s", line))
 			if detectWailbrewSelfUpdate(line) {
@@ -372,24 +452,21 @@ func (s *ActionsService) UpdateSelectedBrewPackages(ctx context.Context, package
 
 	switch phase {
 	case phaseStdoutPipe:
-		msg := fmt.Sprintf("❌ Error creating output pipe: %v", err)
-		s.eventEmitter.Emit("packageUpdateProgress", msg)
-		s.eventEmitter.Emit("packageUpdateComplete", msg)
-		return msg
+		return fail(fmt.Sprintf("❌ Error creating output pipe: %v", err))
 	case phaseStderrPipe:
-		msg := fmt.Sprintf("❌ Error creating error pipe: %v", err)
-		s.eventEmitter.Emit("packageUpdateProgress", msg)
-		s.eventEmitter.Emit("packageUpdateComplete", msg)
-		return msg
+		return fail(fmt.Sprintf("❌ Error creating error pipe: %v", err))
 	case phaseStart:
-		msg := fmt.Sprintf("❌ Error starting update: %v", err)
-		s.eventEmitter.Emit("packageUpdateProgress", msg)
-		s.eventEmitter.Emit("packageUpdateComplete", msg)
-		return msg
+		return fail(fmt.Sprintf("❌ Error starting update: %v", err))
 	}
 
-	var finalMessage string
-	if phase == phaseRun {
+	var result UpgradeResult
+	if phase == phaseCancelled {
+		result = uniformUpgradeResult(packageNames, s.getBackendMsg("backend.operation.cancelled", map[string]string{}), false)
+		s.eventEmitter.Emit("packageUpdateProgress", result.Message)
+	} else if phase == phaseRun {
+		failedMessage := fmt.Sprintf("❌ Update failed for selected packages: %v", err)
+		result = uniformUpgradeResult(packageNames, failedMessage, false)
+
 		// Check if this is the "app already exists" error
 		if s.isAppExistsError(stderrStr) {
 			// Extract failed package names
@@ -402,24 +479,42 @@ func (s *ActionsService) UpdateSelectedBrewPackages(ctx context.Context, package
 				}
 			}
 
-			// Retry failed casks with --force
+			// Retry failed casks with --force. The packages brew did not
+			// report as failed went through in the first run.
 			if len(failedCasks) > 0 {
 				s.eventEmitter.Emit("packageUpdateProgress", s.getBackendMsg("backend.update.retryingFailedCasks", map[string]string{"count": fmt.Sprintf("%d", len(failedCasks))}))
+				retried := make(map[string]PackageUpgradeResult, len(failedCasks))
+				var stillFailed []string
 				for _, pkg := range failedCasks {
 					s.eventEmitter.Emit("packageUpdateProgress", s.getBackendMsg("backend.update.retryingWithForce", map[string]string{"name": pkg}))
-					_, _, _ = s.RunUpdateCommand(pkg, true)
+					message, upgraded, _, _ := s.RunUpdateCommand(ctx, pkg, true)
+					outcome := PackageUpgradeResult{Name: pkg, Success: upgraded}
+					if !upgraded {
+						outcome.Error = message
+						stillFailed = append(stillFailed, pkg)
+					}
+					retried[pkg] = outcome
+				}
+				for i, pkg := range result.Packages {
+					switch outcome, ok := retried[pkg.Name]; {
+					case ok:
+						result.Packages[i] = outcome
+					case !containsPackage(failedPackages, pkg.Name):
+						result.Packages[i] = PackageUpgradeResult{Name: pkg.Name, Success: true}
+					}
+				}
+				if len(stillFailed) > 0 {
+					result.Message = fmt.Sprintf("❌ Retrying with --force failed for: %s", strings.Join(stillFailed, ", "))
+				} else {
+					result.Message = fmt.Sprintf("✅ Retried %d failed cask(s) with --force", len(failedCasks))
 				}
-				finalMessage = fmt.Sprintf("✅ Retried %d failed cask(s) with --force", len(failedCasks))
-			} else {
-				finalMessage = fmt.Sprintf("❌ Update failed for selected packages: %v", err)
 			}
-		} else {
-			finalMessage = fmt.Sprintf("❌ Update failed for selected packages: %v", err)
 		}
-		s.eventEmitter.Emit("packageUpdateProgress", finalMessage)
+		s.eventEmitter.Emit("packageUpdateProgress", result.Message)
 	} else {
-		finalMessage = fmt.Sprintf("✅ Successfully updated %d selected package(s)", len(packageNames))
-		s.eventEmitter.Emit("packageUpdateProgress", finalMessage)
+		result = uniformUpgradeResult(packageNames,
+			fmt.Sprintf("✅ Successfully updated %d selected package(s)", len(packageNames)), true)
+		s.eventEmitter.Emit("packageUpdateProgress", result.Message)
 
 		// Post-upgrade: run quarantine removal for each upgraded cask.
 		// UpdateSelectedBrewPackages uses a single bulk brew command, so
@@ -433,31 +528,109 @@ func (s *ActionsService) UpdateSelectedBrewPackages(ctx context.Context, package
 	}
 
 	// Signal completion
-	s.eventEmitter.Emit("packageUpdateComplete", finalMessage)
+	s.eventEmitter.Emit("packageUpdateComplete", result.Message)
 
 	// If WailBrew was updated, emit event to show restart dialog
 	if updatedPackages["wailbrew"] {
 		s.eventEmitter.Emit("wailbrewUpdated", "")
 	}
 
-	return finalMessage
+	return result
+}
+
+// UpgradeAllArgs builds the upgrade-all command. When outdated packages are
+// pinned or held back by their upgrade policy, the allowed ones are named
+// explicitly instead; nil args mean nothing is allowed. Without a readable
+// outdated list brew picks the packages itself, which is only safe when no
+// policies are configured.
+func (s *ActionsService) UpgradeAllArgs() ([]string, error) {
+	args, _, err := s.upgradeAllPlan()
+	return args, err
+}
+
+// upgradeAllPlan returns the upgrade-all arguments together with the
+// policy-held formulae that are not pinned yet. Naming the allowed packages
+// does not stop brew from upgrading their outdated dependencies, so those
+// formulae have to be pinned for the duration of the run.
+func (s *ActionsService) upgradeAllPlan() ([]string, []string, error) {
+	outdatedFlag := s.getOutdatedFlag()
+	packages, err := s.getOutdated()
+	if err != nil {
+		if len(s.getPolicies()) > 0 {
+			return nil, nil, err
+		}
+		return BuildUpgradeAllArgs(outdatedFlag), nil, nil
+	}
+
+	var allowed, held []string
+	excluded := false
+	for _, pkg := range packages {
+		if pkg.Pinned || !pkg.PolicyAllowed {
+			excluded = true
+			if !pkg.Pinned && pkg.Type == PackageTypeFormula {
+				held = append(held, pkg.Name)
+			}
+			continue
+		}
+		allowed = append(allowed, pkg.Name)
+	}
+
+	switch {
+	case !excluded:
+		return BuildUpgradeAllArgs(outdatedFlag), nil, nil
+	case len(allowed) == 0:
+		return nil, nil, nil
+	default:
+		return append(BuildUpgradeAllArgs(outdatedFlag), allowed...), held, nil
+	}
 }
 
 // UpdateAllBrewPackages upgrades all outdated packages with live progress updates
 func (s *ActionsService) UpdateAllBrewPackages(ctx context.Context) string {
+	result, _ := s.updateAllPackages(ctx)
+	return result
+}
+
+// updateAllPackages is UpdateAllBrewPackages also reporting whether it
+// succeeded. Finding everything held back is not a failure.
+func (s *ActionsService) updateAllPackages(ctx context.Context) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.updateAll.start", map[string]string{})
 	s.eventEmitter.Emit("packageUpdateProgress", startMessage)
 
-	// Build upgrade command respecting the user's Outdated Detection Mode setting
-	upgradeArgs := BuildUpgradeAllArgs(s.getOutdatedFlag())
-	cmd := exec.Command(s.brewPath, upgradeArgs...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
+	// Build upgrade command respecting the user's Outdated Detection Mode
+	// setting, pinned formulae and upgrade policies
+	upgradeArgs, held, err := s.upgradeAllPlan()
+	if err != nil {
+		errorMsg := s.getBackendMsg("backend.updateAll.failed", map[string]string{"error": err.Error()})
+		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
+		s.eventEmitter.Emit("packageUpdateComplete", errorMsg)
+		return errorMsg, false
+	}
+	if upgradeArgs == nil {
+		heldMsg := s.getBackendMsg("backend.updateAll.allHeld", map[string]string{})
+		s.eventEmitter.Emit("packageUpdateProgress", heldMsg)
+		s.eventEmitter.Emit("packageUpdateComplete", heldMsg)
+		return heldMsg, true
+	}
+
+	// Pin the held formulae so the allowed packages cannot drag them along
+	// as dependencies, and release them again once the run is over.
+	for i, name := range held {
+		if _, err := runRecorded(ctx, s.runner, BuildPinArgs(name)...); err != nil {
+			s.unpinHeld(ctx, held[:i])
+			errorMsg := s.getBackendMsg("backend.updateAll.holdFailed", map[string]string{"name": name, "error": err.Error()})
+			s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
+			s.eventEmitter.Emit("packageUpdateComplete", errorMsg)
+			return errorMsg, false
+		}
+	}
+	defer s.unpinHeld(ctx, held)
 
 	// Track which packages are being updated
 	updatedPackages := make(map[string]bool)
 
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, upgradeArgs,
 		func(line string) {
 			s.eventEmitter.Emit("packageUpdateProgress", fmt.Sprintf("📦 %s", line))
 			if detectWailbrewSelfUpdate(line) {
@@ -472,21 +645,24 @@ func (s *ActionsService) UpdateAllBrewPackages(ctx context.Context) string {
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
 		s.eventEmitter.Emit("packageUpdateComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
 		s.eventEmitter.Emit("packageUpdateComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingUpdateAll", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", errorMsg)
 		s.eventEmitter.Emit("packageUpdateComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	var finalMessage string
-	if phase == phaseRun {
+	if phase == phaseCancelled {
+		finalMessage = s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("packageUpdateProgress", finalMessage)
+	} else if phase == phaseRun {
 		finalMessage = s.getBackendMsg("backend.updateAll.failed", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("packageUpdateProgress", finalMessage)
 	} else {
@@ -502,5 +678,45 @@ func (s *ActionsService) UpdateAllBrewPackages(ctx context.Context) string {
 		s.eventEmitter.Emit("wailbrewUpdated", "")
 	}
 
-	return finalMessage
+	return finalMessage, phase == phaseNone
+}
+
+// unpinHeld releases the formulae pinned by UpdateAllBrewPackages. It keeps
+// going after a cancelled run so no hold outlives the upgrade.
+func (s *ActionsService) unpinHeld(ctx context.Context, names []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, name := range names {
+		if _, err := runRecorded(ctx, s.runner, BuildUnpinArgs(name)...); err != nil {
+			s.eventEmitter.Emit("packageUpdateProgress", s.getBackendMsg("backend.updateAll.releaseFailed",
+				map[string]string{"name": name, "error": err.Error()}))
+		}
+	}
+	if len(names) > 0 {
+		s.runner.ClearCache()
+	}
+}
+
+// PinBrewPackage pins a formula at its installed version so upgrades skip it.
+func (s *ActionsService) PinBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.setPinned(ctx, packageName, BuildPinArgs(packageName), "backend.pin")
+	return result
+}
+
+// UnpinBrewPackage lets upgrades touch a pinned formula again.
+func (s *ActionsService) UnpinBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.setPinned(ctx, packageName, BuildUnpinArgs(packageName), "backend.unpin")
+	return result
+}
+
+// setPinned runs a pin or unpin command and drops cached listings, since the
+// outdated list reports pinned state. It reports whether the command
+// succeeded alongside the message.
+func (s *ActionsService) setPinned(ctx context.Context, packageName string, args []string, msgPrefix string) (string, bool) {
+	params := map[string]string{"name": packageName}
+	if _, err := runRecorded(ctx, s.runner, args...); err != nil {
+		params["error"] = err.Error()
+		return s.getBackendMsg(msgPrefix+".failed", params), false
+	}
+	s.runner.ClearCache()
+	return s.getBackendMsg(msgPrefix+".success", params), true
 }
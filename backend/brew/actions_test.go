@@ -0,0 +1,341 @@
+package brew
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInstallBrewPackage(t *testing.T) {
+	tests := []struct {
+		name         string
+		pkg          string
+		recording    brewRecording
+		wantResult   string
+		wantProgress string
+		wantTrust    string
+	}{
+		{
+			name:         "success",
+			pkg:          "wget",
+			recording:    brewRecording{stdout: "==> Pouring wget--1.24.5.arm64_sequoia.bottle.tar.gz\n"},
+			wantResult:   "backend.install.success",
+			wantProgress: "📦 ==> Pouring wget--1.24.5.arm64_sequoia.bottle.tar.gz",
+		},
+		{
+			name:         "failure",
+			pkg:          "wget",
+			recording:    brewRecording{stderr: "Error: No available formula with the name \"wget\".\n", exitCode: 1},
+			wantResult:   "backend.install.failed",
+			wantProgress: "⚠️ Error: No available formula with the name \"wget\".",
+		},
+		{
+			name:       "untrusted tap asks for trust",
+			pkg:        "acme/tools/widget",
+			recording:  brewRecording{stderr: recordedUntrustedTap, exitCode: 1},
+			wantResult: "backend.install.failed",
+			wantTrust:  `{"package":"acme/tools/widget","tap":"acme/tools"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script("install "+tt.pkg, tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := service.InstallBrewPackage(context.Background(), tt.pkg); got != tt.wantResult {
+				t.Fatalf("result = %q, want %q", got, tt.wantResult)
+			}
+			if got := emitter.last("packageInstallComplete"); got != tt.wantResult {
+				t.Fatalf("complete event = %q, want %q", got, tt.wantResult)
+			}
+			if tt.wantProgress != "" && !emitter.has("packageInstallProgress", tt.wantProgress) {
+				t.Fatalf("missing progress line %q in %v", tt.wantProgress, emitter.data)
+			}
+			if got := emitter.first("packageInstallTrustRequired"); got != tt.wantTrust {
+				t.Fatalf("trust event = %q, want %q", got, tt.wantTrust)
+			}
+		})
+	}
+}
+
+func TestRemoveBrewPackage_zapOnlyForCasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		info     string
+		zap      bool
+		wantArgs string
+	}{
+		{name: "cask with zap", pkg: "firefox", info: recordedCaskInfo, zap: true, wantArgs: "uninstall --zap --cask firefox"},
+		{name: "formula with zap", pkg: "wget", info: `{"formulae":[{"name":"wget"}],"casks":[]}`, zap: true, wantArgs: "uninstall wget"},
+		{name: "cask without zap", pkg: "firefox", info: recordedCaskInfo, wantArgs: "uninstall firefox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().
+				script("info --json=v2 "+tt.pkg, brewRecording{stdout: tt.info}).
+				script(tt.wantArgs, brewRecording{})
+			service, _ := newFakeService(fb)
+
+			if got := service.RemoveBrewPackage(context.Background(), tt.pkg, tt.zap); got != "backend.uninstall.success" {
+				t.Fatalf("result = %q", got)
+			}
+			if fb.invoked(tt.wantArgs) != 1 {
+				t.Fatalf("expected %q to run once, calls: %v", tt.wantArgs, fb.calls)
+			}
+		})
+	}
+}
+
+func TestUpdateBrewPackage_retriesCaskWithForceWhenAppExists(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 firefox", brewRecording{stdout: recordedCaskInfo}).
+		script("upgrade firefox", brewRecording{stderr: recordedAppExists, exitCode: 1}).
+		script("upgrade --force firefox", brewRecording{stdout: "==> Upgrading firefox\n"})
+	service, emitter := newFakeService(fb)
+
+	if got := service.UpdateBrewPackage(context.Background(), "firefox"); got != "backend.update.success" {
+		t.Fatalf("result = %q", got)
+	}
+	if fb.invoked("upgrade firefox") != 1 || fb.invoked("upgrade --force firefox") != 1 {
+		t.Fatalf("expected one plain and one forced upgrade, calls: %v", fb.calls)
+	}
+	if !emitter.has("packageUpdateProgress", "backend.update.retryingWithForce") {
+		t.Fatalf("retry was not announced: %v", emitter.data)
+	}
+}
+
+func TestUpdateBrewPackage_formulaIsNotForced(t *testing.T) {
+	fb := newFakeBrew().
+		script("upgrade wget", brewRecording{stderr: recordedAppExists, exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	if got := service.UpdateBrewPackage(context.Background(), "wget"); got != "backend.update.failed" {
+		t.Fatalf("result = %q", got)
+	}
+	if fb.invoked("upgrade --force wget") != 0 {
+		t.Fatalf("formula must not be retried with --force, calls: %v", fb.calls)
+	}
+}
+
+func TestUpdateSelectedBrewPackages_retriesFailedCasksWithForce(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 firefox", brewRecording{stdout: recordedCaskInfo}).
+		script("upgrade firefox wget", brewRecording{stderr: recordedAppExistsMulti, exitCode: 1}).
+		script("upgrade --force firefox", brewRecording{})
+	service, emitter := newFakeService(fb)
+
+	got := service.UpgradeSelectedPackages(context.Background(), []string{"firefox", "wget"})
+	if got.Message != "✅ Retried 1 failed cask(s) with --force" || !got.Success() {
+		t.Fatalf("result = %+v", got)
+	}
+	if fb.invoked("upgrade --force firefox") != 1 {
+		t.Fatalf("expected forced retry of firefox, calls: %v", fb.calls)
+	}
+	if !emitter.has("packageUpdateProgress", "backend.update.retryingFailedCasks") {
+		t.Fatalf("retry was not announced: %v", emitter.data)
+	}
+}
+
+func TestUpgradeSelectedPackages_reportsFailedRetry(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 firefox", brewRecording{stdout: recordedCaskInfo}).
+		script("upgrade firefox wget", brewRecording{stderr: recordedAppExistsMulti, exitCode: 1}).
+		script("upgrade --force firefox", brewRecording{stderr: "Error: permission denied", exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	got := service.UpgradeSelectedPackages(context.Background(), []string{"firefox", "wget"})
+	if got.Success() || !reflect.DeepEqual(got.Failed(), []string{"firefox"}) {
+		t.Fatalf("result = %+v, want only firefox failed", got)
+	}
+	if !strings.HasPrefix(got.Message, "❌") {
+		t.Errorf("message = %q, want a failure", got.Message)
+	}
+}
+
+func TestUpdateAllBrewPackages(t *testing.T) {
+	tests := []struct {
+		name          string
+		recording     brewRecording
+		wantResult    string
+		wantRestarted bool
+	}{
+		{
+			name:       "success",
+			recording:  brewRecording{stdout: "==> Upgrading 1 outdated package:\nwget 1.24.4 -> 1.24.5\n"},
+			wantResult: "backend.updateAll.success",
+		},
+		{
+			name:          "wailbrew upgraded itself",
+			recording:     brewRecording{stdout: "==> Upgrading wailbrew\n"},
+			wantResult:    "backend.updateAll.success",
+			wantRestarted: true,
+		},
+		{
+			name:       "failure",
+			recording:  brewRecording{stderr: "Error: Cannot write to /opt/homebrew/Cellar\n", exitCode: 1},
+			wantResult: "backend.updateAll.failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script("upgrade", tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := service.UpdateAllBrewPackages(context.Background()); got != tt.wantResult {
+				t.Fatalf("result = %q, want %q", got, tt.wantResult)
+			}
+			if restarted := emitter.count("wailbrewUpdated") > 0; restarted != tt.wantRestarted {
+				t.Fatalf("wailbrewUpdated emitted = %v, want %v", restarted, tt.wantRestarted)
+			}
+		})
+	}
+}
+
+func TestInstallBrewPackage_cancelledBeforeStart(t *testing.T) {
+	fb := newFakeBrew().script("install wget", brewRecording{})
+	service, _ := newFakeService(fb)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if got := service.InstallBrewPackage(ctx, "wget"); got != "backend.operation.cancelled" {
+		t.Fatalf("result = %q", got)
+	}
+	if fb.invoked("install wget") != 0 {
+		t.Fatalf("cancelled install must not reach brew, calls: %v", fb.calls)
+	}
+}
+
+func TestUpdateAllBrewPackages_upgradesOnlyAllowedPackages(t *testing.T) {
+	const outdated = `{"formulae":[` +
+		`{"name":"wget","installed_versions":["1.24.4"],"current_version":"1.24.5"},` +
+		`{"name":"node","installed_versions":["22.1.0"],"current_version":"23.0.0"},` +
+		`{"name":"python@3.12","installed_versions":["3.12.4"],"current_version":"3.12.5"},` +
+		`{"name":"jq","installed_versions":["1.7"],"current_version":"1.7.1","pinned":true}],"casks":[]}`
+
+	tests := []struct {
+		name       string
+		outdated   string
+		policies   map[string]string
+		wantArgs   string
+		wantHeld   []string
+		wantResult string
+	}{
+		{
+			name:       "pinned formula is left out",
+			outdated:   outdated,
+			wantArgs:   "upgrade wget node python@3.12",
+			wantResult: "backend.updateAll.success",
+		},
+		{
+			name:       "policies hold back a major and a skipped version",
+			outdated:   outdated,
+			policies:   map[string]string{"node": UpgradePolicyMinor, "python@3.12": "skip:3.12.5", "wget": UpgradePolicyPatch},
+			wantArgs:   "upgrade wget",
+			wantHeld:   []string{"node", "python@3.12"},
+			wantResult: "backend.updateAll.success",
+		},
+		{
+			name: "held dependency of an allowed package stays pinned",
+			outdated: `{"formulae":[` +
+				`{"name":"yarn","installed_versions":["1.22.21"],"current_version":"1.22.22"},` +
+				`{"name":"node","installed_versions":["22.1.0"],"current_version":"23.0.0"}],"casks":[]}`,
+			policies:   map[string]string{"node": UpgradePolicyMinor},
+			wantArgs:   "upgrade yarn",
+			wantHeld:   []string{"node"},
+			wantResult: "backend.updateAll.success",
+		},
+		{
+			name:       "nothing held lets brew pick",
+			outdated:   `{"formulae":[{"name":"wget","installed_versions":["1.24.4"],"current_version":"1.24.5"}],"casks":[]}`,
+			wantArgs:   "upgrade",
+			wantResult: "backend.updateAll.success",
+		},
+		{
+			name:       "everything held runs nothing",
+			outdated:   outdated,
+			policies:   map[string]string{"wget": UpgradePolicyNever, "node": UpgradePolicyPatch, "python@3.12": "skip:3.12.5"},
+			wantResult: "backend.updateAll.allHeld",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().
+				script("outdated --json=v2", brewRecording{stdout: tt.outdated}).
+				script(tt.wantArgs, brewRecording{})
+			for _, name := range tt.wantHeld {
+				fb.script("pin "+name, brewRecording{}).script("unpin "+name, brewRecording{})
+			}
+			service, _ := newFakeServiceWithPolicies(fb, tt.policies)
+
+			if got := service.UpdateAllBrewPackages(context.Background()); got != tt.wantResult {
+				t.Fatalf("result = %q, calls: %v", got, fb.calls)
+			}
+			for _, call := range fb.calls {
+				if strings.HasPrefix(call, "upgrade") && call != tt.wantArgs {
+					t.Fatalf("unexpected %q, want %q", call, tt.wantArgs)
+				}
+			}
+			if tt.wantArgs != "" && fb.invoked(tt.wantArgs) != 1 {
+				t.Fatalf("expected %q to run, calls: %v", tt.wantArgs, fb.calls)
+			}
+			upgradeAt := slices.Index(fb.calls, tt.wantArgs)
+			for _, name := range tt.wantHeld {
+				pinAt, unpinAt := slices.Index(fb.calls, "pin "+name), slices.Index(fb.calls, "unpin "+name)
+				if pinAt < 0 || pinAt > upgradeAt || unpinAt < upgradeAt {
+					t.Fatalf("%s must be pinned around the upgrade, calls: %v", name, fb.calls)
+				}
+			}
+			if len(tt.wantHeld) == 0 && slices.ContainsFunc(fb.calls, func(call string) bool {
+				return strings.HasPrefix(call, "pin ")
+			}) {
+				t.Fatalf("nothing should be pinned, calls: %v", fb.calls)
+			}
+		})
+	}
+}
+
+func TestUpdateAllBrewPackages_stopsWhenHoldFails(t *testing.T) {
+	const outdated = `{"formulae":[` +
+		`{"name":"wget","installed_versions":["1.24.4"],"current_version":"1.24.5"},` +
+		`{"name":"node","installed_versions":["22.1.0"],"current_version":"23.0.0"},` +
+		`{"name":"python@3.12","installed_versions":["3.12.4"],"current_version":"3.12.5"}],"casks":[]}`
+	fb := newFakeBrew().
+		script("outdated --json=v2", brewRecording{stdout: outdated}).
+		script("pin node", brewRecording{}).
+		script("unpin node", brewRecording{}).
+		script("pin python@3.12", brewRecording{stderr: "Error: permission denied\n", exitCode: 1}).
+		script("upgrade wget", brewRecording{})
+	service, _ := newFakeServiceWithPolicies(fb, map[string]string{"node": UpgradePolicyMinor, "python@3.12": "skip:3.12.5"})
+
+	if got := service.UpdateAllBrewPackages(context.Background()); got != "backend.updateAll.holdFailed" {
+		t.Fatalf("result = %q, calls: %v", got, fb.calls)
+	}
+	if fb.invoked("upgrade wget") != 0 {
+		t.Fatalf("upgrade must not run without the hold, calls: %v", fb.calls)
+	}
+	if fb.invoked("unpin node") != 1 {
+		t.Fatalf("node must be released again, calls: %v", fb.calls)
+	}
+}
+
+func TestPinBrewPackage(t *testing.T) {
+	fb := newFakeBrew().
+		script("pin wget", brewRecording{}).
+		script("unpin wget", brewRecording{stderr: "Error: wget not installed\n", exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	if got := service.PinBrewPackage(context.Background(), "wget"); got != "backend.pin.success" {
+		t.Fatalf("pin result = %q", got)
+	}
+	if got := service.UnpinBrewPackage(context.Background(), "wget"); got != "backend.unpin.failed" {
+		t.Fatalf("unpin result = %q", got)
+	}
+}
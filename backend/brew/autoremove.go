@@ -0,0 +1,134 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// OrphanedFormula is a formula that was installed as a dependency and that
+// nothing installed needs anymore, as reported by `brew autoremove --dry-run`.
+type OrphanedFormula struct {
+	Name string `json:"name"`
+	Size string `json:"size"`
+}
+
+// AutoremoveService removes orphaned dependencies via `brew autoremove`.
+type AutoremoveService struct {
+	runner        Runner
+	getBackendMsg func(string, map[string]string) string
+	eventEmitter  EventEmitter
+	getSizes      func(names []string, isCask bool) map[string]string
+}
+
+// NewAutoremoveService creates a new autoremove service.
+func NewAutoremoveService(
+	runner Runner,
+	getBackendMsg func(string, map[string]string) string,
+	eventEmitter EventEmitter,
+	getSizes func(names []string, isCask bool) map[string]string,
+) *AutoremoveService {
+	return &AutoremoveService{
+		runner:        runner,
+		getBackendMsg: getBackendMsg,
+		eventEmitter:  eventEmitter,
+		getSizes:      getSizes,
+	}
+}
+
+// parseAutoremoveDryRun extracts formula names from `brew autoremove
+// --dry-run` output, which lists one name per line under a
+// "==> Would autoremove N unneeded formulae:" header.
+func parseAutoremoveDryRun(output string) []string {
+	names := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if isPackageNameLine(line) {
+			names = append(names, line)
+		}
+	}
+	return names
+}
+
+// Orphans lists the formulae `brew autoremove` would uninstall, with their
+// installed sizes. The answer changes with every install, so it is never
+// served from the cache.
+func (s *AutoremoveService) Orphans() ([]OrphanedFormula, error) {
+	output, err := s.runner.RunNoCache("autoremove", "--dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list orphaned formulae: %v", err)
+	}
+
+	names := parseAutoremoveDryRun(string(output))
+	orphans := make([]OrphanedFormula, 0, len(names))
+	if len(names) == 0 {
+		return orphans, nil
+	}
+
+	sizes := s.getSizes(names, false)
+	for _, name := range names {
+		orphans = append(orphans, OrphanedFormula{Name: name, Size: sizes[name]})
+	}
+	return orphans, nil
+}
+
+// RunAutoremove uninstalls every orphaned formula, streaming output via
+// autoremoveProgress / autoremoveComplete events.
+func (s *AutoremoveService) RunAutoremove(ctx context.Context) string {
+	result, _ := s.runAutoremove(ctx)
+	return result
+}
+
+// runAutoremove is RunAutoremove also reporting whether it succeeded.
+func (s *AutoremoveService) runAutoremove(ctx context.Context) (string, bool) {
+	startMessage := s.getBackendMsg("backend.autoremove.start", map[string]string{})
+	s.eventEmitter.Emit("autoremoveProgress", startMessage)
+
+	phase, _, err := s.runner.Stream(ctx, []string{"autoremove"},
+		func(line string) {
+			s.eventEmitter.Emit("autoremoveProgress", s.getBackendMsg("backend.autoremove.output", map[string]string{"line": line}))
+		},
+		func(line string) {
+			s.eventEmitter.Emit("autoremoveProgress", s.getBackendMsg("backend.autoremove.warning", map[string]string{"line": line}))
+		},
+	)
+
+	var finalMessage string
+	switch phase {
+	case phaseStdoutPipe:
+		finalMessage = s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
+	case phaseStderrPipe:
+		finalMessage = s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
+	case phaseStart:
+		finalMessage = s.getBackendMsg("backend.errors.startingAutoremove", map[string]string{"error": err.Error()})
+	case phaseCancelled:
+		finalMessage = s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+	case phaseRun:
+		finalMessage = s.getBackendMsg("backend.autoremove.failed", map[string]string{"error": err.Error()})
+	default:
+		finalMessage = s.getBackendMsg("backend.autoremove.success", map[string]string{})
+	}
+
+	s.eventEmitter.Emit("autoremoveProgress", finalMessage)
+	s.eventEmitter.Emit("autoremoveComplete", finalMessage)
+	return finalMessage, phase == phaseNone
+}
+
+// MarkInstalledOnRequest records packages as explicitly installed with
+// `brew tab --installed-on-request`, which keeps them out of autoremove.
+func (s *AutoremoveService) MarkInstalledOnRequest(ctx context.Context, packageNames []string) string {
+	result, _ := s.markInstalledOnRequest(ctx, packageNames)
+	return result
+}
+
+// markInstalledOnRequest is MarkInstalledOnRequest also reporting whether it
+// succeeded.
+func (s *AutoremoveService) markInstalledOnRequest(ctx context.Context, packageNames []string) (string, bool) {
+	params := map[string]string{"name": strings.Join(packageNames, ", ")}
+	args := append([]string{"tab", "--installed-on-request"}, packageNames...)
+	if _, err := runRecorded(ctx, s.runner, args...); err != nil {
+		params["error"] = err.Error()
+		return s.getBackendMsg("backend.autoremove.keepFailed", params), false
+	}
+	return s.getBackendMsg("backend.autoremove.keepSuccess", params), true
+}
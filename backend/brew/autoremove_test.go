@@ -0,0 +1,95 @@
+package brew
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const recordedAutoremoveDryRun = "==> Would autoremove 2 unneeded formulae:\n" +
+	"libidn2\n" +
+	"openssl@3\n"
+
+func TestParseAutoremoveDryRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"orphans", recordedAutoremoveDryRun, []string{"libidn2", "openssl@3"}},
+		{"nothing to remove", "", []string{}},
+		{"diagnostics are skipped", "Warning: acme/tools is not trusted.\n" + recordedAutoremoveDryRun, []string{"libidn2", "openssl@3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAutoremoveDryRun(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseAutoremoveDryRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoremoveService_Orphans(t *testing.T) {
+	fb := newFakeBrew().script("autoremove --dry-run", brewRecording{stdout: recordedAutoremoveDryRun})
+	var sized []string
+	service := NewAutoremoveService(fb, func(key string, _ map[string]string) string { return key }, &recordingEmitter{},
+		func(names []string, isCask bool) map[string]string {
+			sized = names
+			return map[string]string{"libidn2": "1.2M", "openssl@3": "27M"}
+		})
+
+	orphans, err := service.Orphans()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []OrphanedFormula{{Name: "libidn2", Size: "1.2M"}, {Name: "openssl@3", Size: "27M"}}
+	if !reflect.DeepEqual(orphans, want) {
+		t.Fatalf("Orphans() = %+v, want %+v", orphans, want)
+	}
+	if !reflect.DeepEqual(sized, []string{"libidn2", "openssl@3"}) {
+		t.Fatalf("sizes requested for %v", sized)
+	}
+
+	fb.script("autoremove --dry-run", brewRecording{stderr: "Error: Homebrew is locked.", exitCode: 1})
+	if _, err := service.Orphans(); err == nil {
+		t.Fatal("expected a failed dry run to return an error")
+	}
+}
+
+func TestRunAutoremove(t *testing.T) {
+	tests := []struct {
+		name      string
+		recording brewRecording
+		want      string
+	}{
+		{"success", brewRecording{stdout: "==> Autoremoving 2 unneeded formulae:\nlibidn2\nopenssl@3\n"}, "backend.autoremove.success"},
+		{"failure", brewRecording{stderr: "Error: Homebrew is locked.", exitCode: 1}, "backend.autoremove.failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script("autoremove", tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := service.RunAutoremove(context.Background()); got != tt.want {
+				t.Fatalf("RunAutoremove() = %q, want %q", got, tt.want)
+			}
+			if emitter.last("autoremoveComplete") != tt.want {
+				t.Fatalf("autoremoveComplete = %q", emitter.last("autoremoveComplete"))
+			}
+		})
+	}
+}
+
+func TestMarkInstalledOnRequest(t *testing.T) {
+	fb := newFakeBrew().script("tab --installed-on-request libidn2 openssl@3", brewRecording{})
+	service, _ := newFakeService(fb)
+
+	if got := service.MarkInstalledOnRequest(context.Background(), []string{"libidn2", "openssl@3"}); got != "backend.autoremove.keepSuccess" {
+		t.Fatalf("MarkInstalledOnRequest() = %q", got)
+	}
+	if got := service.MarkInstalledOnRequest(context.Background(), []string{"wget"}); got != "backend.autoremove.keepFailed" {
+		t.Fatalf("MarkInstalledOnRequest() = %q, want failure for an unscripted package", got)
+	}
+}
@@ -0,0 +1,411 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AutoUpgradeSettings configures unattended upgrades. Days are lower-case
+// three-letter day names or "weekdays" / "weekend", empty meaning every day;
+// WindowStart and WindowEnd are local "HH:MM" times, and an end before the
+// start wraps past midnight. Allow, when set, limits upgrades to the named
+// packages and Deny excludes packages; MaxPackages caps one run, 0 meaning
+// no limit.
+type AutoUpgradeSettings struct {
+	Enabled     bool
+	Days        []string
+	WindowStart string
+	WindowEnd   string
+	Allow       []string
+	Deny        []string
+	MaxPackages int
+}
+
+// MaintenanceWindow is the parsed time window auto-upgrades run in.
+type MaintenanceWindow struct {
+	days       [7]bool // indexed by time.Weekday
+	start, end int     // minutes after midnight
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseMaintenanceWindow validates the day list and "HH:MM" times.
+func ParseMaintenanceWindow(days []string, start, end string) (MaintenanceWindow, error) {
+	var w MaintenanceWindow
+	var err error
+	if w.start, err = parseClock(start); err != nil {
+		return w, err
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return w, err
+	}
+	if w.start == w.end {
+		return w, fmt.Errorf("maintenance window %s-%s is empty", start, end)
+	}
+
+	if len(days) == 0 {
+		days = []string{"weekdays", "weekend"}
+	}
+	for _, day := range days {
+		switch day = strings.ToLower(strings.TrimSpace(day)); day {
+		case "weekdays":
+			for d := time.Monday; d <= time.Friday; d++ {
+				w.days[d] = true
+			}
+		case "weekend":
+			w.days[time.Saturday], w.days[time.Sunday] = true, true
+		default:
+			d, ok := weekdayNames[day]
+			if !ok {
+				return w, fmt.Errorf("unknown day %q", day)
+			}
+			w.days[d] = true
+		}
+	}
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Occurrence returns when the window containing now opened. A window that
+// wraps past midnight belongs to the day it opens on.
+func (w MaintenanceWindow) Occurrence(now time.Time) (time.Time, bool) {
+	minute := now.Hour()*60 + now.Minute()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if w.start < w.end {
+		if minute >= w.start && minute < w.end && w.days[now.Weekday()] {
+			return midnight.Add(time.Duration(w.start) * time.Minute), true
+		}
+		return time.Time{}, false
+	}
+	if minute >= w.start && w.days[now.Weekday()] {
+		return midnight.Add(time.Duration(w.start) * time.Minute), true
+	}
+	yesterday := midnight.AddDate(0, 0, -1)
+	if minute < w.end && w.days[yesterday.Weekday()] {
+		return yesterday.Add(time.Duration(w.start) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+// SelectAutoUpgrade picks the outdated packages an unattended run may
+// upgrade: never pinned ones or ones held back by their upgrade policy, and
+// only those the allow and deny lists let through. Packages over the
+// MaxPackages limit are returned as deferred to a later run.
+func SelectAutoUpgrade(packages []OutdatedPackage, settings AutoUpgradeSettings) (selected, deferred []string) {
+	selected, deferred = []string{}, []string{}
+	for _, pkg := range packages {
+		switch {
+		case pkg.Pinned, !pkg.PolicyAllowed:
+			continue
+		case len(settings.Allow) > 0 && !slices.Contains(settings.Allow, pkg.Name):
+			continue
+		case slices.Contains(settings.Deny, pkg.Name):
+			continue
+		case settings.MaxPackages > 0 && len(selected) >= settings.MaxPackages:
+			deferred = append(deferred, pkg.Name)
+		default:
+			selected = append(selected, pkg.Name)
+		}
+	}
+	return selected, deferred
+}
+
+// AutoUpgradeRun records one unattended upgrade run.
+type AutoUpgradeRun struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Packages   []string  `json:"packages"`
+	Deferred   []string  `json:"deferred"`
+	Success    bool      `json:"success"`
+	// Failed lists the packages that were not upgraded.
+	Failed []string `json:"failed,omitempty"`
+	// Message is the result reported by the upgrade, or why the run failed
+	// before it started.
+	Message string `json:"message"`
+	Cleanup string `json:"cleanup,omitempty"`
+	// Acknowledged is set once the user dismissed a failed run.
+	Acknowledged bool `json:"acknowledged,omitempty"`
+}
+
+// maxAutoUpgradeRuns is how many runs the history keeps.
+const maxAutoUpgradeRuns = 50
+
+// AutoUpgradeHistory persists auto-upgrade runs as a JSON file, newest last,
+// so failures survive a restart until the user acknowledges them.
+type AutoUpgradeHistory struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAutoUpgradeHistory creates a history stored at path.
+func NewAutoUpgradeHistory(path string) *AutoUpgradeHistory {
+	return &AutoUpgradeHistory{path: path}
+}
+
+// Runs returns every recorded run, oldest first.
+func (h *AutoUpgradeHistory) Runs() ([]AutoUpgradeRun, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.load()
+}
+
+// Failures returns the failed runs the user has not acknowledged yet.
+func (h *AutoUpgradeHistory) Failures() ([]AutoUpgradeRun, error) {
+	runs, err := h.Runs()
+	if err != nil {
+		return nil, err
+	}
+	failures := []AutoUpgradeRun{}
+	for _, run := range runs {
+		if !run.Success && !run.Acknowledged {
+			failures = append(failures, run)
+		}
+	}
+	return failures, nil
+}
+
+// Append records a run, dropping the oldest ones over the limit.
+func (h *AutoUpgradeHistory) Append(run AutoUpgradeRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs, err := h.load()
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > maxAutoUpgradeRuns {
+		runs = runs[len(runs)-maxAutoUpgradeRuns:]
+	}
+	return h.save(runs)
+}
+
+// Acknowledge marks every failed run as seen.
+func (h *AutoUpgradeHistory) Acknowledge() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs, err := h.load()
+	if err != nil {
+		return err
+	}
+	for i := range runs {
+		if !runs[i].Success {
+			runs[i].Acknowledged = true
+		}
+	}
+	return h.save(runs)
+}
+
+func (h *AutoUpgradeHistory) load() ([]AutoUpgradeRun, error) {
+	runs := []AutoUpgradeRun{}
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return runs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("invalid auto-upgrade history %s: %w", h.path, err)
+	}
+	return runs, nil
+}
+
+func (h *AutoUpgradeHistory) save(runs []AutoUpgradeRun) error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0644)
+}
+
+// autoUpgradeTick is how often the auto-upgrader checks whether a
+// maintenance window has opened.
+const autoUpgradeTick = time.Minute
+
+// AutoUpgrader upgrades outdated packages unattended, once per maintenance
+// window. It goes through the same queued upgrade the UI runs, so its
+// progress shows up like any other upgrade, and runs `brew cleanup`
+// afterwards when auto-cleanup is enabled.
+//
+// Each run is recorded in the history and announced with
+// "autoUpgradeFinished"; a failed run additionally emits "autoUpgradeFailed".
+// Both carry the JSON AutoUpgradeRun.
+type AutoUpgrader struct {
+	getSettings    func() AutoUpgradeSettings
+	updateDatabase func() error
+	outdated       func() ([]OutdatedPackage, error)
+	upgrade        func(ctx context.Context, packageNames []string) UpgradeResult
+	autoCleanup    func() bool
+	cleanup        func() string
+	history        *AutoUpgradeHistory
+	eventEmitter   EventEmitter
+	logFunc        func(string)
+	now            func() time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAutoUpgrader creates a stopped auto-upgrader. upgrade and cleanup are
+// the service's UpgradeSelectedPackages and RunBrewCleanup.
+func NewAutoUpgrader(
+	getSettings func() AutoUpgradeSettings,
+	updateDatabase func() error,
+	outdated func() ([]OutdatedPackage, error),
+	upgrade func(ctx context.Context, packageNames []string) UpgradeResult,
+	autoCleanup func() bool,
+	cleanup func() string,
+	history *AutoUpgradeHistory,
+	eventEmitter EventEmitter,
+	logFunc func(string),
+) *AutoUpgrader {
+	return &AutoUpgrader{
+		getSettings:    getSettings,
+		updateDatabase: updateDatabase,
+		outdated:       outdated,
+		upgrade:        upgrade,
+		autoCleanup:    autoCleanup,
+		cleanup:        cleanup,
+		history:        history,
+		eventEmitter:   eventEmitter,
+		logFunc:        logFunc,
+		now:            time.Now,
+	}
+}
+
+// Start checks for an open maintenance window every minute until ctx ends
+// or Stop is called.
+func (u *AutoUpgrader) Start(ctx context.Context) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.cancel != nil {
+		return
+	}
+	ctx, u.cancel = context.WithCancel(ctx)
+	u.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(autoUpgradeTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				u.tick(ctx)
+			}
+		}
+	}(u.done)
+}
+
+// Stop ends the auto-upgrader and waits for a running upgrade to finish or
+// be cancelled.
+func (u *AutoUpgrader) Stop() {
+	u.mu.Lock()
+	cancel, done := u.cancel, u.done
+	u.cancel, u.done = nil, nil
+	u.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// tick runs an upgrade if auto-upgrade is enabled, a maintenance window is
+// open and no run has started in it yet.
+func (u *AutoUpgrader) tick(ctx context.Context) {
+	settings := u.getSettings()
+	if !settings.Enabled {
+		return
+	}
+	window, err := ParseMaintenanceWindow(settings.Days, settings.WindowStart, settings.WindowEnd)
+	if err != nil {
+		return
+	}
+	opened, ok := window.Occurrence(u.now())
+	if !ok {
+		return
+	}
+
+	runs, err := u.history.Runs()
+	if err != nil {
+		u.log(fmt.Sprintf("Auto-upgrade skipped: %v", err))
+		return
+	}
+	if len(runs) > 0 && !runs[len(runs)-1].StartedAt.Before(opened) {
+		return
+	}
+	u.Run(ctx, settings)
+}
+
+// Run performs one unattended upgrade and records it.
+func (u *AutoUpgrader) Run(ctx context.Context, settings AutoUpgradeSettings) AutoUpgradeRun {
+	run := AutoUpgradeRun{StartedAt: u.now(), Packages: []string{}, Deferred: []string{}}
+
+	if err := u.updateDatabase(); err != nil {
+		u.log(fmt.Sprintf("Auto-upgrade: brew update failed: %v", err))
+	}
+
+	packages, err := u.outdated()
+	switch {
+	case err != nil:
+		run.Message = fmt.Sprintf("Failed to check for outdated packages: %v", err)
+	default:
+		run.Packages, run.Deferred = SelectAutoUpgrade(packages, settings)
+		if len(run.Packages) == 0 {
+			run.Success = true
+			break
+		}
+		result := u.upgrade(ctx, run.Packages)
+		run.Message, run.Success, run.Failed = result.Message, result.Success(), result.Failed()
+		if run.Success && u.autoCleanup() {
+			run.Cleanup = u.cleanup()
+		}
+	}
+	run.FinishedAt = u.now()
+
+	if run.Message != "" {
+		u.log(fmt.Sprintf("Auto-upgrade of %d package(s) finished: %s", len(run.Packages), run.Message))
+	}
+	if err := u.history.Append(run); err != nil {
+		u.log(fmt.Sprintf("Failed to record auto-upgrade run: %v", err))
+	}
+
+	if data, err := json.Marshal(run); err == nil {
+		u.eventEmitter.Emit("autoUpgradeFinished", string(data))
+		if !run.Success {
+			u.eventEmitter.Emit("autoUpgradeFailed", string(data))
+		}
+	}
+	return run
+}
+
+func (u *AutoUpgrader) log(message string) {
+	if u.logFunc != nil {
+		u.logFunc(message)
+	}
+}
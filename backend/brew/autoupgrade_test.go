@@ -0,0 +1,185 @@
+package brew
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_Occurrence(t *testing.T) {
+	// 2026-10-12 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name       string
+		days       []string
+		start, end string
+		now        time.Time
+		want       time.Time
+		wantOK     bool
+	}{
+		{"weekday lunch", []string{"weekdays"}, "12:00", "13:00", at(12, 12, 30), at(12, 12, 0), true},
+		{"end is exclusive", []string{"weekdays"}, "12:00", "13:00", at(12, 13, 0), time.Time{}, false},
+		{"not on the weekend", []string{"weekdays"}, "12:00", "13:00", at(17, 12, 30), time.Time{}, false},
+		{"every day by default", nil, "12:00", "13:00", at(18, 12, 0), at(18, 12, 0), true},
+		{"overnight before midnight", []string{"fri"}, "22:00", "02:00", at(16, 23, 0), at(16, 22, 0), true},
+		{"overnight after midnight belongs to the opening day", []string{"fri"}, "22:00", "02:00", at(17, 1, 0), at(16, 22, 0), true},
+		{"overnight on the wrong day", []string{"fri"}, "22:00", "02:00", at(16, 1, 0), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := ParseMaintenanceWindow(tt.days, tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, ok := window.Occurrence(tt.now)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Fatalf("Occurrence(%v) = %v, %v; want %v, %v", tt.now, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	for _, bad := range [][3]string{{"mon", "25:00", "13:00"}, {"mon", "12:00", "12:00"}, {"someday", "12:00", "13:00"}} {
+		if _, err := ParseMaintenanceWindow([]string{bad[0]}, bad[1], bad[2]); err == nil {
+			t.Errorf("ParseMaintenanceWindow(%v) should fail", bad)
+		}
+	}
+}
+
+func TestSelectAutoUpgrade(t *testing.T) {
+	packages := []OutdatedPackage{
+		{Name: "wget", PolicyAllowed: true},
+		{Name: "python", PolicyAllowed: true, Pinned: true},
+		{Name: "node", PolicyAllowed: false},
+		{Name: "git", PolicyAllowed: true},
+		{Name: "firefox", PolicyAllowed: true},
+		{Name: "jq", PolicyAllowed: true},
+	}
+
+	tests := []struct {
+		name         string
+		settings     AutoUpgradeSettings
+		want, defers []string
+	}{
+		{"skips pinned and held", AutoUpgradeSettings{}, []string{"wget", "git", "firefox", "jq"}, []string{}},
+		{"deny list", AutoUpgradeSettings{Deny: []string{"firefox"}}, []string{"wget", "git", "jq"}, []string{}},
+		{"allow list", AutoUpgradeSettings{Allow: []string{"git", "python"}}, []string{"git"}, []string{}},
+		{"limit defers the rest", AutoUpgradeSettings{MaxPackages: 2}, []string{"wget", "git"}, []string{"firefox", "jq"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, deferred := SelectAutoUpgrade(packages, tt.settings)
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(deferred, tt.defers) {
+				t.Fatalf("SelectAutoUpgrade() = %v, %v; want %v, %v", got, deferred, tt.want, tt.defers)
+			}
+		})
+	}
+}
+
+// autoUpgradeFixture wires an AutoUpgrader to stubs that record the upgrade
+// and cleanup calls.
+type autoUpgradeFixture struct {
+	upgrader *AutoUpgrader
+	history  *AutoUpgradeHistory
+	emitter  *recordingEmitter
+	result   func(names []string) UpgradeResult
+	upgraded [][]string
+	cleaned  int
+	now      time.Time
+}
+
+func newAutoUpgradeFixture(t *testing.T, settings AutoUpgradeSettings, outdated []OutdatedPackage) *autoUpgradeFixture {
+	f := &autoUpgradeFixture{
+		history: NewAutoUpgradeHistory(filepath.Join(t.TempDir(), "history.json")),
+		emitter: &recordingEmitter{},
+	}
+	f.result = func(names []string) UpgradeResult {
+		return uniformUpgradeResult(names, "✅ Successfully updated", true)
+	}
+	f.upgrader = NewAutoUpgrader(
+		func() AutoUpgradeSettings { return settings },
+		func() error { return nil },
+		func() ([]OutdatedPackage, error) { return outdated, nil },
+		func(_ context.Context, names []string) UpgradeResult {
+			f.upgraded = append(f.upgraded, names)
+			return f.result(names)
+		},
+		func() bool { return true },
+		func() string { f.cleaned++; return "Removing old versions" },
+		f.history,
+		f.emitter,
+		nil,
+	)
+	f.upgrader.now = func() time.Time { return f.now }
+	return f
+}
+
+func TestAutoUpgrader_runsOncePerWindow(t *testing.T) {
+	settings := AutoUpgradeSettings{Enabled: true, Days: []string{"weekdays"}, WindowStart: "12:00", WindowEnd: "13:00", MaxPackages: 1}
+	f := newAutoUpgradeFixture(t, settings, []OutdatedPackage{
+		{Name: "wget", PolicyAllowed: true},
+		{Name: "git", PolicyAllowed: true},
+	})
+
+	for _, now := range []time.Time{
+		time.Date(2026, 10, 12, 11, 59, 0, 0, time.Local), // before the window
+		time.Date(2026, 10, 12, 12, 5, 0, 0, time.Local),
+		time.Date(2026, 10, 12, 12, 6, 0, 0, time.Local), // same window again
+		time.Date(2026, 10, 13, 12, 0, 0, 0, time.Local), // next day's window
+	} {
+		f.now = now
+		f.upgrader.tick(context.Background())
+	}
+
+	want := [][]string{{"wget"}, {"wget"}}
+	if !reflect.DeepEqual(f.upgraded, want) {
+		t.Fatalf("upgrades = %v, want %v", f.upgraded, want)
+	}
+	if f.cleaned != 2 {
+		t.Fatalf("expected cleanup after each run, got %d", f.cleaned)
+	}
+	runs, _ := f.history.Runs()
+	if len(runs) != 2 || !runs[0].Success || !reflect.DeepEqual(runs[0].Deferred, []string{"git"}) {
+		t.Fatalf("unexpected history: %+v", runs)
+	}
+	if f.emitter.count("autoUpgradeFinished") != 2 || f.emitter.count("autoUpgradeFailed") != 0 {
+		t.Fatal("expected two finished and no failed events")
+	}
+}
+
+func TestAutoUpgrader_failureStaysUntilAcknowledged(t *testing.T) {
+	settings := AutoUpgradeSettings{Enabled: true}
+	f := newAutoUpgradeFixture(t, settings, []OutdatedPackage{{Name: "wget", PolicyAllowed: true}})
+	// The upgrade reports a retry as done, but wget still failed.
+	f.result = func([]string) UpgradeResult {
+		return UpgradeResult{Message: "✅ Retried 1 failed cask(s) with --force", Packages: []PackageUpgradeResult{
+			{Name: "wget", Error: "exit status 1"},
+		}}
+	}
+
+	run := f.upgrader.Run(context.Background(), settings)
+	if run.Success || f.cleaned != 0 || !reflect.DeepEqual(run.Failed, []string{"wget"}) {
+		t.Fatalf("failed run: success = %v, failed = %v, cleanups = %d", run.Success, run.Failed, f.cleaned)
+	}
+	if f.emitter.count("autoUpgradeFailed") != 1 {
+		t.Fatal("expected autoUpgradeFailed")
+	}
+
+	// A fresh history on the same file still reports the failure.
+	reopened := NewAutoUpgradeHistory(f.history.path)
+	if failures, err := reopened.Failures(); err != nil || len(failures) != 1 {
+		t.Fatalf("Failures() = %v, %v; want one", failures, err)
+	}
+	if err := reopened.Acknowledge(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures, _ := f.history.Failures(); len(failures) != 0 {
+		t.Fatalf("expected no failures after acknowledging, got %v", failures)
+	}
+}
@@ -0,0 +1,87 @@
+package brew
+
+import (
+	"sort"
+	"strings"
+
+	"WailBrew/backend/brewfile"
+)
+
+// BrewfileExportOptions selects what ExportFilteredBrewfile writes. Formulae
+// are those installed on request plus leaves, narrowed to leaves with
+// LeavesOnly; casks are always included. A non-empty Only keeps just the
+// named packages, by short or full name.
+type BrewfileExportOptions struct {
+	LeavesOnly bool     `json:"leavesOnly"`
+	Only       []string `json:"only"`
+}
+
+// Brewfile builds a Brewfile for the installed packages options selects,
+// with the taps they come from, without running `brew bundle dump`.
+func (g *DependencyGraph) Brewfile(options BrewfileExportOptions) *brewfile.File {
+	only := make(map[string]bool, len(options.Only))
+	for _, name := range options.Only {
+		only[name] = true
+	}
+	leaves := make(map[string]bool)
+	for _, name := range g.Leaves() {
+		leaves[name] = true
+	}
+
+	var formulae, casks []string
+	taps := make(map[string]bool)
+	for _, node := range g.nodes {
+		switch {
+		case len(only) > 0 && !only[node.name] && !only[node.fullName]:
+			continue
+		case node.cask:
+			casks = append(casks, node.fullName)
+		case options.LeavesOnly && !leaves[node.fullName]:
+			continue
+		case node.onRequest || leaves[node.fullName]:
+			formulae = append(formulae, node.fullName)
+		default:
+			continue
+		}
+		if tap, ok := tapOf(node.fullName); ok {
+			taps[tap] = true
+		}
+	}
+
+	file := &brewfile.File{}
+	for _, tap := range sortedKeys(taps) {
+		file.Add(brewfile.Entry{Kind: brewfile.Tap, Name: tap})
+	}
+	sort.Strings(formulae)
+	for _, name := range formulae {
+		file.Add(brewfile.Entry{Kind: brewfile.Brew, Name: name})
+	}
+	sort.Strings(casks)
+	for _, name := range casks {
+		file.Add(brewfile.Entry{Kind: brewfile.Cask, Name: name})
+	}
+	return file
+}
+
+// tapOf returns the tap of a fully qualified name such as
+// "acme/tools/widget". The core taps are implied and never returned.
+func tapOf(fullName string) (string, bool) {
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 3 {
+		return "", false
+	}
+	tap := parts[0] + "/" + parts[1]
+	if tap == "homebrew/core" || tap == "homebrew/cask" {
+		return "", false
+	}
+	return tap, true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,64 @@
+package brew
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDependencyGraph_Brewfile(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	tests := []struct {
+		name    string
+		options BrewfileExportOptions
+		want    string
+	}{
+		{
+			name: "on request and leaves",
+			want: "tap \"acme/tools\"\nbrew \"acme/tools/widget\"\nbrew \"app\"\nbrew \"cmake\"\nbrew \"legacy\"\nbrew \"ping\"\n" +
+				"cask \"browser\"\ncask \"nightly\"\ncask \"tool-app\"\n",
+		},
+		{
+			name:    "leaves only",
+			options: BrewfileExportOptions{LeavesOnly: true},
+			want: "tap \"acme/tools\"\nbrew \"acme/tools/widget\"\nbrew \"app\"\nbrew \"cmake\"\nbrew \"legacy\"\n" +
+				"cask \"browser\"\ncask \"nightly\"\ncask \"tool-app\"\n",
+		},
+		{
+			name:    "favorites by short name",
+			options: BrewfileExportOptions{Only: []string{"widget", "browser", "lib"}},
+			want:    "tap \"acme/tools\"\nbrew \"acme/tools/widget\"\ncask \"browser\"\n",
+		},
+		{
+			name:    "favorite leaves",
+			options: BrewfileExportOptions{LeavesOnly: true, Only: []string{"app", "ping"}},
+			want:    "brew \"app\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(graph.Brewfile(tt.options).Bytes()); got != tt.want {
+				t.Fatalf("Brewfile() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportFilteredBrewfile_writesFile(t *testing.T) {
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	service, _ := newFakeService(fb)
+	path := filepath.Join(t.TempDir(), "Brewfile")
+
+	if err := service.ExportFilteredBrewfile(path, BrewfileExportOptions{Only: []string{"cmake"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "brew \"cmake\"\n" {
+		t.Fatalf("Brewfile = %q, %v", data, err)
+	}
+	if len(fb.calls) != 1 {
+		t.Fatalf("filtered export must only read the inventory, calls: %v", fb.calls)
+	}
+}
@@ -0,0 +1,471 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"WailBrew/backend/brewfile"
+)
+
+// Brewfile entry types, named as `brew bundle` names them.
+const (
+	BrewfileTap     = "tap"
+	BrewfileFormula = "formula"
+	BrewfileCask    = "cask"
+	BrewfileMas     = "mas"
+	BrewfileVSCode  = "vscode"
+)
+
+var brewfileEntryTypes = map[brewfile.Kind]string{
+	brewfile.Tap:    BrewfileTap,
+	brewfile.Brew:   BrewfileFormula,
+	brewfile.Cask:   BrewfileCask,
+	brewfile.Mas:    BrewfileMas,
+	brewfile.VSCode: BrewfileVSCode,
+}
+
+// Brewfile entry states reported in BrewfileEntryStatus.Status.
+const (
+	BrewfileStatusSatisfied = "satisfied"
+	BrewfileStatusMissing   = "missing"
+	BrewfileStatusOutdated  = "outdated"
+)
+
+// BrewfilePreview is what applying a Brewfile would change.
+type BrewfilePreview struct {
+	Path    string                `json:"path"`
+	Command string                `json:"command"`
+	Entries []BrewfileEntryStatus `json:"entries"`
+	// Taps, Install and Upgrade name the entries applying would tap,
+	// install and upgrade.
+	Taps    []string `json:"taps"`
+	Install []string `json:"install"`
+	Upgrade []string `json:"upgrade"`
+}
+
+// BrewfileEntryStatus is one Brewfile entry and whether it is satisfied.
+// Conditional entries are guarded by Ruby, such as `if OS.mac?`, that brew
+// evaluates when the file is applied.
+type BrewfileEntryStatus struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Conditional bool   `json:"conditional,omitempty"`
+}
+
+// bundleCheckPattern matches the unsatisfied entries `brew bundle check
+// --verbose` lists, as in "→ Formula wget needs to be installed or updated."
+var bundleCheckPattern = regexp.MustCompile(`^(?:→\s*)?(Tap|Formula|Cask|App|VSCode Extension)\s+(\S+)\s+needs to be`)
+
+// bundleCheckKinds maps the words brew bundle check uses to entry kinds.
+var bundleCheckKinds = map[string]string{
+	"Tap":              BrewfileTap,
+	"Formula":          BrewfileFormula,
+	"Cask":             BrewfileCask,
+	"App":              BrewfileMas,
+	"VSCode Extension": BrewfileVSCode,
+}
+
+// parseBundleCheck returns the unsatisfied entries keyed by kind and name.
+func parseBundleCheck(output string) map[string]bool {
+	missing := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if m := bundleCheckPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			missing[bundleCheckKinds[m[1]]+" "+m[2]] = true
+		}
+	}
+	return missing
+}
+
+// bundleEntryPattern matches the per-entry lines of `brew bundle install`,
+// as in "Installing wget", "Using git" or "Tapping acme/tools has failed!".
+var bundleEntryPattern = regexp.MustCompile(`^(Using|Installing|Upgrading|Tapping|Skipping)\s+(\S+)(\s+has failed!)?`)
+
+// bundleProgressLine decorates a `brew bundle install` output line for the
+// progress log and reports the entry it names if that entry failed.
+func bundleProgressLine(line string) (message, failed string) {
+	m := bundleEntryPattern.FindStringSubmatch(line)
+	switch {
+	case m == nil:
+		return fmt.Sprintf("📦 %s", line), ""
+	case m[3] != "":
+		return fmt.Sprintf("❌ %s", line), m[2]
+	case m[1] == "Using" || m[1] == "Skipping":
+		return fmt.Sprintf("✅ %s", line), ""
+	default:
+		return fmt.Sprintf("⬇️ %s", line), ""
+	}
+}
+
+// BrewfileCleanupItem is an installed formula, cask or tap a Brewfile does
+// not mention, which `brew bundle cleanup` would remove.
+type BrewfileCleanupItem struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size string `json:"size"`
+	// Dependents are the installed packages that depend directly on this one.
+	Dependents []string `json:"dependents"`
+}
+
+// bundleCleanupHeaders maps the section headers of `brew bundle cleanup`
+// dry-run output to entry types.
+var bundleCleanupHeaders = map[string]string{
+	"Would uninstall formulae:": BrewfileFormula,
+	"Would uninstall casks:":    BrewfileCask,
+	"Would untap:":              BrewfileTap,
+}
+
+// parseBundleCleanup returns the names listed under each section of `brew
+// bundle cleanup` dry-run output, keyed by entry type. Other sections, such
+// as the files `brew cleanup` would delete, are skipped.
+func parseBundleCleanup(output string) map[string][]string {
+	listed := make(map[string][]string)
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if kind, ok := bundleCleanupHeaders[line]; ok {
+			section = kind
+			continue
+		}
+		if strings.HasSuffix(line, ":") || strings.HasPrefix(line, "Run `brew bundle") {
+			section = ""
+			continue
+		}
+		if section != "" {
+			// Columns are space separated when brew thinks it has a terminal.
+			listed[section] = append(listed[section], strings.Fields(line)...)
+		}
+	}
+	return listed
+}
+
+// BundleService previews and applies Brewfiles through `brew bundle`.
+type BundleService struct {
+	runner          Runner
+	getBackendMsg   func(string, map[string]string) string
+	eventEmitter    EventEmitter
+	dependencyGraph func() (*DependencyGraph, error)
+	getSizes        func(names []string, isCask bool) map[string]string
+}
+
+// NewBundleService creates a new bundle service
+func NewBundleService(
+	runner Runner,
+	getBackendMsg func(string, map[string]string) string,
+	eventEmitter EventEmitter,
+	dependencyGraph func() (*DependencyGraph, error),
+	getSizes func(names []string, isCask bool) map[string]string,
+) *BundleService {
+	return &BundleService{
+		runner:          runner,
+		getBackendMsg:   getBackendMsg,
+		eventEmitter:    eventEmitter,
+		dependencyGraph: dependencyGraph,
+		getSizes:        getSizes,
+	}
+}
+
+// PreviewBrewfile reads the entries of a Brewfile and marks those `brew
+// bundle check` reports as unsatisfied: packages that are installed need an
+// upgrade, everything else is missing.
+func (s *BundleService) PreviewBrewfile(path string) (*BrewfilePreview, error) {
+	file, err := brewfile.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read Brewfile: %v", err)
+	}
+	graph, err := s.dependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &BrewfilePreview{
+		Path:    path,
+		Command: FormatCommand(BuildBundleInstallArgs(path)),
+		Entries: []BrewfileEntryStatus{},
+		Taps:    []string{},
+		Install: []string{},
+		Upgrade: []string{},
+	}
+	for _, entry := range file.Entries() {
+		preview.Entries = append(preview.Entries, BrewfileEntryStatus{
+			Name:        entry.Name,
+			Type:        brewfileEntryTypes[entry.Kind],
+			Conditional: entry.Conditional(),
+		})
+	}
+
+	// check exits non-zero whenever something is unsatisfied, so only treat
+	// it as failed when it did not say what.
+	output, err := s.runner.RunNoCacheWithTimeout(2*time.Minute, BuildBundleCheckArgs(path)...)
+	missing := parseBundleCheck(string(output))
+	if err != nil && len(missing) == 0 {
+		return nil, fmt.Errorf("brew bundle check failed: %v", err)
+	}
+
+	for i := range preview.Entries {
+		entry := &preview.Entries[i]
+		switch {
+		case !missing[entry.Type+" "+entry.Name]:
+			entry.Status = BrewfileStatusSatisfied
+		case entry.Type == BrewfileTap:
+			entry.Status = BrewfileStatusMissing
+			preview.Taps = append(preview.Taps, entry.Name)
+		case (entry.Type == BrewfileFormula || entry.Type == BrewfileCask) && graph.Has(entry.Name):
+			entry.Status = BrewfileStatusOutdated
+			preview.Upgrade = append(preview.Upgrade, entry.Name)
+		default:
+			entry.Status = BrewfileStatusMissing
+			preview.Install = append(preview.Install, entry.Name)
+		}
+	}
+	return preview, nil
+}
+
+// ApplyBrewfile runs `brew bundle install` with live progress updates, one
+// "brewfileApplyProgress" event per output line and "brewfileApplyComplete"
+// with the final message.
+func (s *BundleService) ApplyBrewfile(ctx context.Context, path string) string {
+	result, _ := s.applyBrewfile(ctx, path)
+	return result
+}
+
+// applyBrewfile is ApplyBrewfile also reporting whether it succeeded.
+func (s *BundleService) applyBrewfile(ctx context.Context, path string) (string, bool) {
+	startMessage := s.getBackendMsg("backend.brewfile.applyStart", map[string]string{"path": path})
+	s.eventEmitter.Emit("brewfileApplyProgress", startMessage)
+
+	var failed []string
+	phase, _, err := s.runner.Stream(ctx, BuildBundleInstallArgs(path),
+		func(line string) {
+			message, failedEntry := bundleProgressLine(line)
+			if failedEntry != "" {
+				failed = append(failed, failedEntry)
+			}
+			s.eventEmitter.Emit("brewfileApplyProgress", message)
+		},
+		func(line string) { s.eventEmitter.Emit("brewfileApplyProgress", fmt.Sprintf("⚠️ %s", line)) },
+	)
+
+	var finalMessage string
+	switch phase {
+	case phaseStdoutPipe:
+		finalMessage = s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
+	case phaseStderrPipe:
+		finalMessage = s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
+	case phaseStart:
+		finalMessage = s.getBackendMsg("backend.brewfile.applyFailed", map[string]string{"error": err.Error()})
+	case phaseCancelled:
+		finalMessage = s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+	case phaseRun:
+		errorText := err.Error()
+		if len(failed) > 0 {
+			errorText = strings.Join(failed, ", ")
+		}
+		finalMessage = s.getBackendMsg("backend.brewfile.applyFailed", map[string]string{"error": errorText})
+	default:
+		finalMessage = s.getBackendMsg("backend.brewfile.applySuccess", map[string]string{"path": path})
+	}
+
+	s.eventEmitter.Emit("brewfileApplyProgress", finalMessage)
+	s.eventEmitter.Emit("brewfileApplyComplete", finalMessage)
+	return finalMessage, phase == phaseNone
+}
+
+// BrewfileCleanup lists what `brew bundle cleanup` would remove to match the
+// Brewfile at path: casks, then formulae, then taps, with the size and
+// installed dependents of each package.
+func (s *BundleService) BrewfileCleanup(path string) ([]BrewfileCleanupItem, error) {
+	graph, err := s.dependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+	listed, err := s.cleanupListing(path)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []BrewfileCleanupItem{}
+	for _, kind := range []string{BrewfileCask, BrewfileFormula, BrewfileTap} {
+		names := listed[kind]
+		if len(names) == 0 {
+			continue
+		}
+		sizes := map[string]string{}
+		if kind != BrewfileTap {
+			sizes = s.getSizes(names, kind == BrewfileCask)
+		}
+		for _, name := range names {
+			items = append(items, BrewfileCleanupItem{
+				Name:       name,
+				Type:       kind,
+				Size:       sizes[name],
+				Dependents: graph.Dependents(name, false),
+			})
+		}
+	}
+	return items, nil
+}
+
+// cleanupListing runs the `brew bundle cleanup` dry run for path.
+func (s *BundleService) cleanupListing(path string) (map[string][]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("Failed to read Brewfile: %v", err)
+	}
+	// Without --force, cleanup exits non-zero whenever it would change
+	// something, so only treat it as failed when it did not say what.
+	output, err := s.runner.RunNoCacheWithTimeout(2*time.Minute, BuildBundleCleanupArgs(path)...)
+	listed := parseBundleCleanup(string(output))
+	if err != nil && len(listed) == 0 {
+		return nil, fmt.Errorf("brew bundle cleanup failed: %v", err)
+	}
+	return listed, nil
+}
+
+// removalOrder orders packages so each is removed after everything in the
+// list that depends on it, since brew refuses to uninstall a package that is
+// still required. Packages in a dependency cycle keep their listed order.
+func removalOrder(graph *DependencyGraph, names []string) []string {
+	pending := make(map[string]bool, len(names))
+	for _, name := range names {
+		pending[name] = true
+	}
+
+	ordered := make([]string, 0, len(names))
+	for len(ordered) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if !pending[name] {
+				continue
+			}
+			blocked := false
+			for _, dependent := range graph.Dependents(name, true) {
+				if pending[dependent] && dependent != name {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ordered = append(ordered, name)
+				pending[name] = false
+				progressed = true
+			}
+		}
+		if !progressed {
+			for _, name := range names {
+				if pending[name] {
+					ordered = append(ordered, name)
+					pending[name] = false
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// RunBrewfileCleanup removes the names in confirmed, the items of the
+// BrewfileCleanup preview the user agreed to remove. Nothing outside
+// confirmed is touched, and a confirmed name the Brewfile no longer leaves
+// out is refused rather than removed. Packages are removed with remove and
+// taps with untap, so each step runs, streams and fails exactly like a
+// manual uninstall or untap; "brewfileCleanupProgress" announces each step
+// and "brewfileCleanupComplete" carries the summary. A cancelled step stops
+// the remaining ones.
+func (s *BundleService) RunBrewfileCleanup(
+	ctx context.Context,
+	path string,
+	confirmed []string,
+	remove func(ctx context.Context, name string) (string, bool),
+	untap func(ctx context.Context, name string) (string, bool),
+) string {
+	finish := func(message string) string {
+		s.eventEmitter.Emit("brewfileCleanupProgress", message)
+		s.eventEmitter.Emit("brewfileCleanupComplete", message)
+		return message
+	}
+
+	// List again so a stale preview cannot remove what the Brewfile now
+	// lists.
+	listed, err := s.cleanupListing(path)
+	if err != nil {
+		return finish(s.getBackendMsg("backend.brewfileCleanup.failed", map[string]string{"error": err.Error()}))
+	}
+	graph, err := s.dependencyGraph()
+	if err != nil {
+		return finish(s.getBackendMsg("backend.brewfileCleanup.failed", map[string]string{"error": err.Error()}))
+	}
+
+	pending := make(map[string]bool, len(confirmed))
+	for _, name := range confirmed {
+		pending[name] = true
+	}
+	only := func(names []string) []string {
+		selected := []string{}
+		for _, name := range names {
+			if pending[name] {
+				selected = append(selected, name)
+				delete(pending, name)
+			}
+		}
+		return selected
+	}
+	casks := only(listed[BrewfileCask])
+	formulae := removalOrder(graph, only(listed[BrewfileFormula]))
+	taps := only(listed[BrewfileTap])
+	if len(pending) > 0 {
+		refused := make([]string, 0, len(pending))
+		for name := range pending {
+			refused = append(refused, name)
+		}
+		sort.Strings(refused)
+		s.eventEmitter.Emit("brewfileCleanupProgress", s.getBackendMsg("backend.brewfileCleanup.refused", map[string]string{
+			"names": strings.Join(refused, ", "),
+		}))
+	}
+
+	type step struct {
+		name string
+		run  func(ctx context.Context, name string) (string, bool)
+	}
+	var steps []step
+	for _, name := range append(casks, formulae...) {
+		steps = append(steps, step{name, remove})
+	}
+	for _, name := range taps {
+		steps = append(steps, step{name, untap})
+	}
+	if len(steps) == 0 {
+		return finish(s.getBackendMsg("backend.brewfileCleanup.nothingToRemove", map[string]string{}))
+	}
+
+	cancelled := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+	var failed []string
+	for i, st := range steps {
+		if ctx.Err() != nil {
+			return finish(cancelled)
+		}
+		s.eventEmitter.Emit("brewfileCleanupProgress", s.getBackendMsg("backend.brewfileCleanup.step", map[string]string{
+			"name":    st.name,
+			"current": fmt.Sprint(i + 1),
+			"total":   fmt.Sprint(len(steps)),
+		}))
+		_, ok := st.run(ctx, st.name)
+		if ctx.Err() != nil {
+			return finish(cancelled)
+		}
+		if !ok {
+			failed = append(failed, st.name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return finish(s.getBackendMsg("backend.brewfileCleanup.partial", map[string]string{
+			"failed": strings.Join(failed, ", "),
+		}))
+	}
+	return finish(s.getBackendMsg("backend.brewfileCleanup.success", map[string]string{"count": fmt.Sprint(len(steps))}))
+}
@@ -0,0 +1,236 @@
+package brew
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordedBundleCheck is `brew bundle check --verbose` output for a Brewfile
+// with a missing tap, an outdated and a missing formula.
+const recordedBundleCheck = `brew bundle can't satisfy your Brewfile's dependencies.
+→ Tap acme/extra needs to be tapped.
+→ Formula app needs to be installed or updated.
+→ Formula wget needs to be installed or updated.
+Satisfy missing dependencies with ` + "`brew bundle install`" + `.
+`
+
+// testBrewfile guards two entries with Ruby conditions and builds one
+// directive from an expression, which previews cannot list.
+const testBrewfile = `tap "acme/extra"
+brew "app"
+brew "base"
+brew "wget" if OS.mac?
+if OS.mac?
+  cask "browser"
+end
+brew ENV["EXTRA_FORMULA"] if ENV["EXTRA_FORMULA"]
+`
+
+func writeBrewfile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	if err := os.WriteFile(path, []byte(testBrewfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPreviewBrewfile(t *testing.T) {
+	path := writeBrewfile(t)
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("bundle check --verbose --file="+path, brewRecording{stdout: recordedBundleCheck, exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	preview, err := service.PreviewBrewfile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v, calls: %v", err, fb.calls)
+	}
+
+	want := []BrewfileEntryStatus{
+		{Name: "acme/extra", Type: BrewfileTap, Status: BrewfileStatusMissing},
+		{Name: "app", Type: BrewfileFormula, Status: BrewfileStatusOutdated},
+		{Name: "base", Type: BrewfileFormula, Status: BrewfileStatusSatisfied},
+		{Name: "wget", Type: BrewfileFormula, Status: BrewfileStatusMissing, Conditional: true},
+		{Name: "browser", Type: BrewfileCask, Status: BrewfileStatusSatisfied, Conditional: true},
+	}
+	if !reflect.DeepEqual(preview.Entries, want) {
+		t.Fatalf("Entries = %+v", preview.Entries)
+	}
+	if !reflect.DeepEqual(preview.Taps, []string{"acme/extra"}) ||
+		!reflect.DeepEqual(preview.Install, []string{"wget"}) ||
+		!reflect.DeepEqual(preview.Upgrade, []string{"app"}) {
+		t.Fatalf("taps %v, install %v, upgrade %v", preview.Taps, preview.Install, preview.Upgrade)
+	}
+	if preview.Command != "brew bundle install --file="+path {
+		t.Fatalf("Command = %q", preview.Command)
+	}
+}
+
+func TestPreviewBrewfile_errors(t *testing.T) {
+	service, _ := newFakeService(newFakeBrew())
+	if _, err := service.PreviewBrewfile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected an error for a missing Brewfile")
+	}
+
+	// A failing check that names nothing unsatisfied is a real failure.
+	path := writeBrewfile(t)
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	fb.script("bundle check --verbose --file="+path, brewRecording{stderr: "Error: Invalid Brewfile", exitCode: 1})
+	service, _ = newFakeService(fb)
+	if _, err := service.PreviewBrewfile(path); err == nil {
+		t.Fatal("expected the check error")
+	}
+}
+
+func TestApplyBrewfile(t *testing.T) {
+	fb := newFakeBrew().script("bundle install --file=/tmp/Brewfile", brewRecording{
+		stdout:   "Using acme/extra\nInstalling wget\nInstalling app has failed!\nHomebrew Bundle failed! 1 Brewfile dependency failed to install.\n",
+		exitCode: 1,
+	})
+	service, emitter := newFakeService(fb)
+
+	if got := service.ApplyBrewfile(context.Background(), "/tmp/Brewfile"); got != "backend.brewfile.applyFailed" {
+		t.Fatalf("result = %q", got)
+	}
+	for _, line := range []string{"✅ Using acme/extra", "⬇️ Installing wget", "❌ Installing app has failed!"} {
+		if !emitter.has("brewfileApplyProgress", line) {
+			t.Errorf("missing progress line %q", line)
+		}
+	}
+	if emitter.count("brewfileApplyComplete") != 1 {
+		t.Fatal("expected brewfileApplyComplete")
+	}
+}
+
+// recordedBundleCleanup is `brew bundle cleanup` dry-run output for a
+// Brewfile that lists none of the recorded inventory's extras.
+const recordedBundleCleanup = `Would uninstall casks:
+browser
+Would uninstall formulae:
+base
+lib
+legacy
+Would untap:
+acme/tools
+Would ` + "`brew cleanup`" + `:
+Removing: /opt/homebrew/Cellar/old/1.0... (3 files, 12KB)
+Run ` + "`brew bundle cleanup --force`" + ` to make these changes.
+`
+
+func TestGetBrewfileCleanup(t *testing.T) {
+	path := writeBrewfile(t)
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("bundle cleanup --file="+path, brewRecording{stdout: recordedBundleCleanup, exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	items, err := service.GetBrewfileCleanup(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v, calls: %v", err, fb.calls)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Type+" "+item.Name)
+	}
+	want := []string{"cask browser", "formula base", "formula lib", "formula legacy", "tap acme/tools"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(items[2].Dependents, []string{"app", "legacy", "tool-app"}) {
+		t.Fatalf("lib dependents = %v", items[2].Dependents)
+	}
+}
+
+func TestRunBrewfileCleanup(t *testing.T) {
+	path := writeBrewfile(t)
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("bundle cleanup --file="+path, brewRecording{stdout: recordedBundleCleanup, exitCode: 1}).
+		script("uninstall legacy", brewRecording{}).
+		script("uninstall lib", brewRecording{stderr: "Error: Refusing to uninstall lib", exitCode: 1}).
+		script("uninstall base", brewRecording{}).
+		script("untap acme/tools", brewRecording{})
+	service, emitter := newFakeService(fb)
+
+	// wget was confirmed from a stale preview and is no longer listed.
+	confirmed := []string{"base", "lib", "legacy", "acme/tools", "wget"}
+	if got := service.RunBrewfileCleanup(context.Background(), path, confirmed); got != "backend.brewfileCleanup.partial" {
+		t.Fatalf("result = %q", got)
+	}
+
+	// Dependents go first, the unconfirmed cask is left alone, taps go last.
+	var order []string
+	for _, call := range fb.calls {
+		if strings.HasPrefix(call, "uninstall ") || strings.HasPrefix(call, "untap ") {
+			order = append(order, call)
+		}
+	}
+	want := []string{"uninstall legacy", "uninstall lib", "uninstall base", "untap acme/tools"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("calls = %v, want %v", order, want)
+	}
+	if emitter.count("packageUninstallComplete") != 3 || emitter.count("brewfileCleanupComplete") != 1 {
+		t.Fatal("expected the regular uninstall events and one cleanup summary")
+	}
+	if fb.invoked("uninstall wget") != 0 || !emitter.has("brewfileCleanupProgress", "backend.brewfileCleanup.refused") {
+		t.Error("expected the unlisted name to be refused")
+	}
+}
+
+func TestRunBrewfileCleanupStopsOnCancel(t *testing.T) {
+	path := writeBrewfile(t)
+	fb := newFakeBrew().
+		script("bundle cleanup --file="+path, brewRecording{stdout: recordedBundleCleanup, exitCode: 1}).
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	service, _ := newFakeService(fb)
+	bundle := service.(*serviceImpl).bundleService
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var removed []string
+	remove := func(_ context.Context, name string) (string, bool) {
+		removed = append(removed, name)
+		cancel()
+		return "backend.uninstall.failed", false
+	}
+	untap := func(_ context.Context, name string) (string, bool) {
+		t.Errorf("untap %s ran after the cleanup was cancelled", name)
+		return "", false
+	}
+	got := bundle.RunBrewfileCleanup(ctx, path, []string{"browser", "base", "acme/tools"}, remove, untap)
+	if got != "backend.operation.cancelled" {
+		t.Fatalf("result = %q", got)
+	}
+	if !reflect.DeepEqual(removed, []string{"browser"}) {
+		t.Errorf("removed = %v, want only the first step", removed)
+	}
+}
+
+func TestBrewfileCleanupStepStopsOnQueueCancel(t *testing.T) {
+	service, _ := newFakeService(newFakeBrew())
+	impl := service.(*serviceImpl)
+
+	stopped := false
+	stop := func() { stopped = true }
+	impl.cleanupStep(context.Background(), stop, "uninstall", "base", func(ctx context.Context) (string, bool) {
+		// The user cancels the running step from the operation queue.
+		impl.CancelOperation(impl.GetOperationQueue()[0].ID)
+		return "backend.uninstall.failed", false
+	})
+	if !stopped {
+		t.Fatal("expected a step cancelled from the queue to stop the cleanup")
+	}
+
+	stopped = false
+	impl.cleanupStep(context.Background(), stop, "uninstall", "lib", func(context.Context) (string, bool) {
+		return "backend.uninstall.failed", false
+	})
+	if stopped {
+		t.Fatal("a failed step must not stop the cleanup")
+	}
+}
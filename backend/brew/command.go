@@ -58,6 +58,56 @@ func BuildUpgradeAllArgs(outdatedFlag string) []string {
 	return append([]string{"upgrade"}, greedyFlags(outdatedFlag)...)
 }
 
+// BuildPinArgs builds the arguments for pinning a formula at its installed
+// version. Only formulae can be pinned.
+func BuildPinArgs(name string) []string {
+	return []string{"pin", name}
+}
+
+// BuildUnpinArgs builds the arguments for unpinning a formula.
+func BuildUnpinArgs(name string) []string {
+	return []string{"unpin", name}
+}
+
+// BuildReinstallArgs builds the arguments for reinstalling a package from
+// scratch, e.g. after its keg was damaged.
+func BuildReinstallArgs(name string) []string {
+	return []string{"reinstall", name}
+}
+
+// BuildLinkArgs builds the arguments for symlinking a formula into the
+// prefix. overwrite deletes files that are in the way; force is needed for
+// keg-only formulae.
+func BuildLinkArgs(name string, overwrite, force bool) []string {
+	args := []string{"link"}
+	if overwrite {
+		args = append(args, "--overwrite")
+	}
+	if force {
+		args = append(args, "--force")
+	}
+	return append(args, name)
+}
+
+// BuildUnlinkArgs builds the arguments for removing a formula's symlinks from
+// the prefix. The keg itself stays installed.
+func BuildUnlinkArgs(name string) []string {
+	return []string{"unlink", name}
+}
+
+// BuildTapNewArgs builds the arguments for creating an empty local tap. It is
+// never pushed anywhere, so it needs no git history.
+func BuildTapNewArgs(name string) []string {
+	return []string{"tap-new", "--no-git", name}
+}
+
+// BuildExtractArgs builds the arguments for copying formula as it was at
+// version into tap. --force overwrites an earlier extraction of the same
+// version.
+func BuildExtractArgs(formula, version, tap string) []string {
+	return []string{"extract", "--force", "--version=" + version, formula, tap}
+}
+
 // BuildTapArgs builds the arguments for tapping a repository. The URL is
 // optional and only appended when non-empty.
 func BuildTapArgs(name, url string) []string {
@@ -78,6 +128,33 @@ func BuildTrustArgs(name string) []string {
 	return []string{"trust", name}
 }
 
+// BuildBundleCheckArgs builds the arguments for listing every Brewfile entry
+// that is not satisfied yet. --verbose keeps brew from stopping at the first
+// one.
+func BuildBundleCheckArgs(path string) []string {
+	return []string{"bundle", "check", "--verbose", "--file=" + path}
+}
+
+// BuildBundleInstallArgs builds the arguments for applying a Brewfile.
+func BuildBundleInstallArgs(path string) []string {
+	return []string{"bundle", "install", "--file=" + path}
+}
+
+// BuildBundleCleanupArgs builds the arguments for listing what a Brewfile does
+// not mention. Without --force brew bundle cleanup changes nothing.
+func BuildBundleCleanupArgs(path string) []string {
+	return []string{"bundle", "cleanup", "--file=" + path}
+}
+
+// BuildDryRunArgs turns the arguments of a mutating command into its
+// --dry-run preview, so a preview runs exactly what the action would.
+func BuildDryRunArgs(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	return append([]string{args[0], "--dry-run"}, args[1:]...)
+}
+
 // greedyFlags maps the configured outdated detection mode to the matching
 // brew upgrade flag. Standard mode adds nothing.
 func greedyFlags(outdatedFlag string) []string {
@@ -57,6 +57,29 @@ func TestBuildUpgradeArgs(t *testing.T) {
 	}
 }
 
+func TestBuildLinkArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		overwrite bool
+		force     bool
+		expected  []string
+	}{
+		{"plain", false, false, []string{"link", "python@3.12"}},
+		{"overwrite", true, false, []string{"link", "--overwrite", "python@3.12"}},
+		{"keg-only", false, true, []string{"link", "--force", "python@3.12"}},
+		{"both", true, true, []string{"link", "--overwrite", "--force", "python@3.12"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildLinkArgs("python@3.12", tt.overwrite, tt.force)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("BuildLinkArgs() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestBuildUpgradeSelectedArgs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -100,6 +123,28 @@ func TestBuildUpgradeAllArgs(t *testing.T) {
 	}
 }
 
+func TestBuildDryRunArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"upgrade all", BuildUpgradeAllArgs(OutdatedFlagGreedy), []string{"upgrade", "--dry-run", "--greedy"}},
+		{"upgrade selected", BuildUpgradeSelectedArgs([]string{"wget", "jq"}), []string{"upgrade", "--dry-run", "wget", "jq"}},
+		{"install", BuildInstallArgs("wget"), []string{"install", "--dry-run", "wget"}},
+		{"empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildDryRunArgs(tt.args)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("BuildDryRunArgs() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestBuildTapArgs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -133,6 +178,19 @@ func TestBuildUntapAndTrustArgs(t *testing.T) {
 	if got, want := BuildInstallArgs("wget"), []string{"install", "wget"}; !reflect.DeepEqual(got, want) {
 		t.Errorf("BuildInstallArgs() = %v, want %v", got, want)
 	}
+	if got, want := BuildPinArgs("wget"), []string{"pin", "wget"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildPinArgs() = %v, want %v", got, want)
+	}
+	if got, want := BuildUnpinArgs("wget"), []string{"unpin", "wget"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildUnpinArgs() = %v, want %v", got, want)
+	}
+	if got, want := BuildTapNewArgs("me/local"), []string{"tap-new", "--no-git", "me/local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildTapNewArgs() = %v, want %v", got, want)
+	}
+	got, want := BuildExtractArgs("node", "20.11.1", "me/local"), []string{"extract", "--force", "--version=20.11.1", "node", "me/local"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildExtractArgs() = %v, want %v", got, want)
+	}
 }
 
 func TestFormatCommand(t *testing.T) {
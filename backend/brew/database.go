@@ -9,7 +9,7 @@ import (
 
 // DatabaseService provides database update and new package detection functionality
 type DatabaseService struct {
-	executor         *Executor
+	executor         Runner
 	knownPackages    map[string]bool
 	knownPackagesMux sync.Mutex
 	updateMutex      sync.Mutex
@@ -17,7 +17,7 @@ type DatabaseService struct {
 }
 
 // NewDatabaseService creates a new database service
-func NewDatabaseService(executor *Executor) *DatabaseService {
+func NewDatabaseService(executor Runner) *DatabaseService {
 	return &DatabaseService{
 		executor:      executor,
 		knownPackages: make(map[string]bool),
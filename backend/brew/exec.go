@@ -22,9 +22,13 @@ type cacheEntry struct {
 // Executor handles brew command execution with result caching
 type Executor struct {
 	brewPath    string
-	brewEnv     []string
 	logCallback func(string)
 
+	// Environment applied to every brew invocation; replaced by SetEnvironment
+	// when a setting that feeds it (proxy, mirror, cask options) changes
+	brewEnv []string
+	envMux  sync.RWMutex
+
 	// Command result cache (short-lived to deduplicate parallel calls)
 	cache    map[string]*cacheEntry
 	cacheMux sync.RWMutex
@@ -46,6 +50,32 @@ func NewExecutor(brewPath string, brewEnv []string, logCallback func(string)) *E
 	}
 }
 
+// SetEnvironment replaces the environment used for subsequent brew commands.
+// Commands already running keep the environment they were started with.
+func (e *Executor) SetEnvironment(brewEnv []string) {
+	e.envMux.Lock()
+	defer e.envMux.Unlock()
+	e.brewEnv = brewEnv
+}
+
+func (e *Executor) environment() []string {
+	e.envMux.RLock()
+	defer e.envMux.RUnlock()
+	return e.brewEnv
+}
+
+// Getenv reports the value of key in the environment brew runs with: the
+// last assignment in the configured environment, else the app's own.
+func (e *Executor) Getenv(key string) string {
+	env := e.environment()
+	for i := len(env) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(env[i], key+"="); ok {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
 // ClearCache clears the command result cache
 func (e *Executor) ClearCache() {
 	e.cacheMux.Lock()
@@ -86,6 +116,49 @@ func (e *Executor) RunNoCacheStdoutOnly(args ...string) ([]byte, error) {
 	return e.runActual(30*time.Second, true, args...)
 }
 
+// RunNoCacheWithTimeout executes a brew command without cache and with a custom
+// timeout. Use this for state-changing commands that can outlast the default.
+func (e *Executor) RunNoCacheWithTimeout(timeout time.Duration, args ...string) ([]byte, error) {
+	return e.runActual(timeout, false, args...)
+}
+
+// Stream executes a long-running brew command, forwarding its output line by
+// line. It never uses the cache; see runStreamingCommand for how the returned
+// phase and error are reported.
+func (e *Executor) Stream(ctx context.Context, args []string, onStdout, onStderr func(line string)) (streamPhase, string, error) {
+	cmdStr := fmt.Sprintf("brew %s", joinArgs(args))
+	if e.logCallback != nil {
+		go e.logCallback(fmt.Sprintf("Executing: %s", cmdStr))
+	}
+
+	phase, stderrText, err := runStreamingCommand(ctx, e.brewPath, args, e.environment(), onStdout, onStderr)
+
+	if e.logCallback != nil {
+		switch phase {
+		case phaseNone:
+			go e.logCallback(fmt.Sprintf("SUCCESS: %s completed", cmdStr))
+		case phaseCancelled:
+			go e.logCallback(fmt.Sprintf("CANCELLED: %s", cmdStr))
+		default:
+			go e.logCallback(fmt.Sprintf("ERROR: %s failed: %v", cmdStr, err))
+		}
+	}
+
+	return phase, stderrText, err
+}
+
+// RunTool executes a helper program other than brew (for example git against
+// the Homebrew repository) with brew's environment and returns its combined
+// output. Tool invocations are never cached.
+func (e *Executor) RunTool(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	system.ApplyEnvironment(cmd, e.environment())
+	return cmd.CombinedOutput()
+}
+
 func (e *Executor) runWithTimeout(timeout time.Duration, stdoutOnly bool, args ...string) ([]byte, error) {
 	cacheKey := strings.Join(args, "\x00") // Use null byte separator for unique key
 	if stdoutOnly {
@@ -134,7 +207,7 @@ func (e *Executor) runActual(timeout time.Duration, stdoutOnly bool, args ...str
 	}
 
 	cmd := exec.CommandContext(ctx, e.brewPath, args...)
-	system.ApplyEnvironment(cmd, e.brewEnv)
+	system.ApplyEnvironment(cmd, e.environment())
 
 	var output []byte
 	var err error
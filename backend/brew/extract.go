@@ -0,0 +1,106 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LocalTapName is the tap WailBrew creates to hold formula versions it
+// extracts. It only exists on this machine.
+const LocalTapName = "wailbrew/local"
+
+var formulaVersionPattern = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._+-]*$`)
+
+// VersionedFormulaName is the name formula installs under once version has
+// been extracted into the local tap, e.g. wailbrew/local/node@20.11.1.
+func VersionedFormulaName(formula, version string) string {
+	short := formula[strings.LastIndex(formula, "/")+1:]
+	return LocalTapName + "/" + short + "@" + version
+}
+
+// InstallBrewPackageVersion installs formula as it was at version: it creates
+// the local tap on first use, copies the old formula into it with
+// `brew extract`, installs that copy and optionally pins it so upgrades leave
+// it alone. Every step streams through "packageInstallProgress" and the
+// result is announced with "packageInstallComplete", like a regular install.
+func (s *ActionsService) InstallBrewPackageVersion(ctx context.Context, formula, version string, pin bool) string {
+	result, _ := s.installPackageVersion(ctx, formula, version, pin)
+	return result
+}
+
+// installPackageVersion is InstallBrewPackageVersion also reporting whether
+// it succeeded.
+func (s *ActionsService) installPackageVersion(ctx context.Context, formula, version string, pin bool) (string, bool) {
+	params := map[string]string{"name": formula, "version": version}
+	finish := func(message string, ok bool) (string, bool) {
+		s.eventEmitter.Emit("packageInstallProgress", message)
+		s.eventEmitter.Emit("packageInstallComplete", message)
+		return message, ok
+	}
+
+	if formula == "" || strings.ContainsAny(formula, " \t@") || !formulaVersionPattern.MatchString(version) {
+		return finish(s.getBackendMsg("backend.installVersion.invalid", params), false)
+	}
+	target := VersionedFormulaName(formula, version)
+	s.eventEmitter.Emit("packageInstallProgress", s.getBackendMsg("backend.installVersion.start", params))
+
+	tapped, err := s.hasTap(LocalTapName)
+	if err != nil {
+		params["error"] = err.Error()
+		return finish(s.getBackendMsg("backend.installVersion.failed", params), false)
+	}
+	steps := [][]string{}
+	if !tapped {
+		steps = append(steps, BuildTapNewArgs(LocalTapName))
+	}
+	steps = append(steps, BuildExtractArgs(formula, version, LocalTapName), BuildInstallArgs(target))
+
+	for _, args := range steps {
+		s.eventEmitter.Emit("packageInstallProgress", fmt.Sprintf("▶️ %s", FormatCommand(args)))
+		if message, ok := s.streamInstallStep(ctx, args, params); !ok {
+			return finish(message, false)
+		}
+	}
+
+	if pin {
+		result, ok := s.setPinned(ctx, target, BuildPinArgs(target), "backend.pin")
+		if !ok {
+			return finish(result, false)
+		}
+		s.eventEmitter.Emit("packageInstallProgress", result)
+	}
+	return finish(s.getBackendMsg("backend.installVersion.success", map[string]string{"name": target}), true)
+}
+
+// streamInstallStep runs one step of a versioned install. On failure it
+// returns the message to finish with.
+func (s *ActionsService) streamInstallStep(ctx context.Context, args []string, params map[string]string) (string, bool) {
+	phase, _, err := s.runner.Stream(ctx, args,
+		func(line string) { s.eventEmitter.Emit("packageInstallProgress", fmt.Sprintf("📦 %s", line)) },
+		func(line string) { s.eventEmitter.Emit("packageInstallProgress", fmt.Sprintf("⚠️ %s", line)) },
+	)
+	switch phase {
+	case phaseNone:
+		return "", true
+	case phaseCancelled:
+		return s.getBackendMsg("backend.operation.cancelled", map[string]string{}), false
+	}
+	failure := map[string]string{"name": params["name"], "version": params["version"], "error": err.Error()}
+	return s.getBackendMsg("backend.installVersion.failed", failure), false
+}
+
+// hasTap reports whether name is tapped.
+func (s *ActionsService) hasTap(name string) (bool, error) {
+	output, err := s.runner.RunNoCacheStdoutOnly("tap")
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,117 @@
+package brew
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestVersionedFormulaName(t *testing.T) {
+	if got := VersionedFormulaName("node", "20.11.1"); got != "wailbrew/local/node@20.11.1" {
+		t.Errorf("VersionedFormulaName(node) = %q", got)
+	}
+	if got := VersionedFormulaName("acme/tools/widget", "0.2.0"); got != "wailbrew/local/widget@0.2.0" {
+		t.Errorf("VersionedFormulaName(acme/tools/widget) = %q", got)
+	}
+}
+
+func TestInstallBrewPackageVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		taps      string
+		pin       bool
+		scripts   map[string]brewRecording
+		want      string
+		wantCalls []string
+	}{
+		{
+			name: "creates the local tap on first use and pins",
+			taps: "homebrew/services\n",
+			pin:  true,
+			want: "backend.installVersion.success",
+			wantCalls: []string{
+				"tap",
+				"tap-new --no-git wailbrew/local",
+				"extract --force --version=20.11.1 node wailbrew/local",
+				"install wailbrew/local/node@20.11.1",
+				"pin wailbrew/local/node@20.11.1",
+			},
+		},
+		{
+			name: "reuses the local tap",
+			taps: "homebrew/services\nwailbrew/local\n",
+			want: "backend.installVersion.success",
+			wantCalls: []string{
+				"tap",
+				"extract --force --version=20.11.1 node wailbrew/local",
+				"install wailbrew/local/node@20.11.1",
+			},
+		},
+		{
+			name: "stops when the version cannot be extracted",
+			taps: "wailbrew/local\n",
+			scripts: map[string]brewRecording{
+				"extract --force --version=20.11.1 node wailbrew/local": {
+					stderr: "Error: Could not find node! The formula or version may not have existed.", exitCode: 1,
+				},
+			},
+			want:      "backend.installVersion.failed",
+			wantCalls: []string{"tap", "extract --force --version=20.11.1 node wailbrew/local"},
+		},
+		{
+			name: "reports a failed pin",
+			taps: "wailbrew/local\n",
+			pin:  true,
+			scripts: map[string]brewRecording{
+				"pin wailbrew/local/node@20.11.1": {stderr: "Error: No such keg", exitCode: 1},
+			},
+			want: "backend.pin.failed",
+			wantCalls: []string{
+				"tap",
+				"extract --force --version=20.11.1 node wailbrew/local",
+				"install wailbrew/local/node@20.11.1",
+				"pin wailbrew/local/node@20.11.1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().
+				script("tap", brewRecording{stdout: tt.taps}).
+				script("tap-new --no-git wailbrew/local", brewRecording{}).
+				script("extract --force --version=20.11.1 node wailbrew/local", brewRecording{}).
+				script("install wailbrew/local/node@20.11.1", brewRecording{}).
+				script("pin wailbrew/local/node@20.11.1", brewRecording{})
+			for args, rec := range tt.scripts {
+				fb.script(args, rec)
+			}
+			service, emitter := newFakeService(fb)
+
+			got := service.InstallBrewPackageVersion(context.Background(), "node", "20.11.1", tt.pin)
+			if got != tt.want {
+				t.Fatalf("result = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(fb.calls, tt.wantCalls) {
+				t.Fatalf("calls = %v, want %v", fb.calls, tt.wantCalls)
+			}
+			if emitter.count("packageInstallComplete") != 1 || emitter.last("packageInstallComplete") != tt.want {
+				t.Fatal("expected exactly one packageInstallComplete with the result")
+			}
+		})
+	}
+}
+
+func TestInstallBrewPackageVersion_rejectsInvalidInput(t *testing.T) {
+	for _, input := range [][2]string{{"node", ""}, {"node", "20 --HEAD"}, {"node@20", "20.1"}, {"", "1.0"}} {
+		fb := newFakeBrew()
+		service, _ := newFakeService(fb)
+
+		if got := service.InstallBrewPackageVersion(context.Background(), input[0], input[1], false); got != "backend.installVersion.invalid" {
+			t.Errorf("InstallBrewPackageVersion(%q, %q) = %q", input[0], input[1], got)
+		}
+		if len(fb.calls) != 0 {
+			t.Errorf("InstallBrewPackageVersion(%q, %q) ran %v", input[0], input[1], fb.calls)
+		}
+	}
+}
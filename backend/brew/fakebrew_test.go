@@ -0,0 +1,244 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// brewRecording is one captured brew invocation: what it printed and how it
+// exited.
+type brewRecording struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// fakeBrew is a scriptable stand-in for Homebrew that implements Runner, so
+// the whole Service can be exercised without a real installation. Scripts are
+// keyed by the space-joined argument list (tools such as git are keyed with
+// the program name in front). Repeated invocations consume a script's
+// recordings in order and then keep replaying the last one, which is how a
+// test expresses "fails, then succeeds on retry". Unscripted invocations exit
+// 1 with an empty output. Every invocation is recorded for assertions.
+type fakeBrew struct {
+	mu      sync.Mutex
+	scripts map[string][]brewRecording
+	calls   []string
+	cleared int               // ClearCache calls
+	env     map[string]string // Getenv answers
+}
+
+func newFakeBrew() *fakeBrew {
+	return &fakeBrew{scripts: make(map[string][]brewRecording)}
+}
+
+// script registers the recordings replayed for an invocation.
+func (f *fakeBrew) script(args string, recordings ...brewRecording) *fakeBrew {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts[args] = recordings
+	return f
+}
+
+// invoked returns how many times the given invocation was made.
+func (f *fakeBrew) invoked(args string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, call := range f.calls {
+		if call == args {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeBrew) replay(args ...string) brewRecording {
+	key := strings.Join(args, " ")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, key)
+
+	recordings, ok := f.scripts[key]
+	if !ok || len(recordings) == 0 {
+		return brewRecording{exitCode: 1}
+	}
+	if len(recordings) > 1 {
+		f.scripts[key] = recordings[1:]
+	}
+	return recordings[0]
+}
+
+// exitError mirrors how *Executor reports a failed command: the exit status
+// with brew's diagnostics attached.
+func (r brewRecording) exitError(detail string) error {
+	if r.exitCode == 0 {
+		return nil
+	}
+	if detail = strings.TrimSpace(detail); detail != "" {
+		return fmt.Errorf("exit status %d: %s", r.exitCode, detail)
+	}
+	return fmt.Errorf("exit status %d", r.exitCode)
+}
+
+func (f *fakeBrew) buffered(stdoutOnly bool, args ...string) ([]byte, error) {
+	rec := f.replay(args...)
+	if stdoutOnly {
+		return []byte(rec.stdout), rec.exitError(rec.stderr)
+	}
+	combined := rec.stderr + rec.stdout
+	return []byte(combined), rec.exitError(combined)
+}
+
+func (f *fakeBrew) Run(args ...string) ([]byte, error) { return f.buffered(false, args...) }
+
+func (f *fakeBrew) RunWithTimeout(_ time.Duration, args ...string) ([]byte, error) {
+	return f.buffered(false, args...)
+}
+
+func (f *fakeBrew) RunStdoutOnly(args ...string) ([]byte, error) { return f.buffered(true, args...) }
+
+func (f *fakeBrew) RunWithTimeoutStdoutOnly(_ time.Duration, args ...string) ([]byte, error) {
+	return f.buffered(true, args...)
+}
+
+func (f *fakeBrew) RunNoCache(args ...string) ([]byte, error) { return f.buffered(false, args...) }
+
+func (f *fakeBrew) RunNoCacheStdoutOnly(args ...string) ([]byte, error) {
+	return f.buffered(true, args...)
+}
+
+func (f *fakeBrew) RunNoCacheWithTimeout(_ time.Duration, args ...string) ([]byte, error) {
+	return f.buffered(false, args...)
+}
+
+func (f *fakeBrew) RunTool(name string, args ...string) ([]byte, error) {
+	return f.buffered(false, append([]string{name}, args...)...)
+}
+
+// Stream replays stdout then stderr line by line, trimmed and without blank
+// lines, the same way runStreamingCommand delivers them.
+func (f *fakeBrew) Stream(ctx context.Context, args []string, onStdout, onStderr func(line string)) (streamPhase, string, error) {
+	if err := ctx.Err(); err != nil {
+		return phaseCancelled, "", err
+	}
+
+	rec := f.replay(args...)
+	for _, line := range strings.Split(rec.stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" && onStdout != nil {
+			onStdout(line)
+		}
+	}
+
+	var stderrText strings.Builder
+	for _, line := range strings.Split(rec.stderr, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		stderrText.WriteString(line)
+		stderrText.WriteString("\n")
+		if onStderr != nil {
+			onStderr(line)
+		}
+	}
+
+	if rec.exitCode != 0 {
+		return phaseRun, stderrText.String(), fmt.Errorf("exit status %d", rec.exitCode)
+	}
+	return phaseNone, stderrText.String(), nil
+}
+
+func (f *fakeBrew) Getenv(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.env[key]
+}
+
+func (f *fakeBrew) ClearCache() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cleared++
+}
+
+var _ Runner = (*fakeBrew)(nil)
+
+// newFakeService wires a full Service to fb. Backend messages render as their
+// key so tests can assert on which message was chosen.
+func newFakeService(fb *fakeBrew) (Service, *recordingEmitter) {
+	return newFakeServiceWithPolicies(fb, nil)
+}
+
+// newFakeServiceWithPolicies is newFakeService with upgrade policies
+// configured.
+func newFakeServiceWithPolicies(fb *fakeBrew, policies map[string]string) (Service, *recordingEmitter) {
+	return buildFakeService(fb, policies, nil)
+}
+
+// newFakeServiceWithJournal is newFakeService writing every queued
+// operation to journal.
+func newFakeServiceWithJournal(fb *fakeBrew, journal *OperationJournal) (Service, *recordingEmitter) {
+	return buildFakeService(fb, nil, journal)
+}
+
+func buildFakeService(fb *fakeBrew, policies map[string]string, journal *OperationJournal) (Service, *recordingEmitter) {
+	emitter := &recordingEmitter{}
+	service := NewService(
+		fb,
+		"/opt/homebrew/bin/brew",
+		func(string) {},
+		func() error { return nil },
+		func(key string, _ map[string]string) string { return key },
+		emitter,
+		func() string { return OutdatedFlagNone },
+		func() string { return "" },
+		func() map[string]string { return policies },
+		ExtractJSONFromOutput,
+		ParseWarnings,
+		func() bool { return false },
+		func() bool { return false },
+		NewOperationQueue(emitter, func(key string, _ map[string]string) string { return key }),
+		journal,
+	)
+	return service, emitter
+}
+
+// has reports whether event was emitted with exactly data.
+func (e *recordingEmitter) has(event, data string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, ev := range e.events {
+		if ev == event && e.data[i] == data {
+			return true
+		}
+	}
+	return false
+}
+
+// last returns the data of the most recent emission of event.
+func (e *recordingEmitter) last(event string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := len(e.events) - 1; i >= 0; i-- {
+		if e.events[i] == event {
+			return e.data[i]
+		}
+	}
+	return ""
+}
+
+// Recorded Homebrew output replayed by the action tests.
+const (
+	recordedCaskInfo = `{"formulae":[],"casks":[{"token":"firefox","version":"131.0"}]}`
+
+	recordedAppExists = "Error: It seems there is already an App at '/Applications/Firefox.app'."
+
+	recordedAppExistsMulti = "Error: Problems with multiple casks:\n" +
+		"firefox: It seems there is already an App at '/Applications/Firefox.app'."
+
+	recordedUntrustedTap = "Error: Refusing to load formula acme/tools/widget from untrusted tap acme/tools.\n" +
+		"Run `brew trust acme/tools` to trust it."
+)
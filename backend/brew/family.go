@@ -0,0 +1,258 @@
+package brew
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FormulaFamily is a formula shipped in several versions side by side, such
+// as node, node@18 and node@20. At most one member is linked into the prefix
+// at a time, and that is the one on the PATH.
+type FormulaFamily struct {
+	Name    string         `json:"name"`
+	Members []FamilyMember `json:"members"`
+	// Linked is the linked member, or empty when none is.
+	Linked string `json:"linked"`
+}
+
+// FamilyMember is one formula of a FormulaFamily. Members that are not
+// installed come from the formula catalogue and are installed on switching.
+type FamilyMember struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	Installed      bool   `json:"installed"`
+	Linked         bool   `json:"linked"`
+	KegOnly        bool   `json:"kegOnly"`
+	ServiceRunning bool   `json:"serviceRunning"`
+}
+
+// familyOf is the family a formula belongs to: its name without the tap and
+// the @version suffix, e.g. postgresql for homebrew/core/postgresql@15.
+func familyOf(name string) string {
+	short := name[strings.LastIndex(name, "/")+1:]
+	family, _, _ := strings.Cut(short, "@")
+	return family
+}
+
+// GetFormulaFamilies groups the installed formulae with their versioned
+// siblings. Only families with an installed member and at least one
+// name@version formula are reported, sorted by name.
+func (s *serviceImpl) GetFormulaFamilies() ([]FormulaFamily, error) {
+	inventory, err := s.listService.LoadInventory()
+	if err != nil {
+		return nil, err
+	}
+	members := map[string]map[string]*FamilyMember{}
+	add := func(member FamilyMember) {
+		family := familyOf(member.Name)
+		if members[family] == nil {
+			members[family] = map[string]*FamilyMember{}
+		}
+		if members[family][member.Name] == nil {
+			members[family][member.Name] = &member
+		}
+	}
+	for _, formula := range inventory.Formulae {
+		add(FamilyMember{
+			Name:      qualifiedName(formula.Name, formula.Tap),
+			Version:   formula.Version,
+			Installed: true,
+			Linked:    formula.Linked,
+			KegOnly:   formula.KegOnly,
+		})
+	}
+
+	// The catalogue only adds members to families that are installed; a
+	// failure to read it just leaves the uninstalled members out.
+	if output, err := s.runner.RunStdoutOnly("formulae"); err == nil {
+		for _, entry := range parseNameListOutput(output) {
+			if members[familyOf(entry[0])] != nil {
+				add(FamilyMember{Name: entry[0]})
+			}
+		}
+	}
+
+	if services, err := s.servicesService.Services(); err == nil {
+		for _, service := range services {
+			if member := members[familyOf(service.Name)][service.Name]; member != nil && service.Status == "started" {
+				member.ServiceRunning = true
+			}
+		}
+	}
+
+	families := []FormulaFamily{}
+	for name, byName := range members {
+		family := FormulaFamily{Name: name, Members: []FamilyMember{}}
+		versioned := false
+		for _, member := range byName {
+			family.Members = append(family.Members, *member)
+			versioned = versioned || strings.Contains(member.Name, "@")
+			if member.Linked {
+				family.Linked = member.Name
+			}
+		}
+		if !versioned || len(family.Members) < 2 {
+			continue
+		}
+		sort.Slice(family.Members, func(i, j int) bool { return family.Members[i].Name < family.Members[j].Name })
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	return families, nil
+}
+
+// SwitchFormulaVersion makes target the linked member of family: it unlinks
+// the members linked now, installs target if needed and links it. A running
+// service of a previously linked member is moved to target, and a running
+// service of target itself is restarted so it picks up the relink. When a
+// step fails after the unlink, the previously linked members are linked
+// again. Each step runs as the regular action with its own progress events;
+// the steps are announced on "formulaSwitchProgress" and the outcome on
+// "formulaSwitchComplete".
+func (s *serviceImpl) SwitchFormulaVersion(ctx context.Context, family, target string) string {
+	return s.mutate(ctx, "switch-version", target, func(ctx context.Context) (string, bool) {
+		return s.switchFormulaVersion(ctx, family, target)
+	})
+}
+
+func (s *serviceImpl) switchFormulaVersion(ctx context.Context, family, target string) (string, bool) {
+	params := map[string]string{"family": family, "name": target}
+	finish := func(message string, ok bool) (string, bool) {
+		s.eventEmitter.Emit("formulaSwitchProgress", message)
+		s.eventEmitter.Emit("formulaSwitchComplete", message)
+		return message, ok
+	}
+	fail := func(err string) (string, bool) {
+		return finish(s.getBackendMsg("backend.switchVersion.failed",
+			map[string]string{"family": family, "name": target, "error": err}), false)
+	}
+
+	if target == "" || familyOf(target) != family {
+		return finish(s.getBackendMsg("backend.switchVersion.invalid", params), false)
+	}
+	inventory := s.journalInventory()
+	if inventory == nil {
+		return fail("could not read the installed formulae")
+	}
+	s.eventEmitter.Emit("formulaSwitchProgress", s.getBackendMsg("backend.switchVersion.start", params))
+
+	var unlink []InstalledFormula
+	for _, formula := range inventory.Formulae {
+		name := qualifiedName(formula.Name, formula.Tap)
+		if formula.Linked && familyOf(name) == family && !samePackage(name, target) {
+			formula.Name = name
+			unlink = append(unlink, formula)
+		}
+	}
+	current := findFormula(inventory, target)
+	if len(unlink) == 0 && current != nil && current.Linked {
+		return finish(s.getBackendMsg("backend.switchVersion.alreadyLinked", params), true)
+	}
+
+	// Running services are read before anything changes, so the ones of the
+	// members being unlinked can be moved over to target afterwards.
+	restartTarget := false
+	var stopServices []string
+	if services, err := s.servicesService.Services(); err == nil {
+		for _, service := range services {
+			if service.Status != "started" || familyOf(service.Name) != family {
+				continue
+			}
+			switch {
+			case samePackage(service.Name, target):
+				restartTarget = true
+			case slices.ContainsFunc(unlink, func(f InstalledFormula) bool { return samePackage(f.Name, service.Name) }):
+				stopServices = append(stopServices, service.Name)
+			}
+		}
+	}
+
+	// Once anything is unlinked, a failing step puts the family back the way
+	// it was: target is unlinked again if it got linked, the previous members
+	// are relinked and their stopped services started.
+	var unlinked, stopped []string
+	targetLinked := false
+	rollBack := func(err string) (string, bool) {
+		if len(unlinked) == 0 {
+			return fail(err)
+		}
+		s.eventEmitter.Emit("formulaSwitchProgress", s.getBackendMsg("backend.switchVersion.restoring",
+			map[string]string{"names": strings.Join(unlinked, ", ")}))
+		if targetLinked {
+			s.actionsService.unlinkPackage(ctx, target)
+		}
+		for _, formula := range unlink {
+			if !slices.Contains(unlinked, formula.Name) {
+				continue
+			}
+			if result, ok := s.actionsService.linkPackage(ctx, formula.Name, false, formula.KegOnly); !ok {
+				s.eventEmitter.Emit("formulaSwitchProgress", s.getBackendMsg("backend.switchVersion.restoreFailed",
+					map[string]string{"name": formula.Name, "error": result}))
+			}
+		}
+		for _, name := range stopped {
+			s.servicesService.runServiceAction(ctx, "start", name)
+		}
+		return fail(err)
+	}
+
+	for _, formula := range unlink {
+		if result, ok := s.actionsService.unlinkPackage(ctx, formula.Name); !ok {
+			return rollBack(result)
+		}
+		unlinked = append(unlinked, formula.Name)
+	}
+
+	if current == nil {
+		if result, ok := s.actionsService.installPackage(ctx, target); !ok {
+			return rollBack(result)
+		}
+		// A formula that is not keg-only links itself on install.
+		if inventory = s.journalInventory(); inventory != nil {
+			current = findFormula(inventory, target)
+		}
+	}
+	if current == nil || !current.Linked {
+		kegOnly := current != nil && current.KegOnly
+		if result, ok := s.actionsService.linkPackage(ctx, target, false, kegOnly); !ok {
+			return rollBack(result)
+		}
+	}
+	targetLinked = true
+
+	for _, name := range stopServices {
+		if result, ok := s.servicesService.runServiceAction(ctx, "stop", name); !ok {
+			return rollBack(result)
+		}
+		stopped = append(stopped, name)
+	}
+	switch {
+	case restartTarget:
+		if result, ok := s.servicesService.runServiceAction(ctx, "restart", target); !ok {
+			return rollBack(result)
+		}
+	case len(stopServices) > 0 && !s.hasService(target):
+		s.eventEmitter.Emit("formulaSwitchProgress", s.getBackendMsg("backend.switchVersion.noService", params))
+	case len(stopServices) > 0:
+		if result, ok := s.servicesService.runServiceAction(ctx, "start", target); !ok {
+			return rollBack(result)
+		}
+	}
+	return finish(s.getBackendMsg("backend.switchVersion.success", params), true)
+}
+
+// hasService reports whether name is a formula that defines a service.
+func (s *serviceImpl) hasService(name string) bool {
+	services, err := s.servicesService.Services()
+	if err != nil {
+		return false
+	}
+	for _, service := range services {
+		if samePackage(service.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package brew
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGetFormulaFamilies(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: `{"formulae": [
+			{"name": "node", "full_name": "node", "tap": "homebrew/core", "linked_keg": "22.9.0",
+				"installed": [{"version": "22.9.0", "installed_on_request": true}]},
+			{"name": "node@18", "full_name": "node@18", "tap": "homebrew/core", "keg_only": true,
+				"installed": [{"version": "18.20.4", "installed_on_request": true}]},
+			{"name": "wget", "full_name": "wget", "tap": "homebrew/core", "linked_keg": "1.24.5",
+				"installed": [{"version": "1.24.5", "installed_on_request": true}]}
+		], "casks": []}`}).
+		script("formulae", brewRecording{stdout: "node\nnode@18\nnode@20\npostgresql@15\nwget\n"}).
+		script("services list --json", brewRecording{stdout: `[{"name": "node@18", "status": "started"}]`})
+	service, _ := newFakeService(fb)
+
+	families, err := service.GetFormulaFamilies()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []FormulaFamily{{
+		Name: "node",
+		Members: []FamilyMember{
+			{Name: "node", Version: "22.9.0", Installed: true, Linked: true},
+			{Name: "node@18", Version: "18.20.4", Installed: true, KegOnly: true, ServiceRunning: true},
+			{Name: "node@20"},
+		},
+		Linked: "node",
+	}}
+	if !reflect.DeepEqual(families, want) {
+		t.Errorf("families = %+v, want %+v", families, want)
+	}
+}
+
+func TestSwitchFormulaVersion(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed",
+			brewRecording{stdout: `{"formulae": [{"name": "postgresql@15", "full_name": "postgresql@15",
+				"tap": "homebrew/core", "keg_only": true, "linked_keg": "15.8",
+				"installed": [{"version": "15.8", "installed_on_request": true}]}], "casks": []}`},
+			brewRecording{stdout: `{"formulae": [{"name": "postgresql@16", "full_name": "postgresql@16",
+				"tap": "homebrew/core", "keg_only": true,
+				"installed": [{"version": "16.4", "installed_on_request": true}]}], "casks": []}`}).
+		script("services list --json",
+			brewRecording{stdout: `[{"name": "postgresql@15", "status": "started"}]`},
+			brewRecording{stdout: `[{"name": "postgresql@15", "status": "none"}, {"name": "postgresql@16", "status": "none"}]`}).
+		script("unlink postgresql@15", brewRecording{}).
+		script("install postgresql@16", brewRecording{}).
+		script("link --force postgresql@16", brewRecording{}).
+		script("services stop postgresql@15", brewRecording{}).
+		script("services start postgresql@16", brewRecording{})
+	service, emitter := newFakeService(fb)
+
+	if got := service.SwitchFormulaVersion(context.Background(), "postgresql", "postgresql@16"); got != "backend.switchVersion.success" {
+		t.Fatalf("SwitchFormulaVersion = %q", got)
+	}
+	for _, call := range []string{"unlink postgresql@15", "install postgresql@16", "link --force postgresql@16",
+		"services stop postgresql@15", "services start postgresql@16"} {
+		if fb.invoked(call) != 1 {
+			t.Errorf("expected %q to run once", call)
+		}
+	}
+	if emitter.last("formulaSwitchComplete") != "backend.switchVersion.success" {
+		t.Error("expected the outcome on formulaSwitchComplete")
+	}
+
+	if got := service.SwitchFormulaVersion(context.Background(), "postgresql", "node@20"); got != "backend.switchVersion.invalid" {
+		t.Errorf("switching to another family = %q, want invalid", got)
+	}
+}
+
+func TestSwitchFormulaVersionRestoresOnFailure(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed",
+			brewRecording{stdout: `{"formulae": [{"name": "postgresql@15", "full_name": "postgresql@15",
+				"tap": "homebrew/core", "keg_only": true, "linked_keg": "15.8",
+				"installed": [{"version": "15.8", "installed_on_request": true}]}], "casks": []}`}).
+		script("services list --json", brewRecording{stdout: `[{"name": "postgresql@15", "status": "started"}]`}).
+		script("unlink postgresql@15", brewRecording{}).
+		script("install postgresql@16", brewRecording{stderr: "Error: No available formula", exitCode: 1}).
+		script("link --force postgresql@15", brewRecording{})
+	service, emitter := newFakeService(fb)
+
+	if got := service.SwitchFormulaVersion(context.Background(), "postgresql", "postgresql@16"); got != "backend.switchVersion.failed" {
+		t.Fatalf("SwitchFormulaVersion = %q", got)
+	}
+	if fb.invoked("link --force postgresql@15") != 1 || !emitter.has("formulaSwitchProgress", "backend.switchVersion.restoring") {
+		t.Fatalf("expected postgresql@15 to be relinked, calls: %v", fb.calls)
+	}
+	if fb.invoked("services stop postgresql@15") != 0 {
+		t.Error("the running service must be left alone when the switch fails before it")
+	}
+}
@@ -0,0 +1,373 @@
+package brew
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DependencyGraph is the dependency graph of everything installed, built from
+// the same `brew info --json=v2 --installed` snapshot as the Inventory. It
+// answers leaves, dependents and dependency trees without spawning brew.
+//
+// Nodes are keyed by full name (e.g. "acme/tools/widget") and can be looked
+// up by either full or short name; results use short names, matching the
+// installed lists.
+type DependencyGraph struct {
+	nodes   map[string]*graphNode
+	aliases map[string]string // short name -> full name
+}
+
+type graphNode struct {
+	name      string
+	fullName  string
+	cask      bool
+	onRequest bool
+	runtime   []string // full names of direct runtime dependencies
+	build     []string // full names of installed build dependencies
+	users     []string // full names of installed packages with a direct runtime dependency on this one
+
+	// Casks that update themselves or are versioned :latest are only
+	// upgraded with a greedy flag.
+	autoUpdates bool
+	latest      bool
+}
+
+// DependencyNode is one package in a dependency tree.
+type DependencyNode struct {
+	Name         string            `json:"name"`
+	Dependencies []*DependencyNode `json:"dependencies"`
+}
+
+// newDependencyGraph builds the graph from decoded inventory JSON. Only edges
+// between installed packages are kept.
+func newDependencyGraph(info *inventoryJSON) *DependencyGraph {
+	g := &DependencyGraph{
+		nodes:   make(map[string]*graphNode),
+		aliases: make(map[string]string),
+	}
+
+	for _, f := range info.Formulae {
+		fullName := f.FullName
+		if fullName == "" {
+			fullName = f.Name
+		}
+		node := &graphNode{name: f.Name, fullName: fullName}
+		if len(f.Installed) > 0 {
+			node.onRequest = f.Installed[0].InstalledOnRequest
+		}
+		g.add(node)
+	}
+	for _, c := range info.Casks {
+		fullName := c.FullToken
+		if fullName == "" {
+			fullName = c.Token
+		}
+		g.add(&graphNode{
+			name:        c.Token,
+			fullName:    fullName,
+			cask:        true,
+			onRequest:   true,
+			autoUpdates: c.AutoUpdates,
+			latest:      c.Version == "latest",
+		})
+	}
+
+	for _, f := range info.Formulae {
+		node := g.lookup(f.FullName, f.Name)
+		node.runtime = g.resolveAll(directRuntimeDependencies(f.Dependencies, f.Installed))
+		node.build = g.resolveAll(f.BuildDependencies)
+	}
+	for _, c := range info.Casks {
+		node := g.lookup(c.FullToken, c.Token)
+		node.runtime = g.resolveAll(append(append([]string{}, c.DependsOn.Formula...), c.DependsOn.Cask...))
+	}
+
+	for _, node := range g.nodes {
+		for _, dep := range node.runtime {
+			g.nodes[dep].users = append(g.nodes[dep].users, node.fullName)
+		}
+	}
+
+	return g
+}
+
+// directRuntimeDependencies picks the direct runtime dependencies out of an
+// install receipt. Receipts list the whole runtime closure and flag direct
+// entries with declared_directly; receipts written before that flag existed
+// fall back to the formula's declared dependencies, and to the whole closure
+// when those are missing too.
+func directRuntimeDependencies(declared []string, installed []inventoryKeg) []string {
+	if len(installed) == 0 {
+		return nil
+	}
+
+	var direct, closure []string
+	flagged := false
+	for _, dep := range installed[0].RuntimeDependencies {
+		closure = append(closure, dep.FullName)
+		if dep.DeclaredDirectly == nil {
+			continue
+		}
+		flagged = true
+		if *dep.DeclaredDirectly {
+			direct = append(direct, dep.FullName)
+		}
+	}
+	switch {
+	case flagged:
+		return direct
+	case len(declared) > 0:
+		return declared
+	default:
+		return closure
+	}
+}
+
+func (g *DependencyGraph) add(node *graphNode) {
+	g.nodes[node.fullName] = node
+	g.aliases[node.name] = node.fullName
+}
+
+func (g *DependencyGraph) lookup(fullName, name string) *graphNode {
+	if node, ok := g.nodes[fullName]; ok {
+		return node
+	}
+	return g.find(name)
+}
+
+// find resolves a full or short name to its node, or nil when the package is
+// not installed.
+func (g *DependencyGraph) find(name string) *graphNode {
+	if node, ok := g.nodes[name]; ok {
+		return node
+	}
+	if fullName, ok := g.aliases[name]; ok {
+		return g.nodes[fullName]
+	}
+	// Packages from the official taps have their short name as full name,
+	// but can still be asked for qualified, e.g. homebrew/core/openssl@3.
+	for _, tap := range []string{"homebrew/core/", "homebrew/cask/"} {
+		if short, ok := strings.CutPrefix(name, tap); ok && !strings.Contains(short, "/") {
+			return g.find(short)
+		}
+	}
+	return nil
+}
+
+// resolve maps a full or short name to the full name, leaving names that are
+// not installed as they are.
+func (g *DependencyGraph) resolve(name string) string {
+	if node := g.find(name); node != nil {
+		return node.fullName
+	}
+	return name
+}
+
+// resolveAll maps names to full names, dropping packages that are not
+// installed and duplicates.
+func (g *DependencyGraph) resolveAll(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	resolved := make([]string, 0, len(names))
+	for _, name := range names {
+		node := g.find(name)
+		if node == nil || seen[node.fullName] {
+			continue
+		}
+		seen[node.fullName] = true
+		resolved = append(resolved, node.fullName)
+	}
+	sort.Strings(resolved)
+	return resolved
+}
+
+// names renders full names as sorted short names.
+func (g *DependencyGraph) names(fullNames []string) []string {
+	result := make([]string, 0, len(fullNames))
+	for _, fullName := range fullNames {
+		result = append(result, g.nodes[fullName].name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// walk collects every node reachable from start through next, excluding start.
+func (g *DependencyGraph) walk(start *graphNode, next func(*graphNode) []string) []string {
+	seen := map[string]bool{start.fullName: true}
+	queue := append([]string{}, next(start)...)
+	var reached []string
+	for len(queue) > 0 {
+		fullName := queue[0]
+		queue = queue[1:]
+		if seen[fullName] {
+			continue
+		}
+		seen[fullName] = true
+		reached = append(reached, fullName)
+		queue = append(queue, next(g.nodes[fullName])...)
+	}
+	return reached
+}
+
+// Has reports whether name is installed.
+func (g *DependencyGraph) Has(name string) bool {
+	return g.find(name) != nil
+}
+
+// Leaves returns the full names of installed formulae that no other installed
+// formula depends on at runtime, matching `brew leaves`.
+func (g *DependencyGraph) Leaves() []string {
+	leaves := []string{}
+	for _, node := range g.nodes {
+		if node.cask {
+			continue
+		}
+		usedByFormula := false
+		for _, user := range node.users {
+			if !g.nodes[user].cask {
+				usedByFormula = true
+				break
+			}
+		}
+		if !usedByFormula {
+			leaves = append(leaves, node.fullName)
+		}
+	}
+	sort.Strings(leaves)
+	return leaves
+}
+
+// Dependencies returns the installed runtime dependencies of name, direct
+// only or the full closure. includeBuild adds installed build dependencies
+// at every level.
+func (g *DependencyGraph) Dependencies(name string, transitive, includeBuild bool) []string {
+	node := g.find(name)
+	if node == nil {
+		return []string{}
+	}
+	next := func(n *graphNode) []string {
+		if includeBuild {
+			return append(append([]string{}, n.runtime...), n.build...)
+		}
+		return n.runtime
+	}
+	if !transitive {
+		return g.names(g.resolveAll(next(node)))
+	}
+	return g.names(g.walk(node, next))
+}
+
+// Dependents returns the installed formulae and casks that depend on name at
+// runtime, direct only or the full reverse closure.
+func (g *DependencyGraph) Dependents(name string, transitive bool) []string {
+	node := g.find(name)
+	if node == nil {
+		return []string{}
+	}
+	if !transitive {
+		return g.names(node.users)
+	}
+	return g.names(g.walk(node, func(n *graphNode) []string { return n.users }))
+}
+
+// IsCask reports whether name is an installed cask.
+func (g *DependencyGraph) IsCask(name string) bool {
+	node := g.find(name)
+	return node != nil && node.cask
+}
+
+// Orphans returns the dependencies that nothing would need anymore once
+// removed are uninstalled: formulae installed as dependencies whose every
+// dependent is being removed or is itself orphaned. Packages installed on
+// request are never orphaned.
+func (g *DependencyGraph) Orphans(removed []string) []string {
+	gone := make(map[string]bool)
+	var candidates []string
+	for _, name := range removed {
+		node := g.find(name)
+		if node == nil {
+			continue
+		}
+		gone[node.fullName] = true
+		candidates = append(candidates, g.walk(node, func(n *graphNode) []string { return n.runtime })...)
+	}
+
+	var orphans []string
+	for changed := true; changed; {
+		changed = false
+		for _, fullName := range candidates {
+			node := g.nodes[fullName]
+			if gone[fullName] || node.onRequest || node.cask {
+				continue
+			}
+			needed := false
+			for _, user := range node.users {
+				if !gone[user] {
+					needed = true
+					break
+				}
+			}
+			if !needed {
+				gone[fullName] = true
+				orphans = append(orphans, fullName)
+				changed = true
+			}
+		}
+	}
+	return g.names(orphans)
+}
+
+// Tree returns the runtime dependency tree rooted at name, or nil when name is
+// not installed. A dependency reached again through a cycle is listed without
+// children.
+func (g *DependencyGraph) Tree(name string) *DependencyNode {
+	node := g.find(name)
+	if node == nil {
+		return nil
+	}
+	return g.tree(node, map[string]bool{})
+}
+
+func (g *DependencyGraph) tree(node *graphNode, path map[string]bool) *DependencyNode {
+	result := &DependencyNode{Name: node.name, Dependencies: []*DependencyNode{}}
+	if path[node.fullName] {
+		return result
+	}
+	path[node.fullName] = true
+	defer delete(path, node.fullName)
+
+	for _, dep := range node.runtime {
+		result.Dependencies = append(result.Dependencies, g.tree(g.nodes[dep], path))
+	}
+	return result
+}
+
+// graphCache keeps the most recent dependency graph until a mutating
+// operation invalidates it. The generation counter stops a load that started
+// before an invalidation from storing its now-stale graph.
+type graphCache struct {
+	mu         sync.Mutex
+	graph      *DependencyGraph
+	generation int
+}
+
+func (c *graphCache) get() (*DependencyGraph, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.graph, c.generation
+}
+
+func (c *graphCache) store(generation int, graph *DependencyGraph) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.graph = graph
+	}
+}
+
+func (c *graphCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.graph = nil
+	c.generation++
+}
@@ -0,0 +1,204 @@
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dependency graph export formats.
+const (
+	GraphFormatJSON    = "json"
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+)
+
+// Node kinds in an exported dependency graph. Casks are always on request.
+const (
+	GraphNodeLeaf       = "leaf"
+	GraphNodeOnRequest  = "on_request"
+	GraphNodeDependency = "dependency"
+)
+
+// Edge kinds in an exported dependency graph.
+const (
+	GraphEdgeRuntime = "runtime"
+	GraphEdgeBuild   = "build"
+)
+
+// GraphExportOptions selects what ExportDependencyGraph writes. An empty
+// Roots exports everything installed; otherwise only the roots and what they
+// depend on.
+type GraphExportOptions struct {
+	Format       string   `json:"format"`
+	Roots        []string `json:"roots"`
+	IncludeBuild bool     `json:"includeBuild"`
+}
+
+// GraphExport is the JSON form of an exported dependency graph.
+type GraphExport struct {
+	Nodes []GraphExportNode `json:"nodes"`
+	Edges []GraphExportEdge `json:"edges"`
+}
+
+// GraphExportNode is one installed package in an exported graph.
+type GraphExportNode struct {
+	Name     string `json:"name"`
+	FullName string `json:"fullName"`
+	Kind     string `json:"kind"`
+	Cask     bool   `json:"cask"`
+}
+
+// GraphExportEdge points from a package to one of its dependencies.
+type GraphExportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Export renders the graph, or the part reachable from options.Roots, in
+// options.Format. Nodes and edges are sorted so exports diff cleanly.
+func (g *DependencyGraph) Export(options GraphExportOptions) ([]byte, error) {
+	export, err := g.subgraph(options.Roots, options.IncludeBuild)
+	if err != nil {
+		return nil, err
+	}
+
+	switch options.Format {
+	case GraphFormatJSON, "":
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case GraphFormatDOT:
+		return []byte(export.dot()), nil
+	case GraphFormatMermaid:
+		return []byte(export.mermaid()), nil
+	default:
+		return nil, fmt.Errorf("unsupported graph format %q", options.Format)
+	}
+}
+
+func (g *DependencyGraph) subgraph(roots []string, includeBuild bool) (*GraphExport, error) {
+	next := func(n *graphNode) []string {
+		if includeBuild {
+			return append(append([]string{}, n.runtime...), n.build...)
+		}
+		return n.runtime
+	}
+
+	selected := make(map[string]bool)
+	if len(roots) == 0 {
+		for fullName := range g.nodes {
+			selected[fullName] = true
+		}
+	} else {
+		for _, root := range roots {
+			node := g.find(root)
+			if node == nil {
+				return nil, fmt.Errorf("%s is not installed", root)
+			}
+			selected[node.fullName] = true
+			for _, fullName := range g.walk(node, next) {
+				selected[fullName] = true
+			}
+		}
+	}
+
+	leaves := make(map[string]bool)
+	for _, fullName := range g.Leaves() {
+		leaves[fullName] = true
+	}
+
+	fullNames := make([]string, 0, len(selected))
+	for fullName := range selected {
+		fullNames = append(fullNames, fullName)
+	}
+	sort.Strings(fullNames)
+
+	export := &GraphExport{Nodes: []GraphExportNode{}, Edges: []GraphExportEdge{}}
+	for _, fullName := range fullNames {
+		node := g.nodes[fullName]
+		kind := GraphNodeDependency
+		switch {
+		case leaves[fullName]:
+			kind = GraphNodeLeaf
+		case node.onRequest:
+			kind = GraphNodeOnRequest
+		}
+		export.Nodes = append(export.Nodes, GraphExportNode{Name: node.name, FullName: fullName, Kind: kind, Cask: node.cask})
+
+		for _, dep := range node.runtime {
+			export.Edges = append(export.Edges, GraphExportEdge{From: fullName, To: dep, Kind: GraphEdgeRuntime})
+		}
+		if includeBuild {
+			for _, dep := range node.build {
+				export.Edges = append(export.Edges, GraphExportEdge{From: fullName, To: dep, Kind: GraphEdgeBuild})
+			}
+		}
+	}
+	return export, nil
+}
+
+// graphNodeColors are the fill colours used by the DOT and Mermaid exports.
+var graphNodeColors = map[string]string{
+	GraphNodeLeaf:       "#c8e6c9",
+	GraphNodeOnRequest:  "#bbdefb",
+	GraphNodeDependency: "#eeeeee",
+}
+
+func (e *GraphExport) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph homebrew {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=filled];\n")
+	for _, node := range e.Nodes {
+		shape := ""
+		if node.Cask {
+			shape = ", shape=box3d"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, fillcolor=%q%s];\n", dotID(node.FullName), dotID(node.Name), graphNodeColors[node.Kind], shape)
+	}
+	for _, edge := range e.Edges {
+		style := ""
+		if edge.Kind == GraphEdgeBuild {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", dotID(edge.From), dotID(edge.To), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotID quotes a package name as a DOT identifier.
+func dotID(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+}
+
+func (e *GraphExport) mermaid() string {
+	// Mermaid ids cannot contain "@", "/" or "+", so nodes get positional ids
+	// and carry the package name as their label.
+	ids := make(map[string]string, len(e.Nodes))
+	for i, node := range e.Nodes {
+		ids[node.FullName] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, node := range e.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]:::%s\n", ids[node.FullName], strings.ReplaceAll(node.Name, `"`, "#quot;"), node.Kind)
+	}
+	for _, edge := range e.Edges {
+		arrow := "-->"
+		if edge.Kind == GraphEdgeBuild {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+	for _, kind := range []string{GraphNodeLeaf, GraphNodeOnRequest, GraphNodeDependency} {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", kind, graphNodeColors[kind])
+	}
+	return b.String()
+}
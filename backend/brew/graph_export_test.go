@@ -0,0 +1,118 @@
+package brew
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyGraph_ExportJSON(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	data, err := graph.Export(GraphExportOptions{Format: GraphFormatJSON, Roots: []string{"app"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var export GraphExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export is not JSON: %v\n%s", err, data)
+	}
+
+	wantNodes := []GraphExportNode{
+		{Name: "app", FullName: "app", Kind: GraphNodeLeaf},
+		{Name: "base", FullName: "base", Kind: GraphNodeDependency},
+		{Name: "lib", FullName: "lib", Kind: GraphNodeDependency},
+	}
+	if !reflect.DeepEqual(export.Nodes, wantNodes) {
+		t.Fatalf("nodes:\n got %+v\nwant %+v", export.Nodes, wantNodes)
+	}
+	wantEdges := []GraphExportEdge{
+		{From: "app", To: "lib", Kind: GraphEdgeRuntime},
+		{From: "lib", To: "base", Kind: GraphEdgeRuntime},
+	}
+	if !reflect.DeepEqual(export.Edges, wantEdges) {
+		t.Fatalf("edges:\n got %+v\nwant %+v", export.Edges, wantEdges)
+	}
+}
+
+func TestDependencyGraph_ExportOptions(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	tests := []struct {
+		name        string
+		options     GraphExportOptions
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "dot marks kinds and build edges",
+			options:  GraphExportOptions{Format: GraphFormatDOT, Roots: []string{"app"}, IncludeBuild: true},
+			contains: []string{"digraph homebrew {", `"app" -> "lib";`, `"app" -> "cmake" [style=dashed];`, `"cmake" [label="cmake", fillcolor="#c8e6c9"];`},
+		},
+		{
+			name:        "build dependencies are opt-in",
+			options:     GraphExportOptions{Format: GraphFormatDOT, Roots: []string{"app"}},
+			notContains: []string{"cmake"},
+		},
+		{
+			name:     "mermaid uses positional ids",
+			options:  GraphExportOptions{Format: GraphFormatMermaid, Roots: []string{"widget", "tool-app"}},
+			contains: []string{"graph LR", `n0["widget"]:::leaf`, `n3["tool-app"]:::on_request`, "n3 --> n2", "classDef dependency"},
+		},
+		{
+			name:     "whole graph without roots",
+			options:  GraphExportOptions{Format: GraphFormatDOT},
+			contains: []string{`"acme/tools/widget" [label="widget"`, `"ping" -> "pong";`, `"pong" -> "ping";`, `"tool-app" [label="tool-app", fillcolor="#bbdefb", shape=box3d];`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := graph.Export(tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("export lacks %q:\n%s", want, data)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(string(data), unwanted) {
+					t.Errorf("export contains %q:\n%s", unwanted, data)
+				}
+			}
+		})
+	}
+}
+
+func TestDependencyGraph_ExportErrors(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	if _, err := graph.Export(GraphExportOptions{Format: "svg"}); err == nil {
+		t.Fatal("expected an unsupported format error")
+	}
+	if _, err := graph.Export(GraphExportOptions{Format: GraphFormatJSON, Roots: []string{"missing"}}); err == nil {
+		t.Fatal("expected an error for a root that is not installed")
+	}
+}
+
+func TestExportDependencyGraph_writesFile(t *testing.T) {
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	service, _ := newFakeService(fb)
+	path := filepath.Join(t.TempDir(), "dependencies.mmd")
+
+	if err := service.ExportDependencyGraph(path, GraphExportOptions{Format: GraphFormatMermaid}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("export was not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "graph LR\n") {
+		t.Fatalf("unexpected export:\n%s", data)
+	}
+}
@@ -0,0 +1,190 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// recordedDependencyInventory is trimmed `brew info --json=v2 --installed`
+// output with a direct and an indirect runtime dependency, a build
+// dependency, a receipt without declared_directly flags, a dependency cycle,
+// a cask that depends on a formula and casks that only a greedy upgrade
+// touches.
+const recordedDependencyInventory = `{
+  "formulae": [
+    {"name": "app", "full_name": "app", "dependencies": ["lib"], "build_dependencies": ["cmake", "ninja"],
+      "installed": [{"version": "1.0", "installed_on_request": true, "runtime_dependencies": [
+        {"full_name": "lib", "declared_directly": true}, {"full_name": "base", "declared_directly": false}]}]},
+    {"name": "lib", "full_name": "lib", "dependencies": ["base"],
+      "installed": [{"version": "2.0", "installed_as_dependency": true, "runtime_dependencies": [
+        {"full_name": "base", "declared_directly": true}]}]},
+    {"name": "base", "full_name": "base",
+      "installed": [{"version": "3.0", "installed_as_dependency": true}]},
+    {"name": "cmake", "full_name": "cmake",
+      "installed": [{"version": "3.30.0", "installed_on_request": true}]},
+    {"name": "legacy", "full_name": "legacy", "dependencies": ["lib"],
+      "installed": [{"version": "0.1", "installed_on_request": true, "runtime_dependencies": [
+        {"full_name": "lib"}, {"full_name": "base"}]}]},
+    {"name": "widget", "full_name": "acme/tools/widget",
+      "installed": [{"version": "0.3.0", "installed_on_request": true}]},
+    {"name": "ping", "full_name": "ping", "dependencies": ["pong"],
+      "installed": [{"version": "1", "installed_on_request": true}]},
+    {"name": "pong", "full_name": "pong", "dependencies": ["ping"],
+      "installed": [{"version": "1", "installed_as_dependency": true}]}
+  ],
+  "casks": [
+    {"token": "tool-app", "full_token": "tool-app", "installed": "4.0", "depends_on": {"formula": ["lib"]}},
+    {"token": "browser", "full_token": "browser", "version": "130.0", "auto_updates": true, "installed": "129.0"},
+    {"token": "nightly", "full_token": "nightly", "version": "latest", "installed": "latest"}
+  ]
+}`
+
+func newRecordedGraph(t *testing.T) *DependencyGraph {
+	t.Helper()
+	var info inventoryJSON
+	if err := json.Unmarshal([]byte(recordedDependencyInventory), &info); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return newDependencyGraph(&info)
+}
+
+func TestDependencyGraph_Leaves(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	// cmake is only a build dependency and lib is only needed by formulae
+	// and a cask, so cmake is a leaf while lib is not; ping and pong keep
+	// each other off the list.
+	want := []string{"acme/tools/widget", "app", "cmake", "legacy"}
+	if got := graph.Leaves(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Leaves() = %v, want %v", got, want)
+	}
+}
+
+func TestDependencyGraph_Dependencies(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	tests := []struct {
+		name         string
+		pkg          string
+		transitive   bool
+		includeBuild bool
+		want         []string
+	}{
+		{"direct only", "app", false, false, []string{"lib"}},
+		{"transitive", "app", true, false, []string{"base", "lib"}},
+		{"direct with installed build deps", "app", false, true, []string{"cmake", "lib"}},
+		{"transitive with build deps", "app", true, true, []string{"base", "cmake", "lib"}},
+		{"receipt without flags uses declared deps", "legacy", false, false, []string{"lib"}},
+		{"cask depends_on", "tool-app", true, false, []string{"base", "lib"}},
+		{"cycle terminates", "ping", true, false, []string{"pong"}},
+		{"not installed", "missing", true, false, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graph.Dependencies(tt.pkg, tt.transitive, tt.includeBuild); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Dependencies(%q) = %v, want %v", tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencyGraph_Dependents(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	tests := []struct {
+		name       string
+		pkg        string
+		transitive bool
+		want       []string
+	}{
+		{"direct", "base", false, []string{"lib"}},
+		{"transitive", "base", true, []string{"app", "legacy", "lib", "tool-app"}},
+		{"includes casks", "lib", false, []string{"app", "legacy", "tool-app"}},
+		{"build deps are not dependents", "cmake", true, []string{}},
+		{"short name of a tap formula", "widget", true, []string{}},
+		{"cycle terminates", "ping", true, []string{"pong"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graph.Dependents(tt.pkg, tt.transitive); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Dependents(%q) = %v, want %v", tt.pkg, got, tt.want)
+			}
+		})
+	}
+
+	if !graph.Has("acme/tools/widget") || !graph.Has("widget") || graph.Has("missing") {
+		t.Fatal("Has() does not resolve full and short names")
+	}
+}
+
+func TestDependencyGraph_Tree(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	leaf := func(name string, deps ...*DependencyNode) *DependencyNode {
+		if deps == nil {
+			deps = []*DependencyNode{}
+		}
+		return &DependencyNode{Name: name, Dependencies: deps}
+	}
+
+	tests := []struct {
+		pkg  string
+		want *DependencyNode
+	}{
+		{"app", leaf("app", leaf("lib", leaf("base")))},
+		{"ping", leaf("ping", leaf("pong", leaf("ping")))},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		if got := graph.Tree(tt.pkg); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("Tree(%q) = %+v, want %+v", tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestDependencyGraph_reusedUntilMutation(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("install wget", brewRecording{})
+	service, _ := newFakeService(fb)
+
+	service.GetInstalledDependents("lib")
+	service.GetInstalledDependencies("app")
+	if _, err := service.GetLeaves(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := fb.invoked("info --json=v2 --installed"); n != 1 {
+		t.Fatalf("expected one inventory read before the install, got %d", n)
+	}
+
+	service.InstallBrewPackage(context.Background(), "wget")
+	service.GetInstalledDependents("lib")
+	if n := fb.invoked("info --json=v2 --installed"); n != 2 {
+		t.Fatalf("expected the install to invalidate the graph, got %d reads", n)
+	}
+	if fb.cleared == 0 {
+		t.Fatal("expected the install to clear the cached command output")
+	}
+	for _, call := range fb.calls {
+		if call == "leaves" || call == "deps lib --installed" || call == "uses lib --installed" {
+			t.Fatalf("graph queries must not spawn %q", call)
+		}
+	}
+}
+
+func TestGraphCache_dropsGraphLoadedBeforeInvalidation(t *testing.T) {
+	var cache graphCache
+	_, generation := cache.get()
+
+	cache.invalidate()
+	cache.store(generation, &DependencyGraph{})
+
+	if graph, _ := cache.get(); graph != nil {
+		t.Fatal("a graph loaded before invalidation must not be cached")
+	}
+}
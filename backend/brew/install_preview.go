@@ -0,0 +1,349 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"WailBrew/backend/system"
+)
+
+// InstallPreview is what installing a package would add: the package itself
+// and every dependency that is not installed yet.
+type InstallPreview struct {
+	Name             string                `json:"name"`
+	Command          string                `json:"command"`
+	AlreadyInstalled bool                  `json:"alreadyInstalled"`
+	Packages         []InstallPreviewEntry `json:"packages"`
+}
+
+// InstallPreviewEntry is one package an install would add.
+type InstallPreviewEntry struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Version    string `json:"version"`
+	Dependency bool   `json:"dependency"`
+	// Bottle is set when a pre-built bottle exists for this platform;
+	// without one the formula is built from source. Always false for casks.
+	Bottle bool `json:"bottle"`
+	// BottleSize is the bottle's download size, read from Homebrew's download
+	// cache or else from the registry; empty when neither answers. Cached
+	// marks bottles that need no download.
+	BottleSize string `json:"bottleSize"`
+	Cached     bool   `json:"cached"`
+	Caveats    string `json:"caveats"`
+}
+
+// installInfoJSON is the subset of `brew info --json=v2` the install preview
+// reads.
+type installInfoJSON struct {
+	Formulae []struct {
+		Name              string   `json:"name"`
+		FullName          string   `json:"full_name"`
+		Dependencies      []string `json:"dependencies"`
+		BuildDependencies []string `json:"build_dependencies"`
+		Caveats           *string  `json:"caveats"`
+		Versions          struct {
+			Stable string `json:"stable"`
+		} `json:"versions"`
+		Bottle struct {
+			Stable *struct {
+				Files map[string]json.RawMessage `json:"files"`
+			} `json:"stable"`
+		} `json:"bottle"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token     string  `json:"token"`
+		Version   string  `json:"version"`
+		Caveats   *string `json:"caveats"`
+		DependsOn struct {
+			Formula []string `json:"formula"`
+			Cask    []string `json:"cask"`
+		} `json:"depends_on"`
+	} `json:"casks"`
+}
+
+// macOSBottleNames are Homebrew's bottle tag names by macOS major version.
+var macOSBottleNames = []struct {
+	major int
+	name  string
+}{
+	{26, "tahoe"},
+	{15, "sequoia"},
+	{14, "sonoma"},
+	{13, "ventura"},
+	{12, "monterey"},
+	{11, "big_sur"},
+	{10, "catalina"},
+}
+
+// compatibleBottleTags lists the bottle tags Homebrew would pour on this
+// platform, most preferred first. On macOS a bottle built for an older
+// release also works on newer ones.
+func compatibleBottleTags(goos, goarch string, macOSMajor int) []string {
+	if goos != "darwin" {
+		if goarch == "arm64" {
+			return []string{"arm64_linux"}
+		}
+		return []string{"x86_64_linux"}
+	}
+
+	prefix := ""
+	if goarch == "arm64" {
+		prefix = "arm64_"
+	}
+	var tags []string
+	for _, release := range macOSBottleNames {
+		if release.major <= macOSMajor {
+			tags = append(tags, prefix+release.name)
+		}
+	}
+	return tags
+}
+
+// hostBottleTags resolves compatibleBottleTags for the running system.
+func hostBottleTags() []string {
+	major := 0
+	if runtime.GOOS == "darwin" {
+		if version, err := system.GetMacOSVersion(); err == nil {
+			major, _ = strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+		}
+	}
+	return compatibleBottleTags(runtime.GOOS, runtime.GOARCH, major)
+}
+
+// hasCompatibleBottle reports whether files, the bottle files of a formula
+// keyed by tag, include one pourable with tags.
+func hasCompatibleBottle(files map[string]json.RawMessage, tags []string) bool {
+	_, ok := compatibleBottle(files, tags)
+	return ok
+}
+
+// compatibleBottle returns the bottle file Homebrew would pour with tags.
+func compatibleBottle(files map[string]json.RawMessage, tags []string) (json.RawMessage, bool) {
+	if file, ok := files["all"]; ok {
+		return file, true
+	}
+	for _, tag := range tags {
+		if file, ok := files[tag]; ok {
+			return file, true
+		}
+	}
+	return nil, false
+}
+
+// bottleURL is the download URL of a bottle file from `brew info --json=v2`.
+func bottleURL(file json.RawMessage) string {
+	var bottle struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(file, &bottle) != nil {
+		return ""
+	}
+	return bottle.URL
+}
+
+// PreviewInstall resolves what `brew install packageName` would add without
+// installing anything. Dependencies are followed level by level through
+// `brew info --json=v2`, skipping whatever is already installed; formulae
+// without a bottle for this platform also pull in their build dependencies.
+// Cancelling ctx abandons the registry lookups for bottle sizes.
+func (s *serviceImpl) PreviewInstall(ctx context.Context, packageName string) (*InstallPreview, error) {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &InstallPreview{
+		Name:             packageName,
+		Command:          FormatCommand(BuildInstallArgs(packageName)),
+		AlreadyInstalled: graph.Has(packageName),
+		Packages:         []InstallPreviewEntry{},
+	}
+	if preview.AlreadyInstalled {
+		return preview, nil
+	}
+
+	tags := hostBottleTags()
+	urls := map[string]string{}
+	seen := map[string]bool{packageName: true}
+	pending := []string{packageName}
+	for len(pending) > 0 {
+		output, err := s.runner.Run(append([]string{"info", "--json=v2"}, pending...)...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get package info: %v", err)
+		}
+		jsonOutput, _, err := s.extractJSON(strings.TrimSpace(string(output)))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to extract JSON from package info: %v", err)
+		}
+		var info installInfoJSON
+		if err := json.Unmarshal([]byte(jsonOutput), &info); err != nil {
+			return nil, fmt.Errorf("Failed to parse package info: %v", err)
+		}
+
+		var next []string
+		queue := func(names ...string) {
+			for _, name := range names {
+				if !seen[name] && !graph.Has(name) {
+					seen[name] = true
+					next = append(next, name)
+				}
+			}
+		}
+
+		for _, f := range info.Formulae {
+			entry := InstallPreviewEntry{
+				Name:       f.Name,
+				Type:       PackageTypeFormula,
+				Version:    f.Versions.Stable,
+				Dependency: f.Name != packageName && f.FullName != packageName,
+			}
+			if f.Bottle.Stable != nil {
+				var file json.RawMessage
+				file, entry.Bottle = compatibleBottle(f.Bottle.Stable.Files, tags)
+				urls[f.Name] = bottleURL(file)
+			}
+			if f.Caveats != nil {
+				entry.Caveats = strings.TrimSpace(*f.Caveats)
+			}
+			preview.Packages = append(preview.Packages, entry)
+
+			queue(f.Dependencies...)
+			if !entry.Bottle {
+				queue(f.BuildDependencies...)
+			}
+		}
+		for _, c := range info.Casks {
+			entry := InstallPreviewEntry{
+				Name:       c.Token,
+				Type:       PackageTypeCask,
+				Version:    c.Version,
+				Dependency: c.Token != packageName,
+			}
+			if c.Caveats != nil {
+				entry.Caveats = strings.TrimSpace(*c.Caveats)
+			}
+			preview.Packages = append(preview.Packages, entry)
+
+			queue(c.DependsOn.Formula...)
+			queue(c.DependsOn.Cask...)
+		}
+
+		pending = next
+	}
+
+	s.fillBottleSizes(ctx, preview, urls)
+	return preview, nil
+}
+
+// bottleClient fetches bottle sizes from the registry. The preview waits for
+// it, so it gives up quickly.
+var bottleClient = &http.Client{Timeout: 10 * time.Second}
+
+// bottleSizeLookups bounds how many registry requests one preview has in
+// flight, so a package with a long dependency chain does not open a
+// connection per bottle at once.
+const bottleSizeLookups = 6
+
+// bottleMirrorVars are the settings that point brew at a bottle mirror.
+// Bottles then do not come from the registry in the formula JSON, so asking
+// it would report a size for a download that never happens.
+var bottleMirrorVars = []string{"HOMEBREW_BOTTLE_DOMAIN", "HOMEBREW_ARTIFACT_DOMAIN"}
+
+// fillBottleSizes records the download size of each bottle: from the file
+// in Homebrew's download cache when it is already there, otherwise from the
+// registry at urls, keyed by formula name. Registry lookups are skipped when
+// a bottle mirror is configured and stop when ctx is cancelled.
+func (s *serviceImpl) fillBottleSizes(ctx context.Context, preview *InstallPreview, urls map[string]string) {
+	s.fillCachedBottleSizes(preview)
+
+	for _, key := range bottleMirrorVars {
+		if s.runner.Getenv(key) != "" {
+			return
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bottleSizeLookups)
+	for i := range preview.Packages {
+		entry := &preview.Packages[i]
+		if !entry.Bottle || entry.Cached || urls[entry.Name] == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			if size, err := registryBlobSize(ctx, bottleClient, url); err == nil {
+				entry.BottleSize = formatDuSize(size)
+			}
+		}(urls[entry.Name])
+	}
+	wg.Wait()
+}
+
+// registryBlobSize asks the registry for the size of a bottle blob without
+// downloading it. GitHub Packages, which hosts Homebrew's bottles, serves
+// them to anonymous clients with the token Homebrew itself sends.
+func registryBlobSize(ctx context.Context, client *http.Client, url string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer QQ==")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return 0, fmt.Errorf("no size for %s: %s", url, resp.Status)
+	}
+	return resp.ContentLength, nil
+}
+
+// fillCachedBottleSizes asks `brew --cache` where each bottle would be
+// downloaded to and records the size of those already there. brew prints one
+// path per name, in order.
+func (s *serviceImpl) fillCachedBottleSizes(preview *InstallPreview) {
+	var bottled []*InstallPreviewEntry
+	args := []string{"--cache"}
+	for i := range preview.Packages {
+		if entry := &preview.Packages[i]; entry.Bottle {
+			bottled = append(bottled, entry)
+			args = append(args, entry.Name)
+		}
+	}
+
+	if len(bottled) == 0 {
+		return
+	}
+
+	output, err := s.runner.RunStdoutOnly(args...)
+	if err != nil {
+		return
+	}
+	paths := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i, entry := range bottled {
+		if i >= len(paths) {
+			break
+		}
+		if stat, err := os.Stat(strings.TrimSpace(paths[i])); err == nil && !stat.IsDir() {
+			entry.Cached = true
+			entry.BottleSize = formatDuSize(stat.Size())
+		}
+	}
+}
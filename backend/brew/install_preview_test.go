@@ -0,0 +1,218 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompatibleBottleTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		goos   string
+		goarch string
+		major  int
+		want   []string
+	}{
+		{"apple silicon", "darwin", "arm64", 14, []string{"arm64_sonoma", "arm64_ventura", "arm64_monterey", "arm64_big_sur", "arm64_catalina"}},
+		{"intel", "darwin", "amd64", 12, []string{"monterey", "big_sur", "catalina"}},
+		{"linux", "linux", "amd64", 0, []string{"x86_64_linux"}},
+		{"linux arm", "linux", "arm64", 0, []string{"arm64_linux"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compatibleBottleTags(tt.goos, tt.goarch, tt.major); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("compatibleBottleTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasCompatibleBottle(t *testing.T) {
+	files := func(tags ...string) map[string]json.RawMessage {
+		m := make(map[string]json.RawMessage)
+		for _, tag := range tags {
+			m[tag] = json.RawMessage(`{}`)
+		}
+		return m
+	}
+	tags := compatibleBottleTags("darwin", "arm64", 15)
+
+	tests := []struct {
+		name  string
+		files map[string]json.RawMessage
+		want  bool
+	}{
+		{"exact", files("arm64_sequoia"), true},
+		{"older release", files("arm64_sonoma", "sonoma"), true},
+		{"newer release only", files("arm64_tahoe"), false},
+		{"other architecture", files("sequoia"), false},
+		{"platform independent", files("all"), true},
+		{"none", files(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasCompatibleBottle(tt.files, tags); got != tt.want {
+				t.Fatalf("hasCompatibleBottle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewInstall(t *testing.T) {
+	cached := filepath.Join(t.TempDir(), "wget--1.25.0.all.bottle.tar.gz")
+	if err := os.WriteFile(cached, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.Header.Get("Authorization") != "Bearer QQ==" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Length", "4096")
+	}))
+	defer registry.Close()
+
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("info --json=v2 wget", brewRecording{stdout: `{"formulae": [{"name": "wget", "full_name": "wget",
+			"dependencies": ["lib", "gettext"], "build_dependencies": ["cmake"],
+			"caveats": "wget reads ~/.wgetrc\n", "versions": {"stable": "1.25.0"},
+			"bottle": {"stable": {"files": {"all": {}}}}}], "casks": []}`}).
+		script("info --json=v2 gettext", brewRecording{stdout: `{"formulae": [{"name": "gettext", "full_name": "gettext",
+			"build_dependencies": ["pkgconf"], "caveats": null, "versions": {"stable": "0.22.5"},
+			"bottle": {}}], "casks": []}`}).
+		script("info --json=v2 pkgconf", brewRecording{stdout: `{"formulae": [{"name": "pkgconf", "full_name": "pkgconf",
+			"versions": {"stable": "2.3.0"}, "bottle": {"stable": {"files": {"all": {
+				"url": "` + registry.URL + `/v2/homebrew/core/pkgconf/blobs/sha256:abc"}}}}}], "casks": []}`}).
+		script("--cache wget pkgconf", brewRecording{stdout: cached + "\n/nonexistent/pkgconf--2.3.0.all.bottle.tar.gz\n"})
+	service, _ := newFakeService(fb)
+
+	preview, err := service.PreviewInstall(context.Background(), "wget")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []InstallPreviewEntry{
+		{Name: "wget", Type: PackageTypeFormula, Version: "1.25.0", Bottle: true, BottleSize: "2.0K", Cached: true, Caveats: "wget reads ~/.wgetrc"},
+		{Name: "gettext", Type: PackageTypeFormula, Version: "0.22.5", Dependency: true},
+		{Name: "pkgconf", Type: PackageTypeFormula, Version: "2.3.0", Dependency: true, Bottle: true, BottleSize: "4.0K"},
+	}
+	if !reflect.DeepEqual(preview.Packages, want) {
+		t.Fatalf("packages:\n got %+v\nwant %+v", preview.Packages, want)
+	}
+	if preview.Command != "brew install wget" || preview.AlreadyInstalled {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	for _, call := range fb.calls {
+		if call == "info --json=v2 lib" || call == "info --json=v2 cmake" {
+			t.Fatalf("installed dependency looked up: %q", call)
+		}
+	}
+}
+
+func TestPreviewInstall_alreadyInstalled(t *testing.T) {
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	service, _ := newFakeService(fb)
+
+	preview, err := service.PreviewInstall(context.Background(), "lib")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !preview.AlreadyInstalled || len(preview.Packages) != 0 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+}
+
+// bottlePreview is a preview of n uncached bottles served by registry.
+func bottlePreview(n int, registry string) (*InstallPreview, map[string]string) {
+	preview := &InstallPreview{}
+	urls := map[string]string{}
+	for i := range n {
+		name := fmt.Sprintf("dep%d", i)
+		preview.Packages = append(preview.Packages, InstallPreviewEntry{Name: name, Type: PackageTypeFormula, Bottle: true})
+		urls[name] = registry + "/v2/homebrew/core/" + name + "/blobs/sha256:abc"
+	}
+	return preview, urls
+}
+
+func TestFillBottleSizes_boundsLookups(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Length", "4096")
+	}))
+	defer registry.Close()
+
+	service, _ := newFakeService(newFakeBrew())
+	preview, urls := bottlePreview(3*bottleSizeLookups, registry.URL)
+	service.(*serviceImpl).fillBottleSizes(context.Background(), preview, urls)
+
+	if got := peak.Load(); got > bottleSizeLookups {
+		t.Fatalf("%d lookups in flight, want at most %d", got, bottleSizeLookups)
+	}
+	for _, entry := range preview.Packages {
+		if entry.BottleSize != "4.0K" {
+			t.Fatalf("%s: size %q, want 4.0K", entry.Name, entry.BottleSize)
+		}
+	}
+}
+
+func TestFillBottleSizes_skipsRegistry(t *testing.T) {
+	var requests atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Length", "4096")
+	}))
+	defer registry.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		env  map[string]string
+	}{
+		{"bottle mirror", context.Background(), map[string]string{"HOMEBREW_BOTTLE_DOMAIN": "https://mirror.example/bottles"}},
+		{"artifact mirror", context.Background(), map[string]string{"HOMEBREW_ARTIFACT_DOMAIN": "https://mirror.example"}},
+		{"cancelled preview", cancelled, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew()
+			fb.env = tt.env
+			service, _ := newFakeService(fb)
+			preview, urls := bottlePreview(3, registry.URL)
+			service.(*serviceImpl).fillBottleSizes(tt.ctx, preview, urls)
+
+			if got := requests.Load(); got != 0 {
+				t.Fatalf("%d registry requests, want none", got)
+			}
+			for _, entry := range preview.Packages {
+				if entry.BottleSize != "" {
+					t.Fatalf("%s: size %q, want none", entry.Name, entry.BottleSize)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Inventory is a snapshot of everything installed, built from a single
+// `brew info --json=v2 --installed` call instead of one command per view.
+type Inventory struct {
+	Formulae []InstalledFormula `json:"formulae"`
+	Casks    []InstalledCask    `json:"casks"`
+	// Leaves are the full names of installed formulae that no other installed
+	// formula depends on at runtime, matching `brew leaves`.
+	Leaves []string `json:"leaves"`
+
+	graph *DependencyGraph
+}
+
+// inventoryJSON is the subset of `brew info --json=v2 --installed` the
+// inventory is built from.
+type inventoryJSON struct {
+	Formulae []struct {
+		Name              string         `json:"name"`
+		FullName          string         `json:"full_name"`
+		Tap               string         `json:"tap"`
+		Desc              string         `json:"desc"`
+		Homepage          string         `json:"homepage"`
+		Pinned            bool           `json:"pinned"`
+		KegOnly           bool           `json:"keg_only"`
+		LinkedKeg         *string        `json:"linked_keg"`
+		Dependencies      []string       `json:"dependencies"`
+		BuildDependencies []string       `json:"build_dependencies"`
+		Installed         []inventoryKeg `json:"installed"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token       string          `json:"token"`
+		FullToken   string          `json:"full_token"`
+		Tap         string          `json:"tap"`
+		Desc        string          `json:"desc"`
+		Homepage    string          `json:"homepage"`
+		Version     string          `json:"version"`
+		AutoUpdates bool            `json:"auto_updates"`
+		Installed   json.RawMessage `json:"installed"`
+		DependsOn   struct {
+			Formula []string `json:"formula"`
+			Cask    []string `json:"cask"`
+		} `json:"depends_on"`
+	} `json:"casks"`
+}
+
+// inventoryKeg is one installed version of a formula, read from its install
+// receipt.
+type inventoryKeg struct {
+	Version               string `json:"version"`
+	InstalledOnRequest    bool   `json:"installed_on_request"`
+	InstalledAsDependency bool   `json:"installed_as_dependency"`
+	RuntimeDependencies   []struct {
+		FullName         string `json:"full_name"`
+		DeclaredDirectly *bool  `json:"declared_directly"`
+	} `json:"runtime_dependencies"`
+}
+
+// parseInventory builds an Inventory from `brew info --json=v2 --installed`
+// output. Diagnostics printed ahead of the JSON are ignored.
+func parseInventory(output []byte) (*Inventory, error) {
+	jsonOutput, _, err := ExtractJSONFromOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	var info inventoryJSON
+	if err := json.Unmarshal([]byte(jsonOutput), &info); err != nil {
+		return nil, err
+	}
+
+	graph := newDependencyGraph(&info)
+	inventory := &Inventory{
+		Formulae: make([]InstalledFormula, 0, len(info.Formulae)),
+		Casks:    make([]InstalledCask, 0, len(info.Casks)),
+		Leaves:   graph.Leaves(),
+		graph:    graph,
+	}
+
+	for _, f := range info.Formulae {
+		formula := InstalledFormula{
+			Name:          f.Name,
+			Version:       "Unknown",
+			InstallReason: InstallReasonUnknown,
+			Tap:           f.Tap,
+			Pinned:        f.Pinned,
+			KegOnly:       f.KegOnly,
+			Desc:          f.Desc,
+			Homepage:      f.Homepage,
+		}
+
+		if n := len(f.Installed); n > 0 {
+			// The linked keg is the version in use; keg-only and unlinked
+			// formulae fall back to the newest installed keg.
+			formula.Version = f.Installed[n-1].Version
+			if f.LinkedKeg != nil && *f.LinkedKeg != "" {
+				formula.Linked = true
+				formula.Version = *f.LinkedKeg
+			}
+
+			switch {
+			case f.Installed[0].InstalledOnRequest:
+				formula.InstallReason = InstallReasonOnRequest
+			case f.Installed[0].InstalledAsDependency:
+				formula.InstallReason = InstallReasonDependency
+			}
+		}
+
+		inventory.Formulae = append(inventory.Formulae, formula)
+	}
+
+	for _, c := range info.Casks {
+		version := extractCaskInstalledVersion(c.Installed)
+		if version == "" {
+			version = "Unknown"
+		}
+		inventory.Casks = append(inventory.Casks, InstalledCask{
+			Name:     c.Token,
+			Version:  version,
+			Tap:      c.Tap,
+			Desc:     c.Desc,
+			Homepage: c.Homepage,
+		})
+	}
+
+	return inventory, nil
+}
+
+// extractCaskInstalledVersion reads the installed version from brew info JSON.
+// The field may be a version string, an array of strings, or null.
+func extractCaskInstalledVersion(installed json.RawMessage) string {
+	if len(installed) == 0 || string(installed) == "null" {
+		return ""
+	}
+
+	var version string
+	if err := json.Unmarshal(installed, &version); err == nil && version != "" {
+		return version
+	}
+
+	var versions []string
+	if err := json.Unmarshal(installed, &versions); err == nil && len(versions) > 0 {
+		return versions[0]
+	}
+
+	return ""
+}
+
+// LoadInventory reads every installed formula and cask in one brew call and
+// refreshes the cached dependency graph.
+func (s *ListService) LoadInventory() (*Inventory, error) {
+	// Validate brew installation first
+	if err := s.validateFunc(); err != nil {
+		return nil, fmt.Errorf("Homebrew validation failed: %v", err)
+	}
+
+	_, generation := s.graph.get()
+	output, err := s.executor.RunStdoutOnly("info", "--json=v2", "--installed")
+	if err != nil {
+		s.reportFailure(err)
+		if IsBrokenRubyStateError(err.Error()) {
+			return nil, fmt.Errorf("Failed to fetch installed packages: %v\n\n%s", err, BrokenRubyStateRemedy)
+		}
+		return nil, fmt.Errorf("Failed to fetch installed packages: %v", err)
+	}
+
+	if strings.TrimSpace(string(output)) == "" {
+		output = []byte(`{"formulae":[],"casks":[]}`)
+	}
+
+	inventory, err := parseInventory(output)
+	if err != nil {
+		s.log(fmt.Sprintf("Failed to parse installed inventory from brew info: %v", err))
+		return nil, fmt.Errorf("Failed to parse installed packages: %v", err)
+	}
+	s.graph.store(generation, inventory.graph)
+	return inventory, nil
+}
@@ -0,0 +1,120 @@
+package brew
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordedInventory is trimmed `brew info --json=v2 --installed` output
+// covering a linked formula, an unlinked keg-only dependency, a tap formula
+// and a cask.
+const recordedInventory = `{
+  "formulae": [
+    {
+      "name": "wget", "full_name": "wget", "tap": "homebrew/core",
+      "desc": "Internet file retriever", "homepage": "https://www.gnu.org/software/wget/",
+      "pinned": false, "linked_keg": "1.24.5",
+      "installed": [{"version": "1.24.5", "installed_on_request": true, "installed_as_dependency": false,
+        "runtime_dependencies": [{"full_name": "openssl@3"}, {"full_name": "libidn2"}]}]
+    },
+    {
+      "name": "openssl@3", "full_name": "openssl@3", "tap": "homebrew/core",
+      "pinned": true, "keg_only": true, "linked_keg": null,
+      "installed": [{"version": "3.3.1", "installed_on_request": false, "installed_as_dependency": true,
+        "runtime_dependencies": [{"full_name": "ca-certificates"}]},
+        {"version": "3.3.2", "installed_on_request": false, "installed_as_dependency": true,
+        "runtime_dependencies": [{"full_name": "ca-certificates"}]}]
+    },
+    {
+      "name": "libidn2", "full_name": "libidn2", "tap": "homebrew/core", "linked_keg": "2.3.7",
+      "installed": [{"version": "2.3.7", "installed_as_dependency": true}]
+    },
+    {
+      "name": "ca-certificates", "full_name": "ca-certificates", "tap": "homebrew/core", "linked_keg": "2024-07-02",
+      "installed": [{"version": "2024-07-02", "installed_as_dependency": true}]
+    },
+    {
+      "name": "widget", "full_name": "acme/tools/widget", "tap": "acme/tools", "linked_keg": "0.3.0",
+      "installed": [{"version": "0.3.0", "installed_on_request": true}]
+    }
+  ],
+  "casks": [
+    {"token": "firefox", "tap": "homebrew/cask", "desc": "Web browser", "homepage": "https://www.mozilla.org/firefox/", "installed": "131.0"},
+    {"token": "iterm2", "tap": "homebrew/cask", "installed": null}
+  ]
+}`
+
+func TestParseInventory(t *testing.T) {
+	inventory, err := parseInventory([]byte("Warning: acme/tools is a third-party tap.\n" + recordedInventory))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantFormulae := []InstalledFormula{
+		{Name: "wget", Version: "1.24.5", InstallReason: InstallReasonOnRequest, Tap: "homebrew/core", Linked: true,
+			Desc: "Internet file retriever", Homepage: "https://www.gnu.org/software/wget/"},
+		{Name: "openssl@3", Version: "3.3.2", InstallReason: InstallReasonDependency, Tap: "homebrew/core", Pinned: true,
+			KegOnly: true},
+		{Name: "libidn2", Version: "2.3.7", InstallReason: InstallReasonDependency, Tap: "homebrew/core", Linked: true},
+		{Name: "ca-certificates", Version: "2024-07-02", InstallReason: InstallReasonDependency, Tap: "homebrew/core",
+			Linked: true},
+		{Name: "widget", Version: "0.3.0", InstallReason: InstallReasonOnRequest, Tap: "acme/tools", Linked: true},
+	}
+	if !reflect.DeepEqual(inventory.Formulae, wantFormulae) {
+		t.Fatalf("formulae:\n got %+v\nwant %+v", inventory.Formulae, wantFormulae)
+	}
+
+	wantCasks := []InstalledCask{
+		{Name: "firefox", Version: "131.0", Tap: "homebrew/cask", Desc: "Web browser", Homepage: "https://www.mozilla.org/firefox/"},
+		{Name: "iterm2", Version: "Unknown", Tap: "homebrew/cask"},
+	}
+	if !reflect.DeepEqual(inventory.Casks, wantCasks) {
+		t.Fatalf("casks:\n got %+v\nwant %+v", inventory.Casks, wantCasks)
+	}
+
+	if want := []string{"acme/tools/widget", "wget"}; !reflect.DeepEqual(inventory.Leaves, want) {
+		t.Fatalf("leaves = %v, want %v", inventory.Leaves, want)
+	}
+}
+
+func TestGetStartupData_readsInstalledStateOnce(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedInventory}).
+		script("outdated --json=v2", brewRecording{stdout: "[]"}).
+		script("tap", brewRecording{stdout: "acme/tools\n"})
+	service, _ := newFakeService(fb)
+
+	data := service.GetStartupData()
+
+	if fb.invoked("info --json=v2 --installed") != 1 {
+		t.Fatalf("expected a single inventory read, calls: %v", fb.calls)
+	}
+	for _, call := range fb.calls {
+		if call == "leaves" || strings.HasPrefix(call, "list ") || strings.HasPrefix(call, "info --cask") {
+			t.Fatalf("startup must not issue %q, calls: %v", call, fb.calls)
+		}
+	}
+	if len(data.Packages) != 5 || len(data.Casks) != 2 || len(data.Leaves) != 2 || len(data.Taps) != 1 {
+		t.Fatalf("unexpected startup data: %+v", data)
+	}
+}
+
+func TestGetStartupData_inventoryFailureMarksInstalledSections(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stderr: "Error: Homebrew is locked.\n", exitCode: 1}).
+		script("outdated --json=v2", brewRecording{stdout: "[]"})
+	service, _ := newFakeService(fb)
+
+	data := service.GetStartupData()
+
+	if len(data.Packages) != 1 || data.Packages[0][0] != "Error" {
+		t.Fatalf("packages = %v", data.Packages)
+	}
+	if len(data.Casks) != 1 || data.Casks[0][0] != "Error" {
+		t.Fatalf("casks = %v", data.Casks)
+	}
+	if len(data.Leaves) != 1 || !strings.HasPrefix(data.Leaves[0], "Error: ") {
+		t.Fatalf("leaves = %v", data.Leaves)
+	}
+}
@@ -0,0 +1,365 @@
+package brew
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalEntry is one mutating operation as it ran: what was asked for, the
+// brew commands it ran, and the package versions it changed.
+type JournalEntry struct {
+	ID         string           `json:"id"`
+	Kind       string           `json:"kind"`
+	Target     string           `json:"target"`
+	User       string           `json:"user"`
+	StartedAt  time.Time        `json:"startedAt"`
+	DurationMs int64            `json:"durationMs"`
+	Success    bool             `json:"success"`
+	Result     string           `json:"result"`
+	Commands   []JournalCommand `json:"commands"`
+	Changes    []JournalChange  `json:"changes"`
+	// Inverse is set on successful operations that can be undone.
+	Inverse *JournalInverse `json:"inverse,omitempty"`
+	// UndoOf is the ID of the entry an undo operation reversed.
+	UndoOf string `json:"undoOf,omitempty"`
+}
+
+// JournalCommand is one brew invocation. ExitCode is -1 when the command was
+// cancelled or could not be started.
+type JournalCommand struct {
+	Args       []string `json:"args"`
+	ExitCode   int      `json:"exitCode"`
+	DurationMs int64    `json:"durationMs"`
+}
+
+// JournalChange is an installed version that changed. From is empty for a
+// package that was installed, To for one that was removed.
+type JournalChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// JournalFilter selects journal entries. Package matches the operation
+// target and every package whose version changed, by short or full name.
+type JournalFilter struct {
+	Package string `json:"package"`
+	Kind    string `json:"kind"`
+	Offset  int    `json:"offset"`
+	Limit   int    `json:"limit"`
+}
+
+// JournalPage is one page of matching entries, newest first, with the total
+// number of matches.
+type JournalPage struct {
+	Entries []JournalEntry `json:"entries"`
+	Total   int            `json:"total"`
+}
+
+const (
+	defaultJournalPageSize = 50
+	maxJournalPageSize     = 500
+)
+
+// OperationJournal is an append-only JSONL file of every operation that went
+// through the queue. Unlike the session log it survives restarts.
+type OperationJournal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewOperationJournal creates a journal stored at path.
+func NewOperationJournal(path string) *OperationJournal {
+	return &OperationJournal{path: path}
+}
+
+// Append writes entry as one line at the end of the journal.
+func (j *OperationJournal) Append(entry JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query returns the page of entries filter selects. Lines that cannot be
+// parsed, such as one cut short by a crash, are skipped.
+func (j *OperationJournal) Query(filter JournalFilter) (*JournalPage, error) {
+	entries, err := j.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []JournalEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if filter.matches(entries[i]) {
+			matches = append(matches, entries[i])
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultJournalPageSize
+	}
+	limit = min(limit, maxJournalPageSize)
+	start := min(max(filter.Offset, 0), len(matches))
+	end := min(start+limit, len(matches))
+	return &JournalPage{Entries: matches[start:end], Total: len(matches)}, nil
+}
+
+func (j *OperationJournal) entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (f JournalFilter) matches(entry JournalEntry) bool {
+	if f.Kind != "" && entry.Kind != f.Kind {
+		return false
+	}
+	if f.Package == "" {
+		return true
+	}
+	for _, name := range strings.Fields(entry.Target) {
+		if samePackage(name, f.Package) {
+			return true
+		}
+	}
+	for _, change := range entry.Changes {
+		if samePackage(change.Name, f.Package) {
+			return true
+		}
+	}
+	return false
+}
+
+// samePackage compares a full name against a short or full one.
+func samePackage(fullName, name string) bool {
+	return fullName == name || fullName[strings.LastIndex(fullName, "/")+1:] == name
+}
+
+// containsPackage reports whether names holds name, qualified or not.
+func containsPackage(names []string, name string) bool {
+	for _, n := range names {
+		if samePackage(n, name) || samePackage(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// journalChanges lists the packages whose version differs between before and
+// after, sorted by name.
+func journalChanges(before, after map[string]string) []JournalChange {
+	changes := []JournalChange{}
+	for name, from := range before {
+		if to := after[name]; to != from {
+			changes = append(changes, JournalChange{Name: name, From: from, To: to})
+		}
+	}
+	for name, to := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, JournalChange{Name: name, To: to})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// journalUser is who the journal says ran an operation.
+func journalUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// journalRecorder collects the commands an operation runs. It travels in the
+// operation's context, so commands run outside the queue are not attributed
+// to it.
+type journalRecorder struct {
+	mu       sync.Mutex
+	commands []JournalCommand
+}
+
+type journalRecorderKey struct{}
+
+func withJournalRecorder(ctx context.Context, recorder *journalRecorder) context.Context {
+	return context.WithValue(ctx, journalRecorderKey{}, recorder)
+}
+
+// recordCommand adds a finished command to the journal entry of the
+// operation ctx belongs to, if any.
+func recordCommand(ctx context.Context, args []string, started time.Time, err error) {
+	recorder, ok := ctx.Value(journalRecorderKey{}).(*journalRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.commands = append(recorder.commands, JournalCommand{
+		Args:       append([]string(nil), args...),
+		ExitCode:   exitCode(err),
+		DurationMs: time.Since(started).Milliseconds(),
+	})
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	var code int
+	if _, scanErr := fmt.Sscanf(err.Error(), "exit status %d", &code); scanErr == nil {
+		return code
+	}
+	return -1
+}
+
+// runRecorded runs a buffered mutating command and records it for the
+// journal. Streamed commands are recorded by journaledRunner.
+func runRecorded(ctx context.Context, runner Runner, args ...string) ([]byte, error) {
+	started := time.Now()
+	output, err := runner.RunNoCache(args...)
+	recordCommand(ctx, args, started, err)
+	return output, err
+}
+
+// journaledRunner records every streamed command for the journal.
+type journaledRunner struct {
+	Runner
+}
+
+func (r journaledRunner) Stream(ctx context.Context, args []string, onStdout, onStderr func(line string)) (streamPhase, string, error) {
+	started := time.Now()
+	phase, stderrText, err := r.Runner.Stream(ctx, args, onStdout, onStderr)
+	recorded := err
+	if phase == phaseCancelled {
+		recorded = context.Canceled
+	}
+	recordCommand(ctx, args, started, recorded)
+	return phase, stderrText, err
+}
+
+// journaled wraps a queued operation so that, once it has run, it is written
+// to the journal with the commands it ran, the versions it changed and, when
+// it succeeded and can be reversed, its inverse.
+func (s *serviceImpl) journaled(kind, target string, fn func(context.Context) (string, bool)) func(context.Context) (string, bool) {
+	return func(ctx context.Context) (string, bool) {
+		recorder := &journalRecorder{}
+		before := s.journalInventory()
+		inverse := s.plannedInverse(kind, target, before)
+		started := time.Now()
+
+		result, ok := fn(withJournalRecorder(ctx, recorder))
+
+		entry := JournalEntry{
+			ID:         strconv.FormatInt(started.UnixNano(), 10),
+			Kind:       kind,
+			Target:     target,
+			User:       journalUser(),
+			StartedAt:  started.UTC(),
+			DurationMs: time.Since(started).Milliseconds(),
+			Result:     result,
+			Success:    ok,
+			Commands:   recorder.commands,
+			Changes:    []JournalChange{},
+			UndoOf:     undoneEntry(ctx),
+		}
+		if entry.Commands == nil {
+			entry.Commands = []JournalCommand{}
+		}
+		if after := s.journalInventory(); before != nil && after != nil {
+			entry.Changes = journalChanges(installedVersions(before), installedVersions(after))
+		}
+		if ok {
+			entry.Inverse = inverse
+		}
+
+		if err := s.journal.Append(entry); err != nil {
+			s.logFunc(fmt.Sprintf("Failed to write the operation journal: %v", err))
+		}
+		return result, ok
+	}
+}
+
+// journalInventory reads the installed packages bypassing the command cache,
+// or returns nil when they cannot be read.
+func (s *serviceImpl) journalInventory() *Inventory {
+	s.runner.ClearCache()
+	inventory, err := s.listService.LoadInventory()
+	if err != nil {
+		s.logFunc(fmt.Sprintf("Operation journal could not read installed versions: %v", err))
+		return nil
+	}
+	return inventory
+}
+
+// installedVersions maps every installed formula and cask to its version.
+func installedVersions(inventory *Inventory) map[string]string {
+	versions := make(map[string]string, len(inventory.Formulae)+len(inventory.Casks))
+	for _, formula := range inventory.Formulae {
+		versions[qualifiedName(formula.Name, formula.Tap)] = formula.Version
+	}
+	for _, cask := range inventory.Casks {
+		versions[qualifiedName(cask.Name, cask.Tap)] = cask.Version
+	}
+	return versions
+}
+
+// GetOperationHistory pages through the operation journal.
+func (s *serviceImpl) GetOperationHistory(filter JournalFilter) (*JournalPage, error) {
+	if s.journal == nil {
+		return &JournalPage{Entries: []JournalEntry{}}, nil
+	}
+	return s.journal.Query(filter)
+}
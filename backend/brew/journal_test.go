@@ -0,0 +1,137 @@
+package brew
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOperationJournal_Query(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operation-journal.jsonl")
+	journal := NewOperationJournal(path)
+	started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	entries := []JournalEntry{
+		{ID: "1", Kind: "install", Target: "jq", StartedAt: started},
+		{ID: "2", Kind: "upgrade-selected", Target: "git acme/tools/widget", StartedAt: started.Add(time.Minute)},
+		{ID: "3", Kind: "upgrade-all", StartedAt: started.Add(2 * time.Minute),
+			Changes: []JournalChange{{Name: "jq", From: "1.6", To: "1.7"}}},
+		{ID: "4", Kind: "install", Target: "wget", StartedAt: started.Add(3 * time.Minute)},
+	}
+	for i, entry := range entries {
+		if err := journal.Append(entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if i == 1 {
+			// A line cut short by a crash must not hide the rest.
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(`{"id": "broken", "kind":` + "\n")
+			f.Close()
+		}
+	}
+
+	ids := func(page *JournalPage) []string {
+		var got []string
+		for _, entry := range page.Entries {
+			got = append(got, entry.ID)
+		}
+		return got
+	}
+	tests := []struct {
+		name      string
+		filter    JournalFilter
+		wantIDs   []string
+		wantTotal int
+	}{
+		{"everything newest first", JournalFilter{}, []string{"4", "3", "2", "1"}, 4},
+		{"by kind", JournalFilter{Kind: "install"}, []string{"4", "1"}, 2},
+		{"by target or changed package", JournalFilter{Package: "jq"}, []string{"3", "1"}, 2},
+		{"by short name of a tapped package", JournalFilter{Package: "widget"}, []string{"2"}, 1},
+		{"paged", JournalFilter{Offset: 1, Limit: 2}, []string{"3", "2"}, 4},
+		{"past the end", JournalFilter{Offset: 10}, nil, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := journal.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if got := ids(page); !reflect.DeepEqual(got, tt.wantIDs) || page.Total != tt.wantTotal {
+				t.Errorf("Query = %v (total %d), want %v (total %d)", got, page.Total, tt.wantIDs, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestOperationJournal_QueryMissingFile(t *testing.T) {
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "missing", "operation-journal.jsonl"))
+	page, err := journal.Query(JournalFilter{})
+	if err != nil || page.Total != 0 || len(page.Entries) != 0 {
+		t.Fatalf("Query = %+v, %v, want an empty page", page, err)
+	}
+}
+
+func TestServiceJournalsOperations(t *testing.T) {
+	before := `{"formulae": [{"name": "jq", "full_name": "jq", "tap": "homebrew/core",
+		"installed": [{"version": "1.7", "installed_on_request": true}]}], "casks": []}`
+	after := `{"formulae": [{"name": "jq", "full_name": "jq", "tap": "homebrew/core",
+		"installed": [{"version": "1.7", "installed_on_request": true}]},
+		{"name": "wget", "full_name": "wget", "tap": "homebrew/core",
+		"installed": [{"version": "1.24.5", "installed_on_request": true}]}], "casks": []}`
+	fb := newFakeBrew().
+		script("info --json=v2 --installed",
+			brewRecording{stdout: before}, brewRecording{stdout: after}, brewRecording{stdout: after}).
+		script("install wget", brewRecording{stdout: "==> Pouring wget"}).
+		script("pin wget", brewRecording{stderr: "Error: wget is not installed", exitCode: 1})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	service.InstallBrewPackage(context.Background(), "wget")
+	service.PinBrewPackage(context.Background(), "wget")
+
+	page, err := service.GetOperationHistory(JournalFilter{Package: "wget"})
+	if err != nil || page.Total != 2 {
+		t.Fatalf("GetOperationHistory = %+v, %v", page, err)
+	}
+	pin, install := page.Entries[0], page.Entries[1]
+
+	if install.Kind != "install" || !install.Success || install.User == "" {
+		t.Errorf("install entry = %+v", install)
+	}
+	if len(install.Commands) != 1 || !reflect.DeepEqual(install.Commands[0].Args, BuildInstallArgs("wget")) ||
+		install.Commands[0].ExitCode != 0 {
+		t.Errorf("install commands = %+v, want one successful brew install", install.Commands)
+	}
+	if want := []JournalChange{{Name: "wget", To: "1.24.5"}}; !reflect.DeepEqual(install.Changes, want) {
+		t.Errorf("install changes = %+v, want %+v", install.Changes, want)
+	}
+
+	if pin.Success || len(pin.Commands) != 1 || pin.Commands[0].ExitCode != 1 || len(pin.Changes) != 0 {
+		t.Errorf("pin entry = %+v, want one failed command and no changes", pin)
+	}
+}
+
+func TestServiceJournalsRefusedOperationAsFailed(t *testing.T) {
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: `{"formulae": [], "casks": []}`})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	// Nothing runs, so only the reported outcome tells that it failed.
+	if got := service.InstallBrewPackageVersion(context.Background(), "wget", "not a version", false); got != "backend.installVersion.invalid" {
+		t.Fatalf("result = %q", got)
+	}
+
+	page, err := service.GetOperationHistory(JournalFilter{})
+	if err != nil || page.Total != 1 {
+		t.Fatalf("GetOperationHistory = %+v, %v", page, err)
+	}
+	if entry := page.Entries[0]; entry.Success || len(entry.Commands) != 0 {
+		t.Errorf("entry = %+v, want a failure without commands", entry)
+	}
+}
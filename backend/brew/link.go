@@ -0,0 +1,143 @@
+package brew
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LinkPreview is what `brew link` would do for a formula, read from its
+// --dry-run output before anything is touched.
+type LinkPreview struct {
+	Name string `json:"name"`
+	// Links are the files the link would create.
+	Links []string `json:"links"`
+	// Conflicts are existing files in the way. A plain link fails on them;
+	// linking with overwrite deletes them.
+	Conflicts     []string `json:"conflicts"`
+	KegOnly       bool     `json:"kegOnly"`
+	AlreadyLinked bool     `json:"alreadyLinked"`
+	// Command and OverwriteCommand are the exact commands the two ways of
+	// linking run.
+	Command          string `json:"command"`
+	OverwriteCommand string `json:"overwriteCommand"`
+}
+
+// ReinstallBrewPackage reinstalls a package with live progress on
+// "packageReinstallProgress" and the result on "packageReinstallComplete".
+func (s *ActionsService) ReinstallBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.reinstallPackage(ctx, packageName)
+	return result
+}
+
+// reinstallPackage is ReinstallBrewPackage also reporting whether it
+// succeeded.
+func (s *ActionsService) reinstallPackage(ctx context.Context, packageName string) (string, bool) {
+	params := map[string]string{"name": packageName}
+	s.eventEmitter.Emit("packageReinstallProgress", s.getBackendMsg("backend.reinstall.start", params))
+
+	result, ok := s.streamAction(ctx, BuildReinstallArgs(packageName), "packageReinstallProgress", "backend.reinstall", params)
+	if ok && s.isPackageCask(packageName) {
+		s.postInstallCask(packageName, "packageReinstallProgress")
+	}
+	s.eventEmitter.Emit("packageReinstallComplete", result)
+	return result, ok
+}
+
+// LinkBrewPackage symlinks a formula into the prefix with live progress on
+// "packageLinkProgress" and the result on "packageLinkComplete".
+func (s *ActionsService) LinkBrewPackage(ctx context.Context, packageName string, overwrite, force bool) string {
+	result, _ := s.linkPackage(ctx, packageName, overwrite, force)
+	return result
+}
+
+// linkPackage is LinkBrewPackage also reporting whether it succeeded.
+func (s *ActionsService) linkPackage(ctx context.Context, packageName string, overwrite, force bool) (string, bool) {
+	params := map[string]string{"name": packageName}
+	s.eventEmitter.Emit("packageLinkProgress", s.getBackendMsg("backend.link.start", params))
+
+	result, ok := s.streamAction(ctx, BuildLinkArgs(packageName, overwrite, force), "packageLinkProgress", "backend.link", params)
+	s.eventEmitter.Emit("packageLinkComplete", result)
+	return result, ok
+}
+
+// UnlinkBrewPackage removes a formula's symlinks from the prefix, reporting
+// through the same events as LinkBrewPackage.
+func (s *ActionsService) UnlinkBrewPackage(ctx context.Context, packageName string) string {
+	result, _ := s.unlinkPackage(ctx, packageName)
+	return result
+}
+
+// unlinkPackage is UnlinkBrewPackage also reporting whether it succeeded.
+func (s *ActionsService) unlinkPackage(ctx context.Context, packageName string) (string, bool) {
+	params := map[string]string{"name": packageName}
+	s.eventEmitter.Emit("packageLinkProgress", s.getBackendMsg("backend.unlink.start", params))
+
+	result, ok := s.streamAction(ctx, BuildUnlinkArgs(packageName), "packageLinkProgress", "backend.unlink", params)
+	s.eventEmitter.Emit("packageLinkComplete", result)
+	return result, ok
+}
+
+// streamAction runs args, streaming output to event, and returns the
+// <msgPrefix>.success or .failed message, which it also emits on event.
+func (s *ActionsService) streamAction(ctx context.Context, args []string, event, msgPrefix string, params map[string]string) (string, bool) {
+	phase, _, err := s.runner.Stream(ctx, args,
+		func(line string) { s.eventEmitter.Emit(event, fmt.Sprintf("📦 %s", line)) },
+		func(line string) { s.eventEmitter.Emit(event, fmt.Sprintf("⚠️ %s", line)) },
+	)
+
+	var message string
+	switch phase {
+	case phaseNone:
+		message = s.getBackendMsg(msgPrefix+".success", params)
+	case phaseCancelled:
+		message = s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+	default:
+		message = s.getBackendMsg(msgPrefix+".failed", map[string]string{"name": params["name"], "error": err.Error()})
+	}
+	s.eventEmitter.Emit(event, message)
+	return message, phase == phaseNone
+}
+
+// PreviewLink runs `brew link --dry-run` for a formula, once plainly to list
+// the links and once with --overwrite to list the files in the way. Keg-only
+// formulae are previewed with --force, which linking them requires.
+func (s *ActionsService) PreviewLink(packageName string) (*LinkPreview, error) {
+	preview := &LinkPreview{Name: packageName, Links: []string{}, Conflicts: []string{}}
+
+	output, err := s.runner.RunNoCache(BuildDryRunArgs(BuildLinkArgs(packageName, false, false))...)
+	if strings.Contains(string(output), "keg-only") {
+		preview.KegOnly = true
+		output, err = s.runner.RunNoCache(BuildDryRunArgs(BuildLinkArgs(packageName, false, true))...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("brew link --dry-run failed: %v", err)
+	}
+	preview.Links = parseLinkDryRun(string(output))
+	preview.AlreadyLinked = strings.Contains(string(output), "Already linked")
+
+	overwriteArgs := BuildLinkArgs(packageName, true, preview.KegOnly)
+	output, err = s.runner.RunNoCache(BuildDryRunArgs(overwriteArgs)...)
+	if err != nil {
+		return nil, fmt.Errorf("brew link --dry-run --overwrite failed: %v", err)
+	}
+	preview.Conflicts = parseLinkDryRun(string(output))
+
+	preview.Command = FormatCommand(BuildLinkArgs(packageName, false, preview.KegOnly))
+	preview.OverwriteCommand = FormatCommand(overwriteArgs)
+	return preview, nil
+}
+
+// parseLinkDryRun returns the paths listed by `brew link --dry-run`, which
+// prints a "Would link:" or "Would remove:" header followed by one path per
+// line.
+func parseLinkDryRun(output string) []string {
+	paths := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "/") {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,62 @@
+package brew
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPreviewLink(t *testing.T) {
+	fb := newFakeBrew().
+		script("link --dry-run node@20", brewRecording{
+			stderr: "Warning: node@20 is keg-only and must be linked with `--force`.\n",
+		}).
+		script("link --dry-run --force node@20", brewRecording{
+			stdout: "Would link:\n/opt/homebrew/bin/node\n/opt/homebrew/bin/npm\n",
+		}).
+		script("link --dry-run --overwrite --force node@20", brewRecording{
+			stdout: "Would remove:\n/opt/homebrew/bin/npm\n",
+		})
+	service, _ := newFakeService(fb)
+
+	preview, err := service.PreviewLink("node@20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !preview.KegOnly || preview.AlreadyLinked {
+		t.Errorf("preview = %+v, want a keg-only formula that is not linked", preview)
+	}
+	if want := []string{"/opt/homebrew/bin/node", "/opt/homebrew/bin/npm"}; !reflect.DeepEqual(preview.Links, want) {
+		t.Errorf("Links = %v, want %v", preview.Links, want)
+	}
+	if want := []string{"/opt/homebrew/bin/npm"}; !reflect.DeepEqual(preview.Conflicts, want) {
+		t.Errorf("Conflicts = %v, want %v", preview.Conflicts, want)
+	}
+	if preview.Command != "brew link --force node@20" || preview.OverwriteCommand != "brew link --overwrite --force node@20" {
+		t.Errorf("commands = %q, %q", preview.Command, preview.OverwriteCommand)
+	}
+}
+
+func TestLinkActions(t *testing.T) {
+	fb := newFakeBrew().
+		script("reinstall wget", brewRecording{stdout: "==> Pouring wget"}).
+		script("unlink python@3.11", brewRecording{stdout: "Unlinking /opt/homebrew/Cellar/python@3.11/3.11.10... 24 symlinks removed."}).
+		script("link --overwrite python@3.12", brewRecording{stderr: "Error: No such keg", exitCode: 1})
+	service, emitter := newFakeService(fb)
+
+	if got := service.ReinstallBrewPackage(context.Background(), "wget"); got != "backend.reinstall.success" {
+		t.Errorf("reinstall = %q", got)
+	}
+	if !emitter.has("packageReinstallProgress", "📦 ==> Pouring wget") || emitter.last("packageReinstallComplete") != "backend.reinstall.success" {
+		t.Error("expected reinstall output to stream")
+	}
+	if got := service.UnlinkBrewPackage(context.Background(), "python@3.11"); got != "backend.unlink.success" {
+		t.Errorf("unlink = %q", got)
+	}
+	if got := service.LinkBrewPackage(context.Background(), "python@3.12", true, false); got != "backend.link.failed" {
+		t.Errorf("link = %q", got)
+	}
+	if emitter.count("packageLinkComplete") != 2 {
+		t.Error("expected link and unlink to complete on packageLinkComplete")
+	}
+}
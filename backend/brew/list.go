@@ -102,6 +102,7 @@ type ListService struct {
 	unlockKnown   func()
 	logFunc       func(string)
 	onFailure     func(string)
+	graph         graphCache
 }
 
 // NewListService creates a new list service. onFailure receives the diagnostic
@@ -162,229 +163,22 @@ func (s *ListService) GetAllBrewPackages() [][]string {
 	return results
 }
 
-// GetBrewPackages retrieves the list of installed Homebrew packages
-func (s *ListService) GetBrewPackages() [][]string {
-	// Validate brew installation first
-	if err := s.validateFunc(); err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Homebrew validation failed: %v", err)}}
-	}
-
-	output, err := s.executor.RunStdoutOnly("list", "--formula", "--versions")
-	if err != nil {
-		s.reportFailure(err)
-		return [][]string{{"Error", fmt.Sprintf("Failed to fetch installed packages: %v", err)}}
-	}
-
-	outputStr := strings.TrimSpace(string(output))
-	if outputStr == "" {
-		return [][]string{}
-	}
-
-	lines := strings.Split(outputStr, "\n")
-	var packageNames []string
-	packageVersions := make(map[string]string)
-	installReasonByName := make(map[string]string)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || isBrewDiagnosticLine(line) {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			packageNames = append(packageNames, parts[0])
-			packageVersions[parts[0]] = parts[1]
-		} else if len(parts) == 1 {
-			packageNames = append(packageNames, parts[0])
-			packageVersions[parts[0]] = "Unknown"
-		}
-	}
-
-	// Resolve install origin (on request vs as dependency) from Homebrew JSON metadata.
-	infoOutput, infoErr := s.executor.RunStdoutOnly("info", "--json=v2", "--formula", "--installed")
-	if infoErr == nil {
-		var info struct {
-			Formulae []struct {
-				Name      string `json:"name"`
-				Installed []struct {
-					InstalledOnRequest    bool `json:"installed_on_request"`
-					InstalledAsDependency bool `json:"installed_as_dependency"`
-				} `json:"installed"`
-			} `json:"formulae"`
-		}
-
-		if err := json.Unmarshal(infoOutput, &info); err != nil {
-			// Not fatal: the list still renders, but every package falls back to
-			// an "unknown" origin. Log it so that degradation is traceable.
-			s.log(fmt.Sprintf("Failed to parse install-reason metadata from brew info: %v", err))
-		} else {
-			for _, f := range info.Formulae {
-				reason := "unknown"
-				if len(f.Installed) > 0 {
-					switch {
-					case f.Installed[0].InstalledOnRequest:
-						reason = "on_request"
-					case f.Installed[0].InstalledAsDependency:
-						reason = "dependency"
-					}
-				}
-				installReasonByName[f.Name] = reason
-			}
-		}
-	}
-
-	// Build result with name, version, and empty size (lazy loaded)
-	var packages [][]string
-	for _, name := range packageNames {
-		version := packageVersions[name]
-		reason := installReasonByName[name]
-		if reason == "" {
-			reason = "unknown"
-		}
-		packages = append(packages, []string{name, version, "", reason})
-	}
-
-	return packages
-}
-
-// extractCaskInstalledVersion reads the installed version from brew info JSON.
-// The field may be a version string, an array of strings, or null.
-func extractCaskInstalledVersion(installed json.RawMessage) string {
-	if len(installed) == 0 || string(installed) == "null" {
-		return ""
-	}
-
-	var version string
-	if err := json.Unmarshal(installed, &version); err == nil && version != "" {
-		return version
-	}
-
-	var versions []string
-	if err := json.Unmarshal(installed, &versions); err == nil && len(versions) > 0 {
-		return versions[0]
-	}
-
-	return ""
-}
-
-func (s *ListService) fillCaskInstalledVersions(caskNames []string, versionMap map[string]string) {
-	args := []string{"info", "--cask", "--json=v2"}
-	args = append(args, caskNames...)
-
-	infoOutput, err := s.executor.RunStdoutOnly(args...)
-	if err != nil {
-		for _, caskName := range caskNames {
-			s.fillSingleCaskInstalledVersion(caskName, versionMap)
-		}
-		return
-	}
-
-	var caskInfo struct {
-		Casks []struct {
-			Token     string          `json:"token"`
-			Installed json.RawMessage `json:"installed"`
-		} `json:"casks"`
-	}
-
-	if err := json.Unmarshal(infoOutput, &caskInfo); err != nil {
-		for _, caskName := range caskNames {
-			s.fillSingleCaskInstalledVersion(caskName, versionMap)
-		}
-		return
-	}
-
-	for _, cask := range caskInfo.Casks {
-		if version := extractCaskInstalledVersion(cask.Installed); version != "" {
-			versionMap[cask.Token] = version
-		}
-	}
-}
-
-func (s *ListService) fillSingleCaskInstalledVersion(caskName string, versionMap map[string]string) {
-	infoOutput, err := s.executor.RunStdoutOnly("info", "--cask", "--json=v2", caskName)
+// InstalledFormulae retrieves the installed Homebrew formulae
+func (s *ListService) InstalledFormulae() ([]InstalledFormula, error) {
+	inventory, err := s.LoadInventory()
 	if err != nil {
-		return
-	}
-
-	var caskInfo struct {
-		Casks []struct {
-			Installed json.RawMessage `json:"installed"`
-		} `json:"casks"`
-	}
-
-	if err := json.Unmarshal(infoOutput, &caskInfo); err == nil && len(caskInfo.Casks) > 0 {
-		if version := extractCaskInstalledVersion(caskInfo.Casks[0].Installed); version != "" {
-			versionMap[caskName] = version
-		}
+		return nil, err
 	}
+	return inventory.Formulae, nil
 }
 
-// GetBrewCasks retrieves the list of installed Homebrew casks
-func (s *ListService) GetBrewCasks() [][]string {
-	// Validate brew installation first
-	if err := s.validateFunc(); err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Homebrew validation failed: %v", err)}}
-	}
-
-	output, err := s.executor.RunStdoutOnly("list", "--cask", "--versions")
+// InstalledCasks retrieves the installed Homebrew casks
+func (s *ListService) InstalledCasks() ([]InstalledCask, error) {
+	inventory, err := s.LoadInventory()
 	if err != nil {
-		s.reportFailure(err)
-		if IsBrokenRubyStateError(err.Error()) {
-			return [][]string{{"Error", fmt.Sprintf("Failed to fetch installed casks: %v\n\n%s", err, BrokenRubyStateRemedy)}}
-		}
-		return [][]string{{"Error", fmt.Sprintf("Failed to fetch installed casks: %v", err)}}
-	}
-
-	outputStr := strings.TrimSpace(string(output))
-	if outputStr == "" {
-		return [][]string{}
-	}
-
-	lines := strings.Split(outputStr, "\n")
-	var caskNames []string
-	versionMap := make(map[string]string)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || isBrewDiagnosticLine(line) {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			caskNames = append(caskNames, parts[0])
-			versionMap[parts[0]] = parts[1]
-		} else if len(parts) == 1 && isPackageNameLine(parts[0]) {
-			caskNames = append(caskNames, parts[0])
-			versionMap[parts[0]] = ""
-		}
-	}
-
-	if len(caskNames) == 0 {
-		return [][]string{}
-	}
-
-	var missingVersion []string
-	for _, name := range caskNames {
-		if versionMap[name] == "" {
-			missingVersion = append(missingVersion, name)
-		}
-	}
-	if len(missingVersion) > 0 {
-		s.fillCaskInstalledVersions(missingVersion, versionMap)
-	}
-
-	var casks [][]string
-	for _, name := range caskNames {
-		version := versionMap[name]
-		if version == "" {
-			version = "Unknown"
-		}
-		casks = append(casks, []string{name, version, ""})
+		return nil, err
 	}
-
-	return casks
+	return inventory.Casks, nil
 }
 
 // GetAllBrewCasks retrieves all available brew casks
@@ -392,63 +186,64 @@ func (s *ListService) GetAllBrewCasks() [][]string {
 	return s.fetchCatalogNames("casks", "casks")
 }
 
-// GetBrewLeaves retrieves the list of leaf packages
-func (s *ListService) GetBrewLeaves() []string {
-	output, err := s.executor.RunWithTimeoutStdoutOnly(90*time.Second, "leaves")
+// Leaves retrieves the installed formulae nothing else depends on
+func (s *ListService) Leaves() ([]string, error) {
+	graph, err := s.DependencyGraph()
 	if err != nil {
-		return []string{fmt.Sprintf("Error: %v", err)}
+		return nil, err
 	}
+	return graph.Leaves(), nil
+}
 
-	outputStr := strings.TrimSpace(string(output))
-	if outputStr == "" {
-		return []string{}
+// DependencyGraph returns the dependency graph of everything installed. The
+// graph from the last inventory load is reused until
+// InvalidateDependencyGraph is called.
+func (s *ListService) DependencyGraph() (*DependencyGraph, error) {
+	if graph, _ := s.graph.get(); graph != nil {
+		return graph, nil
 	}
-
-	lines := strings.Split(outputStr, "\n")
-	var results []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if isPackageNameLine(line) {
-			results = append(results, line)
-		}
+	inventory, err := s.LoadInventory()
+	if err != nil {
+		return nil, err
 	}
+	return inventory.graph, nil
+}
 
-	return results
+// InvalidateDependencyGraph drops the cached dependency graph after installed
+// state has changed.
+func (s *ListService) InvalidateDependencyGraph() {
+	s.graph.invalidate()
 }
 
-// GetBrewTaps retrieves the list of tapped repositories
-func (s *ListService) GetBrewTaps() [][]string {
+// Taps retrieves the tapped repositories
+func (s *ListService) Taps() ([]Tap, error) {
 	output, err := s.executor.RunStdoutOnly("tap")
 	if err != nil {
 		s.reportFailure(err)
-		return [][]string{{"Error", fmt.Sprintf("Failed to fetch repositories: %v", err)}}
+		return nil, fmt.Errorf("Failed to fetch repositories: %v", err)
 	}
 
 	outputStr := strings.TrimSpace(string(output))
 	if outputStr == "" {
-		return [][]string{}
+		return []Tap{}, nil
 	}
 
 	trustMap := s.getTapTrustMap()
 
 	lines := strings.Split(outputStr, "\n")
-	var taps [][]string
+	taps := make([]Tap, 0, len(lines))
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if isPackageNameLine(line) {
-			trusted := ""
+			tap := Tap{Name: line, Managed: line == LocalTapName}
 			if v, ok := trustMap[line]; ok {
-				if v {
-					trusted = "true"
-				} else {
-					trusted = "false"
-				}
+				tap.Trusted = &v
 			}
-			taps = append(taps, []string{line, "Active", trusted})
+			taps = append(taps, tap)
 		}
 	}
 
-	return taps
+	return taps, nil
 }
 
 // getTapTrustMap returns a map of tap name -> trusted state using the Homebrew 6
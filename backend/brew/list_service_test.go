@@ -46,27 +46,29 @@ func (f *fakeRunner) RunWithTimeoutStdoutOnly(_ time.Duration, args ...string) (
 // still producing valid JSON on stdout. Reading the install reason through a
 // combined-output call puts "Warning: ..." in front of the JSON, so the parse
 // fails and every package silently reports its origin as "unknown".
-func TestGetBrewPackages_installReasonSurvivesStderrWarnings(t *testing.T) {
-	const infoJSON = `{"formulae":[{"name":"wget","installed":[{"installed_on_request":true}]}]}`
+func TestInstalledFormulae_installReasonSurvivesStderrWarnings(t *testing.T) {
+	const infoJSON = `{"formulae":[{"name":"wget","installed":[{"version":"1.21","installed_on_request":true}]}],"casks":[]}`
 
 	runner := &fakeRunner{
 		stdout: map[string]string{
-			"list --formula --versions":            "wget 1.21\n",
-			"info --json=v2 --formula --installed": infoJSON,
+			"info --json=v2 --installed": infoJSON,
 		},
 		stderr: map[string]string{
-			"info --json=v2 --formula --installed": "Warning: Calling `depends_on :macos` is deprecated!\n",
+			"info --json=v2 --installed": "Warning: Calling `depends_on :macos` is deprecated!\n",
 		},
 	}
 
 	service := newTestListService(runner, nil)
 
-	packages := service.GetBrewPackages()
+	packages, err := service.InstalledFormulae()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if len(packages) != 1 {
 		t.Fatalf("expected 1 package, got %v", packages)
 	}
-	if reason := packages[0][3]; reason != "on_request" {
+	if reason := packages[0].InstallReason; reason != "on_request" {
 		t.Fatalf("expected install reason %q, got %q", "on_request", reason)
 	}
 }
@@ -92,12 +94,12 @@ func newTestListServiceWithFailureHook(runner commandRunner, logFunc func(string
 // Homebrew names the untrusted tap and the exact recovery command in its
 // diagnostic. Read paths must hand that text to the failure hook so the UI can
 // offer the trust action that already exists for install and tap flows.
-func TestGetBrewCasks_reportsDiagnosticWhenTapIsUntrusted(t *testing.T) {
+func TestInstalledCasks_reportsDiagnosticWhenTapIsUntrusted(t *testing.T) {
 	const diagnostic = "exit status 1: Error: Refusing to load cask " +
 		"macos-fuse-t/cask/fuse-t-sshfs from untrusted tap macos-fuse-t/cask."
 
 	runner := &fakeRunner{
-		errs: map[string]error{"list --cask --versions": errors.New(diagnostic)},
+		errs: map[string]error{"info --json=v2 --installed": errors.New(diagnostic)},
 	}
 
 	var reported []string
@@ -105,7 +107,9 @@ func TestGetBrewCasks_reportsDiagnosticWhenTapIsUntrusted(t *testing.T) {
 		reported = append(reported, stderr)
 	})
 
-	service.GetBrewCasks()
+	if _, err := service.InstalledCasks(); err == nil {
+		t.Fatal("expected an error when the cask list cannot be read")
+	}
 
 	if len(reported) != 1 {
 		t.Fatalf("expected the failure diagnostic to be reported once, got %d", len(reported))
@@ -115,23 +119,24 @@ func TestGetBrewCasks_reportsDiagnosticWhenTapIsUntrusted(t *testing.T) {
 	}
 }
 
-// A malformed install-reason payload silently degrades every package to
-// "unknown". Swallowing the parse error leaves no trace of why, so the failure
-// must reach the session log.
-func TestGetBrewPackages_logsWhenInstallReasonJSONIsUnreadable(t *testing.T) {
+// A malformed inventory payload must not pass for an empty installation.
+// Swallowing the parse error leaves no trace of why, so the failure must reach
+// the session log as well as the caller.
+func TestInstalledFormulae_logsWhenInventoryJSONIsUnreadable(t *testing.T) {
 	runner := &fakeRunner{
 		stdout: map[string]string{
-			"list --formula --versions":            "wget 1.21\n",
-			"info --json=v2 --formula --installed": "this is not json",
+			"info --json=v2 --installed": "{this is not json",
 		},
 	}
 
 	var logged []string
 	service := newTestListService(runner, func(msg string) { logged = append(logged, msg) })
 
-	service.GetBrewPackages()
+	if _, err := service.InstalledFormulae(); err == nil {
+		t.Fatal("expected an error when the inventory JSON could not be parsed")
+	}
 
 	if len(logged) == 0 {
-		t.Fatal("expected a log entry when the install-reason JSON could not be parsed")
+		t.Fatal("expected a log entry when the inventory JSON could not be parsed")
 	}
 }
@@ -0,0 +1,67 @@
+package brew
+
+// Package types reported in OutdatedPackage.Type.
+const (
+	PackageTypeFormula = "formula"
+	PackageTypeCask    = "cask"
+)
+
+// Install reasons reported in InstalledFormula.InstallReason.
+const (
+	InstallReasonOnRequest  = "on_request"
+	InstallReasonDependency = "dependency"
+	InstallReasonUnknown    = "unknown"
+)
+
+// InstalledFormula is an installed Homebrew formula.
+type InstalledFormula struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	InstallReason string `json:"installReason"`
+	Tap           string `json:"tap"`
+	Pinned        bool   `json:"pinned"`
+	// Linked reports whether a keg is symlinked into the prefix. Keg-only
+	// formulae are unlinked unless linked with --force.
+	Linked   bool   `json:"linked"`
+	KegOnly  bool   `json:"kegOnly"`
+	Desc     string `json:"desc"`
+	Homepage string `json:"homepage"`
+}
+
+// InstalledCask is an installed Homebrew cask.
+type InstalledCask struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Tap      string `json:"tap"`
+	Desc     string `json:"desc"`
+	Homepage string `json:"homepage"`
+}
+
+// OutdatedPackage is a formula or cask with a newer version available.
+// Warning carries Homebrew diagnostics attributed to the package, if any.
+type OutdatedPackage struct {
+	Name             string `json:"name"`
+	InstalledVersion string `json:"installedVersion"`
+	CurrentVersion   string `json:"currentVersion"`
+	Size             string `json:"size"`
+	Warning          string `json:"warning"`
+	Type             string `json:"type"`
+	// Pinned formulae are listed but skipped by upgrades until unpinned.
+	Pinned        bool   `json:"pinned"`
+	PinnedVersion string `json:"pinnedVersion"`
+	// Policy is the configured upgrade policy, if any, and PolicyAllowed
+	// whether it lets upgrade-all install CurrentVersion.
+	Policy        string `json:"policy,omitempty"`
+	PolicyAllowed bool   `json:"policyAllowed"`
+	// Change classifies the upgrade from InstalledVersion to CurrentVersion.
+	Change VersionChange `json:"change"`
+}
+
+// Tap is a tapped repository. Trusted is nil when the installed Homebrew does
+// not report tap trust.
+type Tap struct {
+	Name    string `json:"name"`
+	Trusted *bool  `json:"trusted,omitempty"`
+	// Managed marks the local tap WailBrew extracts older versions into.
+	Managed bool `json:"managed,omitempty"`
+}
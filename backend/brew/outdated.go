@@ -8,7 +8,7 @@ import (
 
 // OutdatedService provides outdated package checking functionality
 type OutdatedService struct {
-	executor              *Executor
+	executor              Runner
 	validateFunc          func() error
 	logFunc               func(string)
 	extractJSON           func(string) (string, string, error)
@@ -16,12 +16,13 @@ type OutdatedService struct {
 	getSizes              func([]string, bool) map[string]string
 	getOutdatedFlag       func() string
 	getCustomOutdatedArgs func() string
+	getUpgradePolicies    func() map[string]string
 	getBackendMsg         func(string, map[string]string) string
 }
 
 // NewOutdatedService creates a new outdated service
 func NewOutdatedService(
-	executor *Executor,
+	executor Runner,
 	validateFunc func() error,
 	logFunc func(string),
 	extractJSON func(string) (string, string, error),
@@ -29,6 +30,7 @@ func NewOutdatedService(
 	getSizes func([]string, bool) map[string]string,
 	getOutdatedFlag func() string,
 	getCustomOutdatedArgs func() string,
+	getUpgradePolicies func() map[string]string,
 	getBackendMsg func(string, map[string]string) string,
 ) *OutdatedService {
 	return &OutdatedService{
@@ -40,15 +42,16 @@ func NewOutdatedService(
 		getSizes:              getSizes,
 		getOutdatedFlag:       getOutdatedFlag,
 		getCustomOutdatedArgs: getCustomOutdatedArgs,
+		getUpgradePolicies:    getUpgradePolicies,
 		getBackendMsg:         getBackendMsg,
 	}
 }
 
-// GetBrewUpdatablePackages checks which packages have updates available using brew outdated
-func (s *OutdatedService) GetBrewUpdatablePackages() [][]string {
+// OutdatedPackages checks which packages have updates available using brew outdated
+func (s *OutdatedService) OutdatedPackages() ([]OutdatedPackage, error) {
 	// Validate brew installation first
 	if err := s.validateFunc(); err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Homebrew validation failed: %v", err)}}
+		return nil, fmt.Errorf("Homebrew validation failed: %v", err)
 	}
 
 	// Use brew outdated with JSON output for accurate detection
@@ -73,19 +76,19 @@ func (s *OutdatedService) GetBrewUpdatablePackages() [][]string {
 
 	output, err := s.executor.Run(args...)
 	if err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Failed to check for updates: %v", err)}}
+		return nil, fmt.Errorf("Failed to check for updates: %v", err)
 	}
 
 	outputStr := strings.TrimSpace(string(output))
 	// If output is empty or "[]", no packages are outdated
 	if outputStr == "" || outputStr == "[]" {
-		return [][]string{}
+		return []OutdatedPackage{}, nil
 	}
 
 	// Extract JSON portion from output (in case there are warnings before the JSON)
 	jsonOutput, warnings, err := s.extractJSON(outputStr)
 	if err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Failed to extract JSON from brew outdated output: %v", err)}}
+		return nil, fmt.Errorf("Failed to extract JSON from brew outdated output: %v", err)
 	}
 
 	// Parse warnings to map them to specific packages
@@ -113,20 +116,15 @@ func (s *OutdatedService) GetBrewUpdatablePackages() [][]string {
 	}
 
 	if err := json.Unmarshal([]byte(jsonOutput), &brewOutdated); err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Failed to parse outdated packages: %v", err)}}
+		return nil, fmt.Errorf("Failed to parse outdated packages: %v", err)
 	}
 
-	var updatablePackages [][]string
+	updatablePackages := []OutdatedPackage{}
 	var formulaeNames []string
 	var caskNames []string
 
 	// Process formulae (packages)
 	for _, formula := range brewOutdated.Formulae {
-		// Skip pinned packages as they won't be updated
-		if formula.Pinned {
-			continue
-		}
-
 		installedVersion := "unknown"
 		if len(formula.InstalledVersions) > 0 {
 			installedVersion = formula.InstalledVersions[0]
@@ -140,13 +138,14 @@ func (s *OutdatedService) GetBrewUpdatablePackages() [][]string {
 			warning = w
 		}
 
-		updatablePackages = append(updatablePackages, []string{
-			formula.Name,
-			installedVersion,
-			formula.CurrentVersion,
-			"",        // size placeholder, will be filled below
-			warning,   // warning message
-			"formula", // package type
+		updatablePackages = append(updatablePackages, OutdatedPackage{
+			Name:             formula.Name,
+			InstalledVersion: installedVersion,
+			CurrentVersion:   formula.CurrentVersion,
+			Warning:          warning,
+			Type:             PackageTypeFormula,
+			Pinned:           formula.Pinned,
+			PinnedVersion:    formula.PinnedVersion,
 		})
 	}
 
@@ -176,33 +175,42 @@ func (s *OutdatedService) GetBrewUpdatablePackages() [][]string {
 			}
 		}
 
-		updatablePackages = append(updatablePackages, []string{
-			cask.Name,
-			installedVersion,
-			cask.CurrentVersion,
-			"",      // size placeholder, will be filled below
-			warning, // warning message
-			"cask",  // package type
+		updatablePackages = append(updatablePackages, OutdatedPackage{
+			Name:             cask.Name,
+			InstalledVersion: installedVersion,
+			CurrentVersion:   cask.CurrentVersion,
+			Warning:          warning,
+			Type:             PackageTypeCask,
 		})
 	}
 
+	// Annotate each entry with the kind of upgrade and whether its upgrade
+	// policy allows it
+	policies := s.getUpgradePolicies()
+	for i := range updatablePackages {
+		pkg := &updatablePackages[i]
+		pkg.Change = ClassifyUpgrade(pkg.InstalledVersion, pkg.CurrentVersion)
+		pkg.Policy = policies[pkg.Name]
+		pkg.PolicyAllowed = PolicyAllows(pkg.Policy, pkg.InstalledVersion, pkg.CurrentVersion)
+	}
+
 	// Get size information for all packages
 	formulaeSizes := s.getSizes(formulaeNames, false)
 	caskSizes := s.getSizes(caskNames, true)
 
 	// Fill in size information
 	for i := range updatablePackages {
-		name := updatablePackages[i][0]
+		name := updatablePackages[i].Name
 		if size, found := formulaeSizes[name]; found {
-			updatablePackages[i][3] = size
+			updatablePackages[i].Size = size
 		} else if size, found := caskSizes[name]; found {
-			updatablePackages[i][3] = size
+			updatablePackages[i].Size = size
 		} else {
-			updatablePackages[i][3] = "Unknown"
+			updatablePackages[i].Size = "Unknown"
 		}
 	}
 
-	return updatablePackages
+	return updatablePackages, nil
 }
 
 func (s *OutdatedService) getAutoUpdateCaskNames(caskNames []string) map[string]bool {
@@ -0,0 +1,51 @@
+package brew
+
+import "strings"
+
+// Upgrade policies as stored per package in the configuration. They limit
+// which versions upgrade-all may move a package to; upgrading a package by
+// name is an explicit choice and ignores them. A skip policy is the prefix
+// followed by the version to pass over, as in "skip:22.0.0".
+const (
+	UpgradePolicyAny        = ""
+	UpgradePolicyNever      = "never"
+	UpgradePolicyPatch      = "patch"
+	UpgradePolicyMinor      = "minor"
+	UpgradePolicySkipPrefix = "skip:"
+)
+
+// ValidUpgradePolicy reports whether policy is one of the known forms.
+func ValidUpgradePolicy(policy string) bool {
+	switch policy {
+	case UpgradePolicyAny, UpgradePolicyNever, UpgradePolicyPatch, UpgradePolicyMinor:
+		return true
+	}
+	version, ok := strings.CutPrefix(policy, UpgradePolicySkipPrefix)
+	return ok && strings.TrimSpace(version) != ""
+}
+
+// PolicyAllows reports whether policy lets an upgrade move a package from
+// installed to current. Patch policies also allow revision and rebuild
+// changes; an upgrade that cannot be classified, such as one to or from
+// "latest", is held back since it cannot be shown to stay within bounds.
+func PolicyAllows(policy, installed, current string) bool {
+	switch policy {
+	case UpgradePolicyAny:
+		return true
+	case UpgradePolicyNever:
+		return false
+	case UpgradePolicyPatch, UpgradePolicyMinor:
+		switch ClassifyUpgrade(installed, current) {
+		case VersionChangePatch, VersionChangeRevision, VersionChangeRebuild:
+			return true
+		case VersionChangeMinor:
+			return policy == UpgradePolicyMinor
+		default:
+			return false
+		}
+	}
+	if skipped, ok := strings.CutPrefix(policy, UpgradePolicySkipPrefix); ok {
+		return CompareVersions(strings.TrimSpace(skipped), current) != 0
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package brew
+
+import "testing"
+
+func TestPolicyAllows(t *testing.T) {
+	tests := []struct {
+		policy    string
+		installed string
+		current   string
+		want      bool
+	}{
+		{UpgradePolicyAny, "22.1.0", "23.0.0", true},
+		{UpgradePolicyNever, "1.24.4", "1.24.5", false},
+		{UpgradePolicyPatch, "3.12.4", "3.12.5", true},
+		{UpgradePolicyPatch, "3.12.4", "3.13.0", false},
+		{UpgradePolicyPatch, "1.7", "1.7.1", true},
+		{UpgradePolicyPatch, "1.7.1_1", "1.7.1_2", true},
+		{UpgradePolicyMinor, "22.1.0", "22.2.0", true},
+		{UpgradePolicyMinor, "22.1.0", "23.0.0", false},
+		{UpgradePolicyMinor, "130.0,20240101", "130.1,20240201", true},
+		{UpgradePolicyMinor, "latest", "latest", false},
+		{"skip:23.0.0", "22.1.0", "23.0.0", false},
+		{"skip:23.0.0", "22.1.0", "23.0.1", true},
+		{"skip:23.0", "22.1.0", "23.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := PolicyAllows(tt.policy, tt.installed, tt.current); got != tt.want {
+			t.Errorf("PolicyAllows(%q, %q, %q) = %v, want %v", tt.policy, tt.installed, tt.current, got, tt.want)
+		}
+	}
+
+	for policy, want := range map[string]bool{"never": true, "patch": true, "skip:1.0": true, "skip:": false, "major": false} {
+		if got := ValidUpgradePolicy(policy); got != want {
+			t.Errorf("ValidUpgradePolicy(%q) = %v, want %v", policy, got, want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+package brew
+
+import "os/exec"
+
+// killProcessGroupOnCancel is a no-op on platforms without process groups;
+// exec.CommandContext still kills the direct child on cancellation.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,313 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Operation states reported in the queue snapshot.
+const (
+	OperationPending = "pending"
+	OperationRunning = "running"
+)
+
+// errOperationDequeued is returned by OperationQueue.Do when a job was removed
+// from the queue (or its context ended) before it got a chance to run.
+var errOperationDequeued = errors.New("operation was removed from the queue before it started")
+
+// Operation identifies a queued or running brew command. Kind matches the
+// action names used by the command preview (install, uninstall, upgrade, ...).
+type Operation struct {
+	ID       string    `json:"id"`
+	Kind     string    `json:"kind"`
+	Target   string    `json:"target"`
+	Status   string    `json:"status"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+type queuedOperation struct {
+	Operation
+	ready     chan struct{} // closed when the job reaches the head of the queue
+	removed   chan struct{} // closed when the job is dropped while still pending
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// OperationQueue serializes every mutating brew command. Homebrew holds a
+// global lock while it installs, upgrades or updates, so two concurrent
+// commands fail with "another active Homebrew process". Jobs run one at a
+// time in FIFO order; pending jobs can be listed, reordered and removed.
+//
+// The queue emits "operationQueueChanged" with the full snapshot whenever it
+// changes and "operationStarted" when a job begins to run. When a job is
+// cancelled — either while running or before it started — it emits
+// "operationCancelled" in addition to the job's own completion event, so the
+// UI can tell a cancel apart from a failure. A job that ran emits its
+// completion event itself; for one that never ran, Run emits it on the job's
+// behalf so nothing waiting for it is left hanging.
+type OperationQueue struct {
+	eventEmitter  EventEmitter
+	getBackendMsg func(string, map[string]string) string
+
+	mu     sync.Mutex
+	nextID uint64
+	jobs   []*queuedOperation // jobs[0] is running (or about to run)
+}
+
+// NewOperationQueue creates an empty operation queue.
+func NewOperationQueue(eventEmitter EventEmitter, getBackendMsg func(string, map[string]string) string) *OperationQueue {
+	return &OperationQueue{
+		eventEmitter:  eventEmitter,
+		getBackendMsg: getBackendMsg,
+	}
+}
+
+// Run queues fn and blocks until it has run, returning its result. fn receives
+// a context that is cancelled by Cancel. If the job is removed before it
+// starts, the localized "cancelled" message is returned instead and, unless
+// completeEvent is empty, emitted on completeEvent: the event fn would have
+// announced its outcome on.
+func (q *OperationQueue) Run(ctx context.Context, kind, target, completeEvent string, fn func(ctx context.Context) string) string {
+	var result string
+	if err := q.Do(ctx, kind, target, func(ctx context.Context) { result = fn(ctx) }); err != nil {
+		message := q.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		if completeEvent != "" && q.eventEmitter != nil {
+			q.eventEmitter.Emit(completeEvent, message)
+		}
+		return message
+	}
+	return result
+}
+
+// Do queues fn and blocks until it has run. It returns an error only when fn
+// never ran because the job was removed or ctx ended while it was pending.
+func (q *OperationQueue) Do(ctx context.Context, kind, target string, fn func(ctx context.Context)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	job := q.enqueue(kind, target, cancel)
+
+	select {
+	case <-job.ready:
+	case <-job.removed:
+	case <-ctx.Done():
+		// The job may be promoted or removed concurrently, so wait for
+		// whichever of the two actually happened.
+		q.dropPending(job.ID)
+		select {
+		case <-job.ready:
+		case <-job.removed:
+		}
+	}
+
+	if isClosed(job.removed) {
+		q.emitOperation("operationCancelled", job.Operation)
+		return errOperationDequeued
+	}
+	if ctx.Err() != nil {
+		// Promoted, but cancelled before it could start: release the head.
+		q.finish(job)
+		q.emitOperation("operationCancelled", job.Operation)
+		return errOperationDequeued
+	}
+
+	q.emitOperation("operationStarted", job.Operation)
+	fn(ctx)
+
+	if q.finish(job) {
+		q.emitOperation("operationCancelled", job.Operation)
+	}
+	return nil
+}
+
+// Cancel stops the operation with the given ID: a running job has its context
+// cancelled, a pending job is dropped from the queue. It reports false when no
+// such job exists (for example because it already finished).
+func (q *OperationQueue) Cancel(id string) bool {
+	q.mu.Lock()
+	for _, job := range q.jobs {
+		if job.ID == id && job.Status == OperationRunning {
+			job.cancelled = true
+			job.cancel()
+			q.mu.Unlock()
+			return true
+		}
+	}
+	q.mu.Unlock()
+
+	return q.dropPending(id)
+}
+
+// Jobs returns a snapshot of the queue, running job first.
+func (q *OperationQueue) Jobs() []Operation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.snapshotLocked()
+}
+
+// Move reorders a pending job to index within the pending part of the queue.
+// Out-of-range indexes are clamped. The running job cannot be moved.
+func (q *OperationQueue) Move(id string, index int) error {
+	q.mu.Lock()
+
+	from := -1
+	for i, job := range q.jobs {
+		if job.ID == id {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		q.mu.Unlock()
+		return fmt.Errorf("operation %s is not queued", id)
+	}
+	if q.jobs[from].Status != OperationPending {
+		q.mu.Unlock()
+		return fmt.Errorf("operation %s is already running", id)
+	}
+
+	// Pending jobs start after the head when something is running.
+	first := 0
+	if len(q.jobs) > 0 && q.jobs[0].Status == OperationRunning {
+		first = 1
+	}
+	to := first + index
+	if to < first {
+		to = first
+	}
+	if to > len(q.jobs)-1 {
+		to = len(q.jobs) - 1
+	}
+
+	job := q.jobs[from]
+	q.jobs = append(q.jobs[:from], q.jobs[from+1:]...)
+	q.jobs = append(q.jobs[:to], append([]*queuedOperation{job}, q.jobs[to:]...)...)
+	q.promoteHeadLocked()
+	snapshot := q.snapshotLocked()
+	q.mu.Unlock()
+
+	q.emitQueue(snapshot)
+	return nil
+}
+
+// Remove drops a pending job from the queue. Use Cancel to stop a running job.
+func (q *OperationQueue) Remove(id string) error {
+	if q.dropPending(id) {
+		return nil
+	}
+	return fmt.Errorf("operation %s is not pending", id)
+}
+
+func (q *OperationQueue) enqueue(kind, target string, cancel context.CancelFunc) *queuedOperation {
+	q.mu.Lock()
+	q.nextID++
+	job := &queuedOperation{
+		Operation: Operation{
+			ID:       fmt.Sprintf("op-%d", q.nextID),
+			Kind:     kind,
+			Target:   target,
+			Status:   OperationPending,
+			QueuedAt: time.Now(),
+		},
+		ready:   make(chan struct{}),
+		removed: make(chan struct{}),
+		cancel:  cancel,
+	}
+	q.jobs = append(q.jobs, job)
+	q.promoteHeadLocked()
+	snapshot := q.snapshotLocked()
+	q.mu.Unlock()
+
+	q.emitQueue(snapshot)
+	return job
+}
+
+// dropPending removes a pending job and reports whether it was found.
+func (q *OperationQueue) dropPending(id string) bool {
+	q.mu.Lock()
+	for i, job := range q.jobs {
+		if job.ID != id || job.Status != OperationPending {
+			continue
+		}
+		q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+		close(job.removed)
+		q.promoteHeadLocked()
+		snapshot := q.snapshotLocked()
+		q.mu.Unlock()
+
+		q.emitQueue(snapshot)
+		return true
+	}
+	q.mu.Unlock()
+	return false
+}
+
+// finish removes a completed job, starts the next one and reports whether the
+// finished job had been cancelled.
+func (q *OperationQueue) finish(done *queuedOperation) bool {
+	q.mu.Lock()
+	for i, job := range q.jobs {
+		if job == done {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+	}
+	q.promoteHeadLocked()
+	snapshot := q.snapshotLocked()
+	q.mu.Unlock()
+
+	q.emitQueue(snapshot)
+	return done.cancelled
+}
+
+// promoteHeadLocked lets the job at the head of the queue run if nothing is
+// running yet. Callers must hold q.mu.
+func (q *OperationQueue) promoteHeadLocked() {
+	if len(q.jobs) == 0 || q.jobs[0].Status == OperationRunning {
+		return
+	}
+	q.jobs[0].Status = OperationRunning
+	close(q.jobs[0].ready)
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *OperationQueue) snapshotLocked() []Operation {
+	ops := make([]Operation, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		ops = append(ops, job.Operation)
+	}
+	return ops
+}
+
+func (q *OperationQueue) emitQueue(snapshot []Operation) {
+	if q.eventEmitter == nil {
+		return
+	}
+	if payload, err := json.Marshal(snapshot); err == nil {
+		q.eventEmitter.Emit("operationQueueChanged", string(payload))
+	}
+}
+
+func (q *OperationQueue) emitOperation(event string, op Operation) {
+	if q.eventEmitter == nil {
+		return
+	}
+	if payload, err := json.Marshal(op); err == nil {
+		q.eventEmitter.Emit(event, string(payload))
+	}
+}
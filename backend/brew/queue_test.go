@@ -0,0 +1,266 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []string
+	data   []string
+}
+
+func (e *recordingEmitter) Emit(event string, data string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+	e.data = append(e.data, data)
+}
+
+func (e *recordingEmitter) count(event string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := 0
+	for _, ev := range e.events {
+		if ev == event {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *recordingEmitter) first(event string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, ev := range e.events {
+		if ev == event {
+			return e.data[i]
+		}
+	}
+	return ""
+}
+
+func newTestQueue(emitter EventEmitter) *OperationQueue {
+	return NewOperationQueue(emitter, func(key string, _ map[string]string) string { return key })
+}
+
+// waitForQueueLength polls until the queue holds n jobs, so tests can submit
+// from goroutines and still know their order.
+func waitForQueueLength(t *testing.T, q *OperationQueue, n int) []Operation {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if jobs := q.Jobs(); len(jobs) == n {
+			return jobs
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("queue never reached %d jobs, have %v", n, q.Jobs())
+	return nil
+}
+
+func TestOperationQueue_cancelStopsRunningOperation(t *testing.T) {
+	emitter := &recordingEmitter{}
+	queue := newTestQueue(emitter)
+
+	result := queue.Run(context.Background(), "install", "wget", "", func(ctx context.Context) string {
+		var op Operation
+		if err := json.Unmarshal([]byte(emitter.first("operationStarted")), &op); err != nil {
+			t.Fatalf("operationStarted payload is not JSON: %v", err)
+		}
+		if op.Kind != "install" || op.Target != "wget" || op.Status != OperationRunning {
+			t.Fatalf("unexpected operation payload: %+v", op)
+		}
+		if !queue.Cancel(op.ID) {
+			t.Fatalf("expected running operation %s to be cancellable", op.ID)
+		}
+		<-ctx.Done()
+		return "cancelled"
+	})
+
+	if result != "cancelled" {
+		t.Fatalf("expected the operation's own result, got %q", result)
+	}
+	if emitter.count("operationCancelled") != 1 {
+		t.Fatalf("expected one operationCancelled event, got %v", emitter.events)
+	}
+}
+
+func TestOperationQueue_finishedOperationIsNotCancelled(t *testing.T) {
+	emitter := &recordingEmitter{}
+	queue := newTestQueue(emitter)
+
+	queue.Run(context.Background(), "untap", "user/repo", "", func(context.Context) string { return "ok" })
+
+	var op Operation
+	if err := json.Unmarshal([]byte(emitter.first("operationStarted")), &op); err != nil {
+		t.Fatalf("operationStarted payload is not JSON: %v", err)
+	}
+	if queue.Cancel(op.ID) {
+		t.Fatal("expected cancelling a finished operation to report false")
+	}
+	if emitter.count("operationCancelled") != 0 {
+		t.Fatalf("did not expect operationCancelled for a completed operation, got %v", emitter.events)
+	}
+	if len(queue.Jobs()) != 0 {
+		t.Fatalf("expected an empty queue, got %v", queue.Jobs())
+	}
+}
+
+// Two mutating commands must never overlap: Homebrew's lock makes the second
+// one fail. Jobs run one at a time, in the order they were submitted.
+func TestOperationQueue_runsJobsSeriallyInFIFOOrder(t *testing.T) {
+	queue := newTestQueue(nil)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	running := 0
+	overlapped := false
+
+	job := func(name string) func(context.Context) string {
+		return func(context.Context) string {
+			mu.Lock()
+			running++
+			if running > 1 {
+				overlapped = true
+			}
+			order = append(order, name)
+			mu.Unlock()
+
+			if name == "first" {
+				<-release
+			}
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return name
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, name := range []string{"first", "second", "third"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			queue.Run(context.Background(), "install", name, "", job(name))
+		}()
+		waitForQueueLength(t, queue, i+1)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if overlapped {
+		t.Fatal("expected queued operations never to run concurrently")
+	}
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "third" {
+		t.Fatalf("expected FIFO order, got %v", order)
+	}
+}
+
+func TestOperationQueue_moveAndRemovePendingJobs(t *testing.T) {
+	emitter := &recordingEmitter{}
+	queue := newTestQueue(emitter)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	results := make(map[string]string)
+
+	var wg sync.WaitGroup
+	for i, name := range []string{"head", "a", "b", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := queue.Run(context.Background(), "install", name, "", func(context.Context) string {
+				if name == "head" {
+					<-release
+				}
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return "ran"
+			})
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+		waitForQueueLength(t, queue, i+1)
+	}
+
+	jobs := queue.Jobs()
+	if jobs[0].Status != OperationRunning || jobs[1].Status != OperationPending {
+		t.Fatalf("expected the head to run and the rest to wait, got %v", jobs)
+	}
+	if err := queue.Move(jobs[0].ID, 0); err == nil {
+		t.Fatal("expected moving the running job to fail")
+	}
+
+	// Move "c" to the front of the pending jobs and drop "a".
+	if err := queue.Move(jobs[3].ID, 0); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if err := queue.Remove(jobs[1].ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != "head" || order[1] != "c" || order[2] != "b" {
+		t.Fatalf("expected head, c, b; got %v", order)
+	}
+	if results["a"] != "backend.operation.cancelled" {
+		t.Fatalf("expected the removed job to report cancellation, got %q", results["a"])
+	}
+	if emitter.count("operationCancelled") != 1 {
+		t.Fatalf("expected one operationCancelled event for the removed job, got %d", emitter.count("operationCancelled"))
+	}
+	if emitter.count("operationQueueChanged") == 0 {
+		t.Fatal("expected queue changes to be emitted")
+	}
+}
+
+// A pending job that is removed never runs fn, so the queue has to announce
+// the job's completion itself or the dialog waiting for it never closes.
+func TestOperationQueue_removedPendingJobEmitsItsCompletion(t *testing.T) {
+	emitter := &recordingEmitter{}
+	queue := newTestQueue(emitter)
+
+	release := make(chan struct{})
+	go queue.Run(context.Background(), "upgrade-all", "", "packageUpdateComplete", func(context.Context) string {
+		<-release
+		return "done"
+	})
+	waitForQueueLength(t, queue, 1)
+
+	result := make(chan string)
+	ran := false
+	go func() {
+		result <- queue.Run(context.Background(), "install", "wget", "packageInstallComplete", func(context.Context) string {
+			ran = true
+			return "installed"
+		})
+	}()
+	jobs := waitForQueueLength(t, queue, 2)
+
+	if err := queue.Remove(jobs[1].ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if got := <-result; got != "backend.operation.cancelled" || ran {
+		t.Fatalf("Run = %q, ran = %v; want the cancelled message without running", got, ran)
+	}
+	if !emitter.has("packageInstallComplete", "backend.operation.cancelled") {
+		t.Fatalf("expected packageInstallComplete with the cancelled message, got %v", emitter.events)
+	}
+	if emitter.count("packageUpdateComplete") != 0 {
+		t.Fatal("the running job's completion is its own to emit")
+	}
+	close(release)
+}
@@ -0,0 +1,110 @@
+package brew
+
+import "fmt"
+
+// The functions in this file render typed results in the legacy [][]string
+// row layout the frontend still consumes. A failure becomes a single
+// {"Error", message} row. They take the (result, error) pair directly so an
+// adapter can be written as FormulaRows(service.GetInstalledFormulae()).
+
+func errorRows(err error) [][]string {
+	return [][]string{{"Error", err.Error()}}
+}
+
+// flag renders a boolean column as "true" or "".
+func flag(set bool) string {
+	if set {
+		return "true"
+	}
+	return ""
+}
+
+// FormulaRows renders formulae as
+// [name, version, size, installReason, pinned, linked, kegOnly] rows, where the
+// last three are "true" or "". The size column is left empty; the frontend
+// loads sizes lazily.
+func FormulaRows(formulae []InstalledFormula, err error) [][]string {
+	if err != nil {
+		return errorRows(err)
+	}
+	rows := make([][]string, 0, len(formulae))
+	for _, f := range formulae {
+		rows = append(rows, []string{f.Name, f.Version, "", f.InstallReason, flag(f.Pinned), flag(f.Linked), flag(f.KegOnly)})
+	}
+	return rows
+}
+
+// CaskRows renders casks as [name, version, size] rows.
+func CaskRows(casks []InstalledCask, err error) [][]string {
+	if err != nil {
+		return errorRows(err)
+	}
+	rows := make([][]string, 0, len(casks))
+	for _, c := range casks {
+		rows = append(rows, []string{c.Name, c.Version, ""})
+	}
+	return rows
+}
+
+// OutdatedRows renders outdated packages as
+// [name, installed, current, size, warning, type, pinned, policy, held, change]
+// rows, where pinned and held are "true" or "" and held marks packages their
+// upgrade policy keeps out of upgrade-all.
+func OutdatedRows(packages []OutdatedPackage, err error) [][]string {
+	if err != nil {
+		return errorRows(err)
+	}
+	rows := make([][]string, 0, len(packages))
+	for _, p := range packages {
+		rows = append(rows, []string{p.Name, p.InstalledVersion, p.CurrentVersion, p.Size, p.Warning, p.Type,
+			flag(p.Pinned), p.Policy, flag(!p.PolicyAllowed), string(p.Change)})
+	}
+	return rows
+}
+
+// TapRows renders taps as [name, "Active", trusted, managed] rows, where
+// trusted is "true", "false" or "" when unknown and managed is "true" only
+// for the local tap WailBrew extracts older versions into.
+func TapRows(taps []Tap, err error) [][]string {
+	if err != nil {
+		return errorRows(err)
+	}
+	rows := make([][]string, 0, len(taps))
+	for _, t := range taps {
+		trusted := ""
+		if t.Trusted != nil {
+			if *t.Trusted {
+				trusted = "true"
+			} else {
+				trusted = "false"
+			}
+		}
+		managed := ""
+		if t.Managed {
+			managed = "true"
+		}
+		rows = append(rows, []string{t.Name, "Active", trusted, managed})
+	}
+	return rows
+}
+
+// ServiceRows renders services as [name, status, user] rows.
+func ServiceRows(services []ServiceEntry, err error) [][]string {
+	if err != nil {
+		return errorRows(err)
+	}
+	rows := make([][]string, 0, len(services))
+	for _, s := range services {
+		rows = append(rows, []string{s.Name, s.Status, s.User})
+	}
+	return rows
+}
+
+// LeafNames renders leaves as a plain name list; a failure becomes a single
+// "Error: message" entry.
+func LeafNames(leaves []string, err error) []string {
+	if err != nil {
+		return []string{fmt.Sprintf("Error: %v", err)}
+	}
+	return leaves
+}
@@ -0,0 +1,119 @@
+package brew
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRowAdapters(t *testing.T) {
+	trusted, untrusted := true, false
+
+	tests := []struct {
+		name string
+		got  [][]string
+		want [][]string
+	}{
+		{
+			name: "formulae",
+			got: FormulaRows([]InstalledFormula{
+				{Name: "wget", Version: "1.24.5", InstallReason: InstallReasonOnRequest, Linked: true},
+				{Name: "node", Version: "22.1.0", InstallReason: InstallReasonOnRequest, Pinned: true, Linked: true},
+				{Name: "openssl@3", Version: "3.3.2", InstallReason: InstallReasonDependency, KegOnly: true},
+			}, nil),
+			want: [][]string{
+				{"wget", "1.24.5", "", "on_request", "", "true", ""},
+				{"node", "22.1.0", "", "on_request", "true", "true", ""},
+				{"openssl@3", "3.3.2", "", "dependency", "", "", "true"},
+			},
+		},
+		{
+			name: "casks",
+			got:  CaskRows([]InstalledCask{{Name: "firefox", Version: "131.0"}}, nil),
+			want: [][]string{{"firefox", "131.0", ""}},
+		},
+		{
+			name: "outdated",
+			got: OutdatedRows([]OutdatedPackage{
+				{Name: "firefox", InstalledVersion: "130.0", CurrentVersion: "131.0", Size: "400 MB", Type: PackageTypeCask, PolicyAllowed: true, Change: VersionChangeMajor},
+				{Name: "node", InstalledVersion: "22.1.0", CurrentVersion: "23.0.0", Size: "90M", Type: PackageTypeFormula, Policy: UpgradePolicyMinor, Change: VersionChangeMajor},
+			}, nil),
+			want: [][]string{
+				{"firefox", "130.0", "131.0", "400 MB", "", "cask", "", "", "", "major"},
+				{"node", "22.1.0", "23.0.0", "90M", "", "formula", "", "minor", "true", "major"},
+			},
+		},
+		{
+			name: "taps",
+			got: TapRows([]Tap{
+				{Name: "acme/tools", Trusted: &trusted}, {Name: "acme/old", Trusted: &untrusted}, {Name: "homebrew/core"},
+				{Name: LocalTapName, Managed: true},
+			}, nil),
+			want: [][]string{
+				{"acme/tools", "Active", "true", ""}, {"acme/old", "Active", "false", ""}, {"homebrew/core", "Active", "", ""},
+				{LocalTapName, "Active", "", "true"},
+			},
+		},
+		{
+			name: "services",
+			got:  ServiceRows([]ServiceEntry{{Name: "redis", Status: "started", User: "nico"}}, nil),
+			want: [][]string{{"redis", "started", "nico"}},
+		},
+		{
+			name: "empty result is an empty list",
+			got:  FormulaRows([]InstalledFormula{}, nil),
+			want: [][]string{},
+		},
+		{
+			name: "error",
+			got:  CaskRows(nil, errors.New("Failed to fetch installed casks: exit status 1")),
+			want: [][]string{{"Error", "Failed to fetch installed casks: exit status 1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// A package literally named "Error" used to be indistinguishable from the
+// error row. The typed API keeps data and failure apart.
+func TestGetOutdatedPackages_typedResult(t *testing.T) {
+	const outdated = `{"formulae":[{"name":"Error","installed_versions":["1.0"],"current_version":"1.1","pinned":false},` +
+		`{"name":"node","installed_versions":["22.1.0"],"current_version":"22.2.0","pinned":true,"pinned_version":"22.1.0"}],` +
+		`"casks":[{"name":"firefox","installed_versions":["130.0"],"current_version":"131.0"}]}`
+
+	fb := newFakeBrew().
+		script("outdated --json=v2", brewRecording{stdout: outdated}).
+		script("info --cask --json=v2 firefox", brewRecording{stdout: `{"casks":[{"token":"firefox","auto_updates":true}]}`})
+	service, _ := newFakeService(fb)
+
+	packages, err := service.GetOutdatedPackages()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []OutdatedPackage{
+		{Name: "Error", InstalledVersion: "1.0", CurrentVersion: "1.1", Size: "Unknown", Type: PackageTypeFormula, PolicyAllowed: true, Change: VersionChangeMinor},
+		{Name: "node", InstalledVersion: "22.1.0", CurrentVersion: "22.2.0", Size: "Unknown", Type: PackageTypeFormula, Pinned: true, PinnedVersion: "22.1.0", PolicyAllowed: true, Change: VersionChangeMinor},
+		{Name: "firefox", InstalledVersion: "130.0", CurrentVersion: "131.0", Size: "Unknown", Warning: "backend.outdated.autoUpdateCask", Type: PackageTypeCask, PolicyAllowed: true, Change: VersionChangeMajor},
+	}
+	if !reflect.DeepEqual(packages, want) {
+		t.Fatalf("got %+v, want %+v", packages, want)
+	}
+}
+
+func TestGetOutdatedPackages_failureIsAnError(t *testing.T) {
+	fb := newFakeBrew().
+		script("outdated --json=v2", brewRecording{stderr: "Error: Homebrew is locked.\n", exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	packages, err := service.GetOutdatedPackages()
+	if err == nil {
+		t.Fatalf("expected an error, got %v", packages)
+	}
+}
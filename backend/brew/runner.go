@@ -0,0 +1,35 @@
+package brew
+
+import (
+	"context"
+	"time"
+)
+
+// Runner is how the service layer executes brew. It covers both the buffered
+// calls used for reads and the streaming calls used for long-running
+// mutations, so every path through Service can be driven by a fake brew in
+// tests. *Executor is the production implementation.
+type Runner interface {
+	commandRunner
+
+	RunWithTimeout(timeout time.Duration, args ...string) ([]byte, error)
+	RunNoCache(args ...string) ([]byte, error)
+	RunNoCacheWithTimeout(timeout time.Duration, args ...string) ([]byte, error)
+
+	// Stream runs brew with args and forwards trimmed, non-empty output lines
+	// to onStdout/onStderr as they arrive. The returned phase, stderr text and
+	// error follow runStreamingCommand.
+	Stream(ctx context.Context, args []string, onStdout, onStderr func(line string)) (phase streamPhase, stderrText string, err error)
+
+	// RunTool runs a helper program other than brew (e.g. git against the
+	// Homebrew repository) with brew's environment and returns its combined
+	// output.
+	RunTool(name string, args ...string) ([]byte, error)
+
+	// Getenv reports the value of key in the environment brew runs with.
+	Getenv(key string) string
+
+	ClearCache()
+}
+
+var _ Runner = (*Executor)(nil)
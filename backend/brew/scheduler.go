@@ -0,0 +1,288 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// OutdatedDiff is what changed in the outdated list between two scheduled
+// checks. The first check after launch reports every package as added with
+// Initial set.
+type OutdatedDiff struct {
+	Initial bool              `json:"initial"`
+	Added   []OutdatedPackage `json:"added"`
+	// Updated are packages that were already outdated but now have a newer
+	// version available than at the previous check.
+	Updated  []OutdatedPackage `json:"updated"`
+	Removed  []string          `json:"removed"`
+	Packages []OutdatedPackage `json:"packages"`
+	// Count is the number of outdated packages that are not pinned, as shown
+	// on the dock badge.
+	Count int `json:"count"`
+}
+
+// Empty reports whether nothing was added, updated or removed.
+func (d OutdatedDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0
+}
+
+// diffOutdated compares the current outdated list with the previous one,
+// keyed by package name.
+func diffOutdated(previous map[string]OutdatedPackage, current []OutdatedPackage) OutdatedDiff {
+	diff := OutdatedDiff{
+		Added:    []OutdatedPackage{},
+		Updated:  []OutdatedPackage{},
+		Removed:  []string{},
+		Packages: current,
+	}
+	seen := make(map[string]bool, len(current))
+	for _, pkg := range current {
+		seen[pkg.Name] = true
+		if !pkg.Pinned {
+			diff.Count++
+		}
+		before, ok := previous[pkg.Name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, pkg)
+		case before.CurrentVersion != pkg.CurrentVersion:
+			diff.Updated = append(diff.Updated, pkg)
+		}
+	}
+	for name := range previous {
+		if !seen[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// maxOutdatedCheckBackoff caps how far failures stretch the wait between
+// checks, unless the configured interval is longer still.
+const maxOutdatedCheckBackoff = 6 * time.Hour
+
+// nextCheckDelay is how long to wait before the next check. Every consecutive
+// failure doubles the interval, up to maxOutdatedCheckBackoff, so an offline
+// machine or a broken Homebrew is not hit every few minutes. A zero interval
+// disables checks.
+func nextCheckDelay(interval time.Duration, failures int) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	limit := max(interval, maxOutdatedCheckBackoff)
+	delay := interval
+	for i := 0; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// OutdatedScheduler periodically updates the Homebrew database and checks for
+// outdated packages, so the UI and dock badge stay current without polling
+// from the frontend.
+//
+// Each check emits "outdatedCheckStarted", then either "outdatedChanged" with
+// the JSON OutdatedDiff — on the first check and whenever the list changed —
+// or "outdatedCheckFailed" with the error. "outdatedCheckScheduled" carries
+// the time of the next check in Unix milliseconds, or "0" when scheduled
+// checks are disabled.
+type OutdatedScheduler struct {
+	updateDatabase func() error
+	outdated       func() ([]OutdatedPackage, error)
+	getInterval    func() time.Duration
+	eventEmitter   EventEmitter
+	onChecked      func(OutdatedDiff)
+	logFunc        func(string)
+
+	trigger chan struct{}
+	checkMu sync.Mutex // serializes checks
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+	previous map[string]OutdatedPackage
+	failures int
+	next     time.Time
+}
+
+// NewOutdatedScheduler creates a stopped scheduler. getInterval is read
+// before every wait so a changed setting applies after Reschedule; onChecked,
+// if set, runs after every successful check.
+func NewOutdatedScheduler(
+	updateDatabase func() error,
+	outdated func() ([]OutdatedPackage, error),
+	getInterval func() time.Duration,
+	eventEmitter EventEmitter,
+	onChecked func(OutdatedDiff),
+	logFunc func(string),
+) *OutdatedScheduler {
+	return &OutdatedScheduler{
+		updateDatabase: updateDatabase,
+		outdated:       outdated,
+		getInterval:    getInterval,
+		eventEmitter:   eventEmitter,
+		onChecked:      onChecked,
+		logFunc:        logFunc,
+		trigger:        make(chan struct{}, 1),
+	}
+}
+
+// Start runs the first check after initialDelay and then one per interval
+// until ctx ends or Stop is called. The first check runs even when the
+// interval is zero so the badge is right after launch.
+func (s *OutdatedScheduler) Start(ctx context.Context, initialDelay time.Duration) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	done := s.done
+	s.mu.Unlock()
+
+	go s.loop(ctx, initialDelay, done)
+}
+
+// Stop ends the scheduler and waits for a running check to finish.
+func (s *OutdatedScheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Reschedule restarts the wait for the next check, picking up a changed
+// interval.
+func (s *OutdatedScheduler) Reschedule() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// NextCheck returns when the next scheduled check runs, or the zero time when
+// none is scheduled.
+func (s *OutdatedScheduler) NextCheck() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+func (s *OutdatedScheduler) loop(ctx context.Context, delay time.Duration, done chan struct{}) {
+	defer close(done)
+	defer s.setNext(time.Time{})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	s.setNext(time.Now().Add(delay))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.trigger:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-timer.C:
+			s.Check()
+		}
+
+		s.mu.Lock()
+		failures := s.failures
+		s.mu.Unlock()
+
+		delay := nextCheckDelay(s.getInterval(), failures)
+		if delay == 0 {
+			s.setNext(time.Time{})
+			continue
+		}
+		timer.Reset(delay)
+		s.setNext(time.Now().Add(delay))
+	}
+}
+
+func (s *OutdatedScheduler) setNext(next time.Time) {
+	s.mu.Lock()
+	s.next = next
+	s.mu.Unlock()
+
+	millis := int64(0)
+	if !next.IsZero() {
+		millis = next.UnixMilli()
+	}
+	s.eventEmitter.Emit("outdatedCheckScheduled", strconv.FormatInt(millis, 10))
+}
+
+// Check updates the database and compares the outdated list with the last
+// successful check. When only the database update fails the outdated list is
+// still reported from the local database, but the failure counts towards the
+// backoff like a failed outdated check.
+func (s *OutdatedScheduler) Check() error {
+	s.checkMu.Lock()
+	defer s.checkMu.Unlock()
+
+	s.eventEmitter.Emit("outdatedCheckStarted", "")
+
+	updateErr := s.updateDatabase()
+	if updateErr != nil {
+		s.log(fmt.Sprintf("Scheduled brew update failed: %v", updateErr))
+	}
+
+	packages, err := s.outdated()
+	if err != nil {
+		s.mu.Lock()
+		s.failures++
+		s.mu.Unlock()
+		s.log(fmt.Sprintf("Scheduled outdated check failed: %v", err))
+		s.eventEmitter.Emit("outdatedCheckFailed", err.Error())
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.previous
+	diff := diffOutdated(previous, packages)
+	diff.Initial = previous == nil
+	s.previous = make(map[string]OutdatedPackage, len(packages))
+	for _, pkg := range packages {
+		s.previous[pkg.Name] = pkg
+	}
+	if updateErr != nil {
+		s.failures++
+	} else {
+		s.failures = 0
+	}
+	s.mu.Unlock()
+
+	if diff.Initial || !diff.Empty() {
+		if data, err := json.Marshal(diff); err == nil {
+			s.eventEmitter.Emit("outdatedChanged", string(data))
+		}
+	}
+	if s.onChecked != nil {
+		s.onChecked(diff)
+	}
+	return updateErr
+}
+
+func (s *OutdatedScheduler) log(message string) {
+	if s.logFunc != nil {
+		s.logFunc(message)
+	}
+}
@@ -0,0 +1,183 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNextCheckDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{"disabled", 0, 3, 0},
+		{"no failures", 15 * time.Minute, 0, 15 * time.Minute},
+		{"doubles per failure", 15 * time.Minute, 2, time.Hour},
+		{"capped", 15 * time.Minute, 10, maxOutdatedCheckBackoff},
+		{"interval above the cap", 24 * time.Hour, 3, 24 * time.Hour},
+		{"many failures do not overflow", time.Minute, 1000, maxOutdatedCheckBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCheckDelay(tt.interval, tt.failures); got != tt.want {
+				t.Fatalf("nextCheckDelay(%v, %d) = %v, want %v", tt.interval, tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffOutdated(t *testing.T) {
+	previous := map[string]OutdatedPackage{
+		"wget":    {Name: "wget", CurrentVersion: "1.24.5"},
+		"git":     {Name: "git", CurrentVersion: "2.46.0"},
+		"firefox": {Name: "firefox", CurrentVersion: "131.0"},
+	}
+	current := []OutdatedPackage{
+		{Name: "wget", CurrentVersion: "1.24.5"},
+		{Name: "git", CurrentVersion: "2.47.0"},
+		{Name: "node", CurrentVersion: "22.9.0"},
+		{Name: "python", CurrentVersion: "3.13.0", Pinned: true},
+	}
+
+	diff := diffOutdated(previous, current)
+
+	if names := packageNames(diff.Added); !reflect.DeepEqual(names, []string{"node", "python"}) {
+		t.Errorf("Added = %v", names)
+	}
+	if names := packageNames(diff.Updated); !reflect.DeepEqual(names, []string{"git"}) {
+		t.Errorf("Updated = %v", names)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"firefox"}) {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+	if diff.Count != 3 {
+		t.Errorf("Count = %d, want 3 without the pinned formula", diff.Count)
+	}
+
+	if unchanged := diffOutdated(previous, []OutdatedPackage{
+		previous["firefox"], previous["git"], previous["wget"],
+	}); !unchanged.Empty() {
+		t.Errorf("expected an empty diff, got %+v", unchanged)
+	}
+}
+
+func packageNames(packages []OutdatedPackage) []string {
+	names := []string{}
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+	return names
+}
+
+func TestOutdatedScheduler_Check(t *testing.T) {
+	emitter := &recordingEmitter{}
+	var (
+		outdated  []OutdatedPackage
+		failWith  error
+		updateErr error
+		checked   []OutdatedDiff
+	)
+	scheduler := NewOutdatedScheduler(
+		func() error { return updateErr },
+		func() ([]OutdatedPackage, error) { return outdated, failWith },
+		func() time.Duration { return 15 * time.Minute },
+		emitter,
+		func(diff OutdatedDiff) { checked = append(checked, diff) },
+		nil,
+	)
+
+	decodeLast := func() OutdatedDiff {
+		t.Helper()
+		var diff OutdatedDiff
+		if err := json.Unmarshal([]byte(emitter.last("outdatedChanged")), &diff); err != nil {
+			t.Fatalf("invalid outdatedChanged payload: %v", err)
+		}
+		return diff
+	}
+
+	outdated = []OutdatedPackage{{Name: "wget", CurrentVersion: "1.24.5"}}
+	if err := scheduler.Check(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := decodeLast(); !diff.Initial || diff.Count != 1 || len(diff.Added) != 1 {
+		t.Fatalf("first check should report the whole list, got %+v", diff)
+	}
+
+	// An unchanged list updates the badge but emits nothing.
+	if err := scheduler.Check(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := emitter.count("outdatedChanged"); n != 1 {
+		t.Fatalf("expected no event for an unchanged list, got %d", n)
+	}
+	if len(checked) != 2 {
+		t.Fatalf("expected onChecked after every check, got %d calls", len(checked))
+	}
+
+	failWith = errors.New("brew outdated failed")
+	if err := scheduler.Check(); err == nil {
+		t.Fatal("expected the outdated error")
+	}
+	if !emitter.has("outdatedCheckFailed", "brew outdated failed") {
+		t.Fatal("expected outdatedCheckFailed")
+	}
+	if scheduler.failures != 1 || len(checked) != 2 {
+		t.Fatalf("failed check: failures = %d, onChecked calls = %d", scheduler.failures, len(checked))
+	}
+
+	// A failed database update still reports the local list but keeps
+	// backing off.
+	failWith, updateErr = nil, errors.New("network unreachable")
+	outdated = nil
+	if err := scheduler.Check(); err == nil {
+		t.Fatal("expected the update error")
+	}
+	if diff := decodeLast(); diff.Initial || !reflect.DeepEqual(diff.Removed, []string{"wget"}) || diff.Count != 0 {
+		t.Fatalf("expected wget to be removed, got %+v", diff)
+	}
+	if scheduler.failures != 2 {
+		t.Fatalf("failures = %d, want 2", scheduler.failures)
+	}
+
+	updateErr = nil
+	scheduler.Check()
+	if scheduler.failures != 0 {
+		t.Fatalf("a successful check must reset failures, got %d", scheduler.failures)
+	}
+}
+
+func TestOutdatedScheduler_StartAndStop(t *testing.T) {
+	emitter := &recordingEmitter{}
+	checked := make(chan OutdatedDiff, 1)
+	scheduler := NewOutdatedScheduler(
+		func() error { return nil },
+		func() ([]OutdatedPackage, error) { return []OutdatedPackage{}, nil },
+		func() time.Duration { return 0 },
+		emitter,
+		func(diff OutdatedDiff) { checked <- diff },
+		nil,
+	)
+
+	scheduler.Start(context.Background(), 0)
+	select {
+	case <-checked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first check did not run")
+	}
+	scheduler.Stop()
+
+	// With checks disabled nothing is scheduled after the first one.
+	if !scheduler.NextCheck().IsZero() {
+		t.Fatalf("expected no next check, got %v", scheduler.NextCheck())
+	}
+	if got := emitter.last("outdatedCheckScheduled"); got != "0" {
+		t.Fatalf("outdatedCheckScheduled = %q, want \"0\"", got)
+	}
+}
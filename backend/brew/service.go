@@ -5,13 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"regexp"
-	"runtime"
 	"strings"
 	"time"
-
-	"WailBrew/backend/system"
 )
 
 // Service provides a high-level interface for brew operations
@@ -23,10 +19,11 @@ type Service interface {
 	// Package listing
 	GetAllBrewPackages() [][]string
 	GetAllBrewCasks() [][]string
-	GetBrewPackages() [][]string
-	GetBrewCasks() [][]string
-	GetBrewLeaves() []string
-	GetBrewTaps() [][]string
+	GetInstalledFormulae() ([]InstalledFormula, error)
+	GetInstalledCasks() ([]InstalledCask, error)
+	GetLeaves() ([]string, error)
+	GetInventory() (*Inventory, error)
+	GetTaps() ([]Tap, error)
 	GetBrewTapInfo(repositoryName string) string
 
 	// Package sizes
@@ -40,17 +37,30 @@ type Service interface {
 	CheckForNewPackages() (*NewPackagesInfo, error)
 
 	// Outdated packages
-	GetBrewUpdatablePackages() [][]string
+	GetOutdatedPackages() ([]OutdatedPackage, error)
 	IsPackageCask(packageName string) bool
 	IsAppAlreadyExistsError(stderrOutput string) bool
 	ExtractFailedPackagesFromError(stderrOutput string) []string
 
 	// Actions
 	InstallBrewPackage(ctx context.Context, packageName string) string
+	InstallBrewPackageVersion(ctx context.Context, packageName, version string, pin bool) string
 	RemoveBrewPackage(ctx context.Context, packageName string, zap bool) string
 	UpdateBrewPackage(ctx context.Context, packageName string) string
 	UpdateSelectedBrewPackages(ctx context.Context, packageNames []string) string
+	UpgradeSelectedPackages(ctx context.Context, packageNames []string) UpgradeResult
 	UpdateAllBrewPackages(ctx context.Context) string
+	UpgradeAllArgs() ([]string, error)
+	PinBrewPackage(ctx context.Context, packageName string) string
+	UnpinBrewPackage(ctx context.Context, packageName string) string
+	PreviewUpgrade(packageNames []string) (*UpgradePreview, error)
+	PreviewInstall(ctx context.Context, packageName string) (*InstallPreview, error)
+	ReinstallBrewPackage(ctx context.Context, packageName string) string
+	LinkBrewPackage(ctx context.Context, packageName string, overwrite, force bool) string
+	UnlinkBrewPackage(ctx context.Context, packageName string) string
+	PreviewLink(packageName string) (*LinkPreview, error)
+	GetFormulaFamilies() ([]FormulaFamily, error)
+	SwitchFormulaVersion(ctx context.Context, family, target string) string
 
 	// Tap operations
 	TapBrewRepository(ctx context.Context, repositoryName, repositoryURL string) string
@@ -58,7 +68,7 @@ type Service interface {
 	TrustBrewTap(ctx context.Context, tapName string) string
 
 	// Services operations
-	GetBrewServices() [][]string
+	GetServices() ([]ServiceEntry, error)
 	GetBrewServiceInfo(name string) string
 	GetBrewServicePid(name string) int
 	StartBrewService(ctx context.Context, name string) string
@@ -71,6 +81,9 @@ type Service interface {
 	GetBrewPackageInfo(packageName string) string
 	GetInstalledDependencies(packageName string) []string
 	GetInstalledDependents(packageName string) []string
+	GetDependents(packageName string, transitive bool) ([]string, error)
+	GetDependencyTree(packageName string) (*DependencyNode, error)
+	PlanUninstall(packageNames []string) (*UninstallPlan, error)
 
 	// Other operations
 	RunBrewDoctor() string
@@ -78,11 +91,35 @@ type Service interface {
 	GetBrewCleanupDryRun() (string, error)
 	RunBrewCleanupDryRun() string
 	RunBrewCleanup() string
+	GetAutoremoveDryRun() ([]OrphanedFormula, error)
+	RunAutoremove(ctx context.Context) string
+	MarkInstalledOnRequest(ctx context.Context, packageNames []string) string
 	GetHomebrewVersion() (string, error)
 	CheckHomebrewUpdate() (map[string]interface{}, error)
 	UpdateHomebrew(ctx context.Context) string
 	GetHomebrewCaskVersion() (string, error)
 	ExportBrewfile(filePath string) error
+	ExportFilteredBrewfile(filePath string, options BrewfileExportOptions) error
+	PreviewBrewfile(filePath string) (*BrewfilePreview, error)
+	ApplyBrewfile(ctx context.Context, filePath string) string
+	GetBrewfileCleanup(filePath string) ([]BrewfileCleanupItem, error)
+	RunBrewfileCleanup(ctx context.Context, filePath string, confirmed []string) string
+
+	// Snapshots
+	CaptureSnapshot() (*Snapshot, error)
+	RestoreSnapshot(ctx context.Context, snapshot *Snapshot) string
+	ExportDependencyGraph(filePath string, options GraphExportOptions) error
+
+	// Operation queue - every mutating brew command runs through it
+	CancelOperation(id string) bool
+	GetOperationQueue() []Operation
+	MoveQueuedOperation(id string, index int) error
+	RemoveQueuedOperation(id string) error
+
+	// Operation journal - persisted history of the queued operations above
+	GetOperationHistory(filter JournalFilter) (*JournalPage, error)
+	GetLastUndoableOperation() (*JournalEntry, error)
+	UndoLastOperation(ctx context.Context) string
 
 	// Cache management
 	ClearCache()
@@ -96,11 +133,9 @@ type NewPackagesInfo struct {
 
 // serviceImpl implements the Service interface
 type serviceImpl struct {
-	executor        *Executor
-	getBrewEnvFunc  func() []string
+	runner          Runner
 	logFunc         func(string)
 	validateFunc    func() error
-	brewPath        string
 	getBackendMsg   func(string, map[string]string) string
 	eventEmitter    EventEmitter
 	getOutdatedFlag func() string
@@ -108,41 +143,57 @@ type serviceImpl struct {
 	parseWarnings   func(string) map[string]string
 
 	// Module services
-	listService     *ListService
-	sizeService     *SizeService
-	databaseService *DatabaseService
-	outdatedService *OutdatedService
-	actionsService  *ActionsService
-	tapService      *TapService
-	servicesService *ServicesService
-	startupService  *StartupService
+	listService       *ListService
+	sizeService       *SizeService
+	databaseService   *DatabaseService
+	outdatedService   *OutdatedService
+	actionsService    *ActionsService
+	tapService        *TapService
+	servicesService   *ServicesService
+	autoremoveService *AutoremoveService
+	bundleService     *BundleService
+	startupService    *StartupService
+
+	// queue serializes every mutating command so two never contend for
+	// Homebrew's lock. The caller owns it and hands the same queue to every
+	// service it creates, so replacing the service cannot start a second one.
+	queue   *OperationQueue
+	journal *OperationJournal
 }
 
 // NewService creates a new brew service
 func NewService(
-	executor *Executor,
+	runner Runner,
 	brewPath string,
-	getBrewEnvFunc func() []string,
 	logFunc func(string),
 	validateFunc func() error,
 	getBackendMsg func(string, map[string]string) string,
 	eventEmitter EventEmitter,
 	getOutdatedFlag func() string,
 	getCustomOutdatedArgs func() string,
+	getUpgradePolicies func() map[string]string,
 	extractJSON func(string) (string, string, error),
 	parseWarnings func(string) map[string]string,
 	getNoQuarantine func() bool,
 	getAutoRelaunch func() bool,
+	queue *OperationQueue,
+	journal *OperationJournal,
 ) Service {
-	// Create database service first (needs executor)
-	databaseService := NewDatabaseService(executor)
+	// Streamed commands are recorded for the journal as they finish; nil
+	// turns the journal off.
+	if journal != nil {
+		runner = journaledRunner{runner}
+	}
+
+	// Create database service first (needs runner)
+	databaseService := NewDatabaseService(runner)
 
 	// Probed lazily on the first read failure, so no cost on the happy path.
-	capabilities := NewCapabilityDetector(executor)
+	capabilities := NewCapabilityDetector(runner)
 
 	// Create list service
 	listService := NewListService(
-		executor,
+		runner,
 		validateFunc,
 		func() map[string]bool { return databaseService.knownPackages },
 		func() { databaseService.knownPackagesMux.Lock() },
@@ -163,11 +214,11 @@ func NewService(
 	)
 
 	// Create size service
-	sizeService := NewSizeService(executor, logFunc, extractJSON)
+	sizeService := NewSizeService(runner, logFunc, extractJSON)
 
 	// Create outdated service
 	outdatedService := NewOutdatedService(
-		executor,
+		runner,
 		validateFunc,
 		logFunc,
 		extractJSON,
@@ -177,13 +228,14 @@ func NewService(
 		},
 		getOutdatedFlag,
 		getCustomOutdatedArgs,
+		getUpgradePolicies,
 		getBackendMsg,
 	)
 
 	// Create actions service
 	actionsService := NewActionsService(
+		runner,
 		brewPath,
-		getBrewEnvFunc,
 		getBackendMsg,
 		eventEmitter,
 		outdatedService.IsPackageCask,
@@ -193,37 +245,61 @@ func NewService(
 		getOutdatedFlag,
 		getNoQuarantine,
 		getAutoRelaunch,
+		outdatedService.OutdatedPackages,
+		getUpgradePolicies,
 	)
 
 	// Create tap service
-	tapService := NewTapService(brewPath, getBrewEnvFunc, getBackendMsg, eventEmitter)
+	tapService := NewTapService(runner, getBackendMsg, eventEmitter)
 
 	// Create services service
-	servicesService := NewServicesService(executor, brewPath, getBrewEnvFunc, getBackendMsg, eventEmitter)
+	servicesService := NewServicesService(runner, getBackendMsg, eventEmitter)
+
+	// Create autoremove service
+	autoremoveService := NewAutoremoveService(runner, getBackendMsg, eventEmitter, sizeService.GetPackageSizes)
+
+	// Create bundle service
+	bundleService := NewBundleService(runner, getBackendMsg, eventEmitter, listService.DependencyGraph, sizeService.GetPackageSizes)
 
 	// Create startup service for optimized initial data loading
-	startupService := NewStartupService(listService, outdatedService, databaseService)
+	startupService := NewStartupService(listService, outdatedService, func() (string, error) {
+		return queuedDatabaseUpdate(queue, databaseService)
+	})
 
 	return &serviceImpl{
-		executor:        executor,
-		getBrewEnvFunc:  getBrewEnvFunc,
-		logFunc:         logFunc,
-		validateFunc:    validateFunc,
-		brewPath:        brewPath,
-		getBackendMsg:   getBackendMsg,
-		eventEmitter:    eventEmitter,
-		getOutdatedFlag: getOutdatedFlag,
-		extractJSON:     extractJSON,
-		parseWarnings:   parseWarnings,
-		listService:     listService,
-		sizeService:     sizeService,
-		databaseService: databaseService,
-		outdatedService: outdatedService,
-		actionsService:  actionsService,
-		tapService:      tapService,
-		servicesService: servicesService,
-		startupService:  startupService,
+		runner:            runner,
+		logFunc:           logFunc,
+		validateFunc:      validateFunc,
+		getBackendMsg:     getBackendMsg,
+		eventEmitter:      eventEmitter,
+		getOutdatedFlag:   getOutdatedFlag,
+		extractJSON:       extractJSON,
+		parseWarnings:     parseWarnings,
+		listService:       listService,
+		sizeService:       sizeService,
+		databaseService:   databaseService,
+		outdatedService:   outdatedService,
+		actionsService:    actionsService,
+		tapService:        tapService,
+		servicesService:   servicesService,
+		autoremoveService: autoremoveService,
+		bundleService:     bundleService,
+		startupService:    startupService,
+		queue:             queue,
+		journal:           journal,
+	}
+}
+
+// queuedDatabaseUpdate runs `brew update` through the operation queue.
+func queuedDatabaseUpdate(queue *OperationQueue, databaseService *DatabaseService) (string, error) {
+	var output string
+	var err error
+	if qerr := queue.Do(context.Background(), "database-update", "", func(context.Context) {
+		output, err = databaseService.UpdateBrewDatabaseWithOutput()
+	}); qerr != nil {
+		return "", qerr
 	}
+	return output, err
 }
 
 // Startup methods
@@ -237,7 +313,76 @@ func (s *serviceImpl) GetStartupDataWithUpdate() *StartupData {
 
 // Cache management
 func (s *serviceImpl) ClearCache() {
-	s.executor.ClearCache()
+	s.runner.ClearCache()
+	s.listService.InvalidateDependencyGraph()
+}
+
+// mutate runs an operation that changes installed state through the queue and
+// then drops everything derived from the old state, the cached command output
+// as well as the dependency graph, so the next read sees the result. fn
+// reports whether the operation succeeded, which the journal records.
+func (s *serviceImpl) mutate(ctx context.Context, kind, target string, fn func(context.Context) (string, bool)) string {
+	message, _ := s.mutateOK(ctx, kind, target, fn)
+	return message
+}
+
+// mutateOK is mutate also returning whether the operation succeeded. An
+// operation removed from the queue before it ran did not succeed.
+func (s *serviceImpl) mutateOK(ctx context.Context, kind, target string, fn func(context.Context) (string, bool)) (string, bool) {
+	defer s.ClearCache()
+	if s.journal != nil {
+		fn = s.journaled(kind, target, fn)
+	}
+	ok := false
+	message := s.queue.Run(ctx, kind, target, completionEvents[kind], func(ctx context.Context) string {
+		var message string
+		message, ok = fn(ctx)
+		return message
+	})
+	return message, ok
+}
+
+// completionEvents is the event each kind of operation announces its outcome
+// on. Kinds without one report only through their return value, or, like
+// undo, emit their own completion once the queued step returns.
+var completionEvents = map[string]string{
+	"install":          "packageInstallComplete",
+	"install-version":  "packageInstallComplete",
+	"uninstall":        "packageUninstallComplete",
+	"reinstall":        "packageReinstallComplete",
+	"upgrade":          "packageUpdateComplete",
+	"upgrade-selected": "packageUpdateComplete",
+	"upgrade-all":      "packageUpdateComplete",
+	"link":             "packageLinkComplete",
+	"unlink":           "packageLinkComplete",
+	"tap":              "repositoryTapComplete",
+	"untap":            "repositoryUntapComplete",
+	"trust":            "repositoryTrustComplete",
+	"autoremove":       "autoremoveComplete",
+	"brewfile":         "brewfileApplyComplete",
+	"switch-version":   "formulaSwitchComplete",
+	"service-start":    "serviceActionComplete",
+	"service-stop":     "serviceActionComplete",
+	"service-restart":  "serviceActionComplete",
+	"service-run":      "serviceActionComplete",
+	"homebrew-update":  "homebrewUpdateComplete",
+}
+
+// Operation queue methods
+func (s *serviceImpl) CancelOperation(id string) bool {
+	return s.queue.Cancel(id)
+}
+
+func (s *serviceImpl) GetOperationQueue() []Operation {
+	return s.queue.Jobs()
+}
+
+func (s *serviceImpl) MoveQueuedOperation(id string, index int) error {
+	return s.queue.Move(id, index)
+}
+
+func (s *serviceImpl) RemoveQueuedOperation(id string) error {
+	return s.queue.Remove(id)
 }
 
 // Package listing methods
@@ -249,20 +394,24 @@ func (s *serviceImpl) GetAllBrewCasks() [][]string {
 	return s.listService.GetAllBrewCasks()
 }
 
-func (s *serviceImpl) GetBrewPackages() [][]string {
-	return s.listService.GetBrewPackages()
+func (s *serviceImpl) GetInstalledFormulae() ([]InstalledFormula, error) {
+	return s.listService.InstalledFormulae()
 }
 
-func (s *serviceImpl) GetBrewCasks() [][]string {
-	return s.listService.GetBrewCasks()
+func (s *serviceImpl) GetInstalledCasks() ([]InstalledCask, error) {
+	return s.listService.InstalledCasks()
 }
 
-func (s *serviceImpl) GetBrewLeaves() []string {
-	return s.listService.GetBrewLeaves()
+func (s *serviceImpl) GetLeaves() ([]string, error) {
+	return s.listService.Leaves()
 }
 
-func (s *serviceImpl) GetBrewTaps() [][]string {
-	return s.listService.GetBrewTaps()
+func (s *serviceImpl) GetInventory() (*Inventory, error) {
+	return s.listService.LoadInventory()
+}
+
+func (s *serviceImpl) GetTaps() ([]Tap, error) {
+	return s.listService.Taps()
 }
 
 func (s *serviceImpl) GetBrewTapInfo(repositoryName string) string {
@@ -280,11 +429,12 @@ func (s *serviceImpl) GetBrewCaskSizes(caskNames []string) map[string]string {
 
 // Database methods
 func (s *serviceImpl) UpdateBrewDatabase() error {
-	return s.databaseService.UpdateBrewDatabase()
+	_, err := queuedDatabaseUpdate(s.queue, s.databaseService)
+	return err
 }
 
 func (s *serviceImpl) UpdateBrewDatabaseWithOutput() (string, error) {
-	return s.databaseService.UpdateBrewDatabaseWithOutput()
+	return queuedDatabaseUpdate(s.queue, s.databaseService)
 }
 
 func (s *serviceImpl) ParseNewPackagesFromUpdateOutput(output string) *NewPackagesInfo {
@@ -296,8 +446,8 @@ func (s *serviceImpl) CheckForNewPackages() (*NewPackagesInfo, error) {
 }
 
 // Outdated package methods
-func (s *serviceImpl) GetBrewUpdatablePackages() [][]string {
-	return s.outdatedService.GetBrewUpdatablePackages()
+func (s *serviceImpl) GetOutdatedPackages() ([]OutdatedPackage, error) {
+	return s.outdatedService.OutdatedPackages()
 }
 
 func (s *serviceImpl) IsPackageCask(packageName string) bool {
@@ -314,41 +464,115 @@ func (s *serviceImpl) ExtractFailedPackagesFromError(stderrOutput string) []stri
 
 // Action methods
 func (s *serviceImpl) InstallBrewPackage(ctx context.Context, packageName string) string {
-	return s.actionsService.InstallBrewPackage(ctx, packageName)
+	return s.mutate(ctx, "install", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.installPackage(ctx, packageName)
+	})
+}
+
+func (s *serviceImpl) InstallBrewPackageVersion(ctx context.Context, packageName, version string, pin bool) string {
+	return s.mutate(ctx, "install-version", packageName+"@"+version, func(ctx context.Context) (string, bool) {
+		return s.actionsService.installPackageVersion(ctx, packageName, version, pin)
+	})
 }
 
 func (s *serviceImpl) RemoveBrewPackage(ctx context.Context, packageName string, zap bool) string {
-	return s.actionsService.RemoveBrewPackage(ctx, packageName, zap)
+	return s.mutate(ctx, "uninstall", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.removePackage(ctx, packageName, zap)
+	})
 }
 
 func (s *serviceImpl) UpdateBrewPackage(ctx context.Context, packageName string) string {
-	return s.actionsService.UpdateBrewPackage(ctx, packageName)
+	return s.mutate(ctx, "upgrade", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.updatePackage(ctx, packageName)
+	})
 }
 
 func (s *serviceImpl) UpdateSelectedBrewPackages(ctx context.Context, packageNames []string) string {
-	return s.actionsService.UpdateSelectedBrewPackages(ctx, packageNames)
+	return s.UpgradeSelectedPackages(ctx, packageNames).Message
+}
+
+// UpgradeSelectedPackages is UpdateSelectedBrewPackages reporting the outcome
+// of each package. An upgrade removed from the queue before it ran fails
+// every package.
+func (s *serviceImpl) UpgradeSelectedPackages(ctx context.Context, packageNames []string) UpgradeResult {
+	var result UpgradeResult
+	ran := false
+	message := s.mutate(ctx, "upgrade-selected", strings.Join(packageNames, " "), func(ctx context.Context) (string, bool) {
+		result, ran = s.actionsService.UpgradeSelectedPackages(ctx, packageNames), true
+		return result.Message, result.Success()
+	})
+	if !ran {
+		return uniformUpgradeResult(packageNames, message, false)
+	}
+	return result
 }
 
 func (s *serviceImpl) UpdateAllBrewPackages(ctx context.Context) string {
-	return s.actionsService.UpdateAllBrewPackages(ctx)
+	return s.mutate(ctx, "upgrade-all", "", func(ctx context.Context) (string, bool) {
+		return s.actionsService.updateAllPackages(ctx)
+	})
+}
+
+func (s *serviceImpl) UpgradeAllArgs() ([]string, error) {
+	return s.actionsService.UpgradeAllArgs()
+}
+
+func (s *serviceImpl) PinBrewPackage(ctx context.Context, packageName string) string {
+	return s.mutate(ctx, "pin", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.setPinned(ctx, packageName, BuildPinArgs(packageName), "backend.pin")
+	})
+}
+
+func (s *serviceImpl) UnpinBrewPackage(ctx context.Context, packageName string) string {
+	return s.mutate(ctx, "unpin", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.setPinned(ctx, packageName, BuildUnpinArgs(packageName), "backend.unpin")
+	})
+}
+
+func (s *serviceImpl) ReinstallBrewPackage(ctx context.Context, packageName string) string {
+	return s.mutate(ctx, "reinstall", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.reinstallPackage(ctx, packageName)
+	})
+}
+
+func (s *serviceImpl) LinkBrewPackage(ctx context.Context, packageName string, overwrite, force bool) string {
+	return s.mutate(ctx, "link", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.linkPackage(ctx, packageName, overwrite, force)
+	})
+}
+
+func (s *serviceImpl) UnlinkBrewPackage(ctx context.Context, packageName string) string {
+	return s.mutate(ctx, "unlink", packageName, func(ctx context.Context) (string, bool) {
+		return s.actionsService.unlinkPackage(ctx, packageName)
+	})
+}
+
+func (s *serviceImpl) PreviewLink(packageName string) (*LinkPreview, error) {
+	return s.actionsService.PreviewLink(packageName)
 }
 
 // Tap methods
 func (s *serviceImpl) TapBrewRepository(ctx context.Context, repositoryName, repositoryURL string) string {
-	return s.tapService.TapBrewRepository(ctx, repositoryName, repositoryURL)
+	return s.mutate(ctx, "tap", repositoryName, func(ctx context.Context) (string, bool) {
+		return s.tapService.tap(ctx, repositoryName, repositoryURL)
+	})
 }
 
 func (s *serviceImpl) UntapBrewRepository(ctx context.Context, repositoryName string) string {
-	return s.tapService.UntapBrewRepository(ctx, repositoryName)
+	return s.mutate(ctx, "untap", repositoryName, func(ctx context.Context) (string, bool) {
+		return s.tapService.untap(ctx, repositoryName)
+	})
 }
 
 func (s *serviceImpl) TrustBrewTap(ctx context.Context, tapName string) string {
-	return s.tapService.TrustBrewTap(ctx, tapName)
+	return s.mutate(ctx, "trust", tapName, func(ctx context.Context) (string, bool) {
+		return s.tapService.trust(ctx, tapName)
+	})
 }
 
 // Services methods
-func (s *serviceImpl) GetBrewServices() [][]string {
-	return s.servicesService.GetBrewServices()
+func (s *serviceImpl) GetServices() ([]ServiceEntry, error) {
+	return s.servicesService.Services()
 }
 
 func (s *serviceImpl) GetBrewServiceInfo(name string) string {
@@ -360,24 +584,32 @@ func (s *serviceImpl) GetBrewServicePid(name string) int {
 }
 
 func (s *serviceImpl) StartBrewService(ctx context.Context, name string) string {
-	return s.servicesService.StartBrewService(ctx, name)
+	return s.mutate(ctx, "service-start", name, func(ctx context.Context) (string, bool) {
+		return s.servicesService.runServiceAction(ctx, "start", name)
+	})
 }
 
 func (s *serviceImpl) StopBrewService(ctx context.Context, name string) string {
-	return s.servicesService.StopBrewService(ctx, name)
+	return s.mutate(ctx, "service-stop", name, func(ctx context.Context) (string, bool) {
+		return s.servicesService.runServiceAction(ctx, "stop", name)
+	})
 }
 
 func (s *serviceImpl) RestartBrewService(ctx context.Context, name string) string {
-	return s.servicesService.RestartBrewService(ctx, name)
+	return s.mutate(ctx, "service-restart", name, func(ctx context.Context) (string, bool) {
+		return s.servicesService.runServiceAction(ctx, "restart", name)
+	})
 }
 
 func (s *serviceImpl) RunBrewService(ctx context.Context, name string) string {
-	return s.servicesService.RunBrewService(ctx, name)
+	return s.mutate(ctx, "service-run", name, func(ctx context.Context) (string, bool) {
+		return s.servicesService.runServiceAction(ctx, "run", name)
+	})
 }
 
 // Package info methods - these can be extracted to a separate module later
 func (s *serviceImpl) GetBrewPackageInfoAsJson(packageName string) map[string]interface{} {
-	output, err := s.executor.Run("info", "--json=v2", packageName)
+	output, err := s.runner.Run("info", "--json=v2", packageName)
 	if err != nil {
 		return map[string]interface{}{
 			"error": fmt.Sprintf("Failed to get package info: %v", err),
@@ -440,55 +672,55 @@ func (s *serviceImpl) GetBrewPackageInfoAsJson(packageName string) map[string]in
 }
 
 func (s *serviceImpl) GetBrewPackageInfo(packageName string) string {
-	output, err := s.executor.Run("info", packageName)
+	output, err := s.runner.Run("info", packageName)
 	if err != nil {
 		return fmt.Sprintf("Error: Failed to get package info: %v", err)
 	}
 	return string(output)
 }
 
+// GetInstalledDependencies returns every installed package name depends on
+// at runtime, like `brew deps --installed`.
 func (s *serviceImpl) GetInstalledDependencies(packageName string) []string {
-	output, err := s.executor.Run("deps", packageName, "--installed")
+	graph, err := s.listService.DependencyGraph()
 	if err != nil {
 		return []string{}
 	}
-	raw := strings.TrimSpace(string(output))
-	if raw == "" {
-		return []string{}
-	}
-	lines := strings.Split(raw, "\n")
-	deps := make([]string, 0, len(lines))
-	for _, line := range lines {
-		dep := strings.TrimSpace(line)
-		if dep != "" {
-			deps = append(deps, dep)
-		}
-	}
-	return deps
+	return graph.Dependencies(packageName, true, false)
 }
 
+// GetInstalledDependents returns the installed packages that depend directly
+// on name, like `brew uses --installed`.
 func (s *serviceImpl) GetInstalledDependents(packageName string) []string {
-	output, err := s.executor.Run("uses", packageName, "--installed")
+	dependents, err := s.GetDependents(packageName, false)
 	if err != nil {
 		return []string{}
 	}
-	raw := strings.TrimSpace(string(output))
-	if raw == "" {
-		return []string{}
+	return dependents
+}
+
+func (s *serviceImpl) GetDependents(packageName string, transitive bool) ([]string, error) {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return nil, err
 	}
-	lines := strings.Split(raw, "\n")
-	deps := make([]string, 0, len(lines))
-	for _, line := range lines {
-		dep := strings.TrimSpace(line)
-		if dep != "" {
-			deps = append(deps, dep)
-		}
+	return graph.Dependents(packageName, transitive), nil
+}
+
+func (s *serviceImpl) GetDependencyTree(packageName string) (*DependencyNode, error) {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return nil, err
 	}
-	return deps
+	tree := graph.Tree(packageName)
+	if tree == nil {
+		return nil, fmt.Errorf("%s is not installed", packageName)
+	}
+	return tree, nil
 }
 
 func (s *serviceImpl) RunBrewDoctor() string {
-	output, err := s.executor.Run("doctor")
+	output, err := s.runner.Run("doctor")
 	outputStr := string(output)
 	if err != nil {
 		if strings.Contains(outputStr, "Please note that these warnings are just used to help the Homebrew maintainers") ||
@@ -533,7 +765,7 @@ func (s *serviceImpl) GetDeprecatedFormulae(doctorOutput string) []string {
 }
 
 func (s *serviceImpl) GetBrewCleanupDryRun() (string, error) {
-	output, err := s.executor.RunWithTimeout(120*time.Second, "cleanup", "--dry-run")
+	output, err := s.runner.RunWithTimeout(120*time.Second, "cleanup", "--dry-run")
 	// Don't discard output on error — brew cleanup --dry-run often exits non-zero
 	// due to warnings but still produces valid output with the summary line.
 	if err != nil && len(output) == 0 {
@@ -556,7 +788,7 @@ func (s *serviceImpl) GetBrewCleanupDryRun() (string, error) {
 }
 
 func (s *serviceImpl) RunBrewCleanupDryRun() string {
-	output, err := s.executor.RunWithTimeout(120*time.Second, "cleanup", "--dry-run")
+	output, err := s.runner.RunWithTimeout(120*time.Second, "cleanup", "--dry-run")
 	if err != nil {
 		return fmt.Sprintf("Error running brew cleanup --dry-run: %v\n\nOutput:\n%s", err, string(output))
 	}
@@ -564,15 +796,35 @@ func (s *serviceImpl) RunBrewCleanupDryRun() string {
 }
 
 func (s *serviceImpl) RunBrewCleanup() string {
-	output, err := s.executor.Run("cleanup")
+	return s.mutate(context.Background(), "cleanup", "", func(context.Context) (string, bool) {
+		return s.runBrewCleanup()
+	})
+}
+
+func (s *serviceImpl) GetAutoremoveDryRun() ([]OrphanedFormula, error) {
+	return s.autoremoveService.Orphans()
+}
+
+func (s *serviceImpl) RunAutoremove(ctx context.Context) string {
+	return s.mutate(ctx, "autoremove", "", s.autoremoveService.runAutoremove)
+}
+
+func (s *serviceImpl) MarkInstalledOnRequest(ctx context.Context, packageNames []string) string {
+	return s.mutate(ctx, "tab", strings.Join(packageNames, " "), func(ctx context.Context) (string, bool) {
+		return s.autoremoveService.markInstalledOnRequest(ctx, packageNames)
+	})
+}
+
+func (s *serviceImpl) runBrewCleanup() (string, bool) {
+	output, err := s.runner.Run("cleanup")
 	if err != nil {
-		return fmt.Sprintf("Error running brew cleanup: %v\n\nOutput:\n%s", err, string(output))
+		return fmt.Sprintf("Error running brew cleanup: %v\n\nOutput:\n%s", err, string(output)), false
 	}
-	return string(output)
+	return string(output), true
 }
 
 func (s *serviceImpl) GetHomebrewVersion() (string, error) {
-	output, err := s.executor.Run("--version")
+	output, err := s.runner.Run("--version")
 	if err != nil {
 		return "", fmt.Errorf("failed to get Homebrew version: %w", err)
 	}
@@ -601,40 +853,26 @@ func (s *serviceImpl) CheckHomebrewUpdate() (map[string]interface{}, error) {
 		"latestVersion":  currentVersion,
 	}
 
-	// Check Homebrew's git repository status
-	brewDir := ""
-	if runtime.GOOS == "darwin" {
-		// Check for Workbrew first (enterprise users)
-		if _, err := os.Stat("/opt/workbrew"); err == nil {
-			brewDir = "/opt/workbrew"
-		} else if runtime.GOARCH == "arm64" {
-			brewDir = "/opt/homebrew"
-		} else {
-			brewDir = "/usr/local"
-		}
-	}
-
-	if brewDir != "" {
-		gitDir := fmt.Sprintf("%s/.git", brewDir)
-		if _, err := os.Stat(gitDir); err == nil {
-			cmd := exec.Command("git", "-C", brewDir, "rev-list", "--count", "HEAD..origin/HEAD")
-			system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-			behindOutput, _ := cmd.CombinedOutput()
-
-			behindCount := strings.TrimSpace(string(behindOutput))
-			if behindCount != "" && behindCount != "0" {
-				cmd = exec.Command("git", "-C", brewDir, "describe", "--tags", "origin/HEAD")
-				system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-				latestTag, _ := cmd.CombinedOutput()
-				latestVersion := strings.TrimSpace(string(latestTag))
-
-				if latestVersion != "" {
-					result["isUpToDate"] = false
-					result["latestVersion"] = latestVersion
-				} else {
-					result["isUpToDate"] = false
-					result["latestVersion"] = "latest"
-				}
+	// Check Homebrew's git repository status. `brew --repository` resolves the
+	// checkout for every prefix (Apple Silicon, Intel, Workbrew); when it is not
+	// a git checkout the git calls fail and the installed version is reported
+	// as current.
+	repoOutput, err := s.runner.RunStdoutOnly("--repository")
+	brewDir := strings.TrimSpace(string(repoOutput))
+	if err == nil && brewDir != "" {
+		behindOutput, err := s.runner.RunTool("git", "-C", brewDir, "rev-list", "--count", "HEAD..origin/HEAD")
+
+		behindCount := strings.TrimSpace(string(behindOutput))
+		if err == nil && behindCount != "" && behindCount != "0" {
+			latestTag, err := s.runner.RunTool("git", "-C", brewDir, "describe", "--tags", "origin/HEAD")
+			latestVersion := strings.TrimSpace(string(latestTag))
+
+			if err == nil && latestVersion != "" {
+				result["isUpToDate"] = false
+				result["latestVersion"] = latestVersion
+			} else {
+				result["isUpToDate"] = false
+				result["latestVersion"] = "latest"
 			}
 		}
 	}
@@ -643,13 +881,14 @@ func (s *serviceImpl) CheckHomebrewUpdate() (map[string]interface{}, error) {
 }
 
 func (s *serviceImpl) UpdateHomebrew(ctx context.Context) string {
+	return s.mutate(ctx, "homebrew-update", "", s.updateHomebrew)
+}
+
+func (s *serviceImpl) updateHomebrew(ctx context.Context) (string, bool) {
 	startMessage := s.getBackendMsg("backend.homebrewUpdate.start", map[string]string{})
 	s.eventEmitter.Emit("homebrewUpdateProgress", startMessage)
 
-	cmd := exec.Command(s.brewPath, "update")
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, []string{"update"},
 		func(line string) {
 			s.eventEmitter.Emit("homebrewUpdateProgress", s.getBackendMsg("backend.homebrewUpdate.output", map[string]string{"line": line}))
 		},
@@ -662,26 +901,28 @@ func (s *serviceImpl) UpdateHomebrew(ctx context.Context) string {
 	case phaseStdoutPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("homebrewUpdateProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("homebrewUpdateProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingHomebrewUpdate", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("homebrewUpdateProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	var finalMessage string
-	if phase == phaseRun {
+	if phase == phaseCancelled {
+		finalMessage = s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+	} else if phase == phaseRun {
 		finalMessage = s.getBackendMsg("backend.homebrewUpdate.failed", map[string]string{"error": err.Error()})
 	} else {
 		finalMessage = s.getBackendMsg("backend.homebrewUpdate.success", map[string]string{})
 	}
 
 	s.eventEmitter.Emit("homebrewUpdateComplete", finalMessage)
-	return finalMessage
+	return finalMessage, phase == phaseNone
 }
 
 func (s *serviceImpl) GetHomebrewCaskVersion() (string, error) {
@@ -689,7 +930,7 @@ func (s *serviceImpl) GetHomebrewCaskVersion() (string, error) {
 		return "", fmt.Errorf("homebrew validation failed: %v", err)
 	}
 
-	infoOutput, err := s.executor.RunStdoutOnly("info", "--cask", "--json=v2", "wailbrew")
+	infoOutput, err := s.runner.RunStdoutOnly("info", "--cask", "--json=v2", "wailbrew")
 	if err != nil {
 		return "", fmt.Errorf("failed to get Homebrew Cask info: %v", err)
 	}
@@ -718,13 +959,123 @@ func (s *serviceImpl) GetHomebrewCaskVersion() (string, error) {
 }
 
 func (s *serviceImpl) ExportBrewfile(filePath string) error {
-	cmd := exec.Command(s.brewPath, "bundle", "dump", "--file="+filePath, "--force")
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
+	// bundle dump walks every installed package, so allow well past the default timeout
+	if _, err := s.runner.RunNoCacheWithTimeout(5*time.Minute, "bundle", "dump", "--file="+filePath, "--force"); err != nil {
+		return fmt.Errorf("brew bundle dump failed: %v", err)
+	}
 
-	output, err := cmd.CombinedOutput()
+	return nil
+}
+
+// ExportFilteredBrewfile writes a Brewfile built from the dependency graph, so
+// unlike ExportBrewfile it can leave packages out and needs no brew bundle.
+func (s *serviceImpl) ExportFilteredBrewfile(filePath string, options BrewfileExportOptions) error {
+	graph, err := s.listService.DependencyGraph()
 	if err != nil {
-		return fmt.Errorf("brew bundle dump failed: %v\nOutput: %s", err, string(output))
+		return err
 	}
+	return graph.Brewfile(options).WriteFile(filePath)
+}
 
+func (s *serviceImpl) PreviewBrewfile(filePath string) (*BrewfilePreview, error) {
+	return s.bundleService.PreviewBrewfile(filePath)
+}
+
+func (s *serviceImpl) ApplyBrewfile(ctx context.Context, filePath string) string {
+	return s.mutate(ctx, "brewfile", filePath, func(ctx context.Context) (string, bool) {
+		return s.bundleService.applyBrewfile(ctx, filePath)
+	})
+}
+
+func (s *serviceImpl) GetBrewfileCleanup(filePath string) ([]BrewfileCleanupItem, error) {
+	return s.bundleService.BrewfileCleanup(filePath)
+}
+
+// RunBrewfileCleanup removes the confirmed items the Brewfile does not list
+// through the regular uninstall and untap operations, each queued on its own.
+// Cancelling one of them ends the cleanup.
+func (s *serviceImpl) RunBrewfileCleanup(ctx context.Context, filePath string, confirmed []string) string {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	return s.bundleService.RunBrewfileCleanup(ctx, filePath, confirmed,
+		func(ctx context.Context, name string) (string, bool) {
+			return s.cleanupStep(ctx, stop, "uninstall", name, func(ctx context.Context) (string, bool) {
+				return s.actionsService.removePackage(ctx, name, false)
+			})
+		},
+		func(ctx context.Context, name string) (string, bool) {
+			return s.cleanupStep(ctx, stop, "untap", name, func(ctx context.Context) (string, bool) {
+				return s.tapService.untap(ctx, name)
+			})
+		},
+	)
+}
+
+// cleanupStep queues one step of a Brewfile cleanup. A step cancelled while
+// it runs, or removed from the queue before it starts, calls stop.
+func (s *serviceImpl) cleanupStep(ctx context.Context, stop context.CancelFunc, kind, name string, fn func(context.Context) (string, bool)) (string, bool) {
+	ran, cancelled := false, false
+	message, ok := s.mutateOK(ctx, kind, name, func(ctx context.Context) (string, bool) {
+		ran = true
+		message, ok := fn(ctx)
+		cancelled = ctx.Err() != nil
+		return message, ok
+	})
+	if !ran || cancelled {
+		stop()
+	}
+	return message, ok
+}
+
+// CaptureSnapshot records the live system. Services are left out when
+// `brew services` is unavailable rather than failing the snapshot.
+func (s *serviceImpl) CaptureSnapshot() (*Snapshot, error) {
+	inventory, err := s.listService.LoadInventory()
+	if err != nil {
+		return nil, err
+	}
+	taps, err := s.listService.Taps()
+	if err != nil {
+		return nil, err
+	}
+	services, err := s.servicesService.Services()
+	if err != nil {
+		s.logFunc(fmt.Sprintf("Snapshot without services: %v", err))
+	}
+	return newSnapshot(inventory, taps, services), nil
+}
+
+// RestoreSnapshot re-taps and reinstalls what snapshot has and the live
+// system lacks, through the regular tap and install operations.
+func (s *serviceImpl) RestoreSnapshot(ctx context.Context, snapshot *Snapshot) string {
+	live, err := s.CaptureSnapshot()
+	if err != nil {
+		message := s.getBackendMsg("backend.snapshot.restoreFailed", map[string]string{"error": err.Error()})
+		s.eventEmitter.Emit("snapshotRestoreProgress", message)
+		s.eventEmitter.Emit("snapshotRestoreComplete", message)
+		return message
+	}
+	return restoreSnapshot(ctx, snapshot, live,
+		func(ctx context.Context, name string) string { return s.TapBrewRepository(ctx, name, "") },
+		s.InstallBrewPackage,
+		s.getBackendMsg,
+		s.eventEmitter,
+	)
+}
+
+func (s *serviceImpl) ExportDependencyGraph(filePath string, options GraphExportOptions) error {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return err
+	}
+
+	data, err := graph.Export(options)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write dependency graph: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,193 @@
+package brew
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceActions(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        func(Service) string
+		wantArgs   string
+		recording  brewRecording
+		wantResult string
+	}{
+		{
+			name:       "start",
+			run:        func(s Service) string { return s.StartBrewService(context.Background(), "postgresql@16") },
+			wantArgs:   "services start postgresql@16",
+			recording:  brewRecording{stdout: "==> Successfully started `postgresql@16` (label: homebrew.mxcl.postgresql@16)\n"},
+			wantResult: "backend.service.success",
+		},
+		{
+			name:       "restart",
+			run:        func(s Service) string { return s.RestartBrewService(context.Background(), "redis") },
+			wantArgs:   "services restart redis",
+			wantResult: "backend.service.success",
+		},
+		{
+			name:       "stop failure",
+			run:        func(s Service) string { return s.StopBrewService(context.Background(), "redis") },
+			wantArgs:   "services stop redis",
+			recording:  brewRecording{stderr: "Error: Service `redis` is not started.\n", exitCode: 1},
+			wantResult: "backend.service.failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script(tt.wantArgs, tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := tt.run(service); got != tt.wantResult {
+				t.Fatalf("result = %q, want %q, calls: %v", got, tt.wantResult, fb.calls)
+			}
+			if got := emitter.last("serviceActionComplete"); got != tt.wantResult {
+				t.Fatalf("complete event = %q, want %q", got, tt.wantResult)
+			}
+			if fb.cleared == 0 {
+				t.Fatal("expected the service action to clear the cached command output")
+			}
+		})
+	}
+}
+
+func TestUpdateHomebrew(t *testing.T) {
+	tests := []struct {
+		name       string
+		recording  brewRecording
+		wantResult string
+	}{
+		{name: "success", recording: brewRecording{stdout: "Already up-to-date.\n"}, wantResult: "backend.homebrewUpdate.success"},
+		{name: "failure", recording: brewRecording{stderr: "fatal: unable to access 'https://github.com/Homebrew/brew/'\n", exitCode: 1}, wantResult: "backend.homebrewUpdate.failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script("update", tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := service.UpdateHomebrew(context.Background()); got != tt.wantResult {
+				t.Fatalf("result = %q, want %q", got, tt.wantResult)
+			}
+			if got := emitter.last("homebrewUpdateComplete"); got != tt.wantResult {
+				t.Fatalf("complete event = %q, want %q", got, tt.wantResult)
+			}
+			if fb.cleared == 0 {
+				t.Fatal("expected the update to clear the cached command output")
+			}
+		})
+	}
+}
+
+func TestServiceActionsAreJournaled(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: `{"formulae": [], "casks": []}`}).
+		script("services restart redis", brewRecording{}).
+		script("update", brewRecording{stderr: "fatal: unable to access\n", exitCode: 1})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	service.RestartBrewService(context.Background(), "redis")
+	service.UpdateHomebrew(context.Background())
+
+	page, err := service.GetOperationHistory(JournalFilter{})
+	if err != nil || page.Total != 2 {
+		t.Fatalf("GetOperationHistory = %+v, %v", page, err)
+	}
+	update, restart := page.Entries[0], page.Entries[1]
+	if restart.Kind != "service-restart" || restart.Target != "redis" || !restart.Success {
+		t.Errorf("restart entry = %+v", restart)
+	}
+	if update.Kind != "homebrew-update" || update.Success {
+		t.Errorf("update entry = %+v", update)
+	}
+}
+
+func TestCheckHomebrewUpdate(t *testing.T) {
+	const (
+		revList  = "git -C /opt/homebrew rev-list --count HEAD..origin/HEAD"
+		describe = "git -C /opt/homebrew describe --tags origin/HEAD"
+	)
+
+	tests := []struct {
+		name         string
+		behind       brewRecording
+		latestTag    brewRecording
+		wantUpToDate bool
+		wantLatest   string
+	}{
+		{
+			name:         "up to date",
+			behind:       brewRecording{stdout: "0\n"},
+			wantUpToDate: true,
+			wantLatest:   "4.6.0",
+		},
+		{
+			name:       "behind a release",
+			behind:     brewRecording{stdout: "12\n"},
+			latestTag:  brewRecording{stdout: "4.6.1\n"},
+			wantLatest: "4.6.1",
+		},
+		{
+			name:       "behind without a tag",
+			behind:     brewRecording{stdout: "3\n"},
+			latestTag:  brewRecording{stderr: "fatal: No names found, cannot describe anything.\n", exitCode: 128},
+			wantLatest: "latest",
+		},
+		{
+			name:         "not a git checkout",
+			behind:       brewRecording{stderr: "fatal: not a git repository (or any of the parent directories): .git\n", exitCode: 128},
+			wantUpToDate: true,
+			wantLatest:   "4.6.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().
+				script("--version", brewRecording{stdout: "Homebrew 4.6.0\n"}).
+				script("--repository", brewRecording{stdout: "/opt/homebrew\n"}).
+				script(revList, tt.behind).
+				script(describe, tt.latestTag)
+			service, _ := newFakeService(fb)
+
+			result, err := service.CheckHomebrewUpdate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result["currentVersion"] != "4.6.0" {
+				t.Fatalf("currentVersion = %v", result["currentVersion"])
+			}
+			if result["isUpToDate"] != tt.wantUpToDate {
+				t.Fatalf("isUpToDate = %v, want %v", result["isUpToDate"], tt.wantUpToDate)
+			}
+			if result["latestVersion"] != tt.wantLatest {
+				t.Fatalf("latestVersion = %v, want %q", result["latestVersion"], tt.wantLatest)
+			}
+		})
+	}
+}
+
+func TestExportBrewfile(t *testing.T) {
+	const dump = "bundle dump --file=/tmp/Brewfile --force"
+
+	fb := newFakeBrew().script(dump, brewRecording{})
+	service, _ := newFakeService(fb)
+	if err := service.ExportBrewfile("/tmp/Brewfile"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fb.invoked(dump) != 1 {
+		t.Fatalf("expected %q, calls: %v", dump, fb.calls)
+	}
+
+	fb = newFakeBrew().script(dump, brewRecording{stderr: "Error: Permission denied @ rb_sysopen - /tmp/Brewfile\n", exitCode: 1})
+	service, _ = newFakeService(fb)
+	err := service.ExportBrewfile("/tmp/Brewfile")
+	if err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Fatalf("expected brew's diagnostics in the error, got %v", err)
+	}
+}
@@ -4,9 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-
-	"WailBrew/backend/system"
 )
 
 // ServiceEntry represents a single Homebrew-managed background service as
@@ -21,69 +18,62 @@ type ServiceEntry struct {
 
 // ServicesService provides management of Homebrew background services (launchd).
 type ServicesService struct {
-	executor       *Executor
-	brewPath       string
-	getBrewEnvFunc func() []string
-	getBackendMsg  func(string, map[string]string) string
-	eventEmitter   EventEmitter
+	runner        Runner
+	getBackendMsg func(string, map[string]string) string
+	eventEmitter  EventEmitter
 }
 
 // NewServicesService creates a new services service.
 func NewServicesService(
-	executor *Executor,
-	brewPath string,
-	getBrewEnvFunc func() []string,
+	runner Runner,
 	getBackendMsg func(string, map[string]string) string,
 	eventEmitter EventEmitter,
 ) *ServicesService {
 	return &ServicesService{
-		executor:       executor,
-		brewPath:       brewPath,
-		getBrewEnvFunc: getBrewEnvFunc,
-		getBackendMsg:  getBackendMsg,
-		eventEmitter:   eventEmitter,
+		runner:        runner,
+		getBackendMsg: getBackendMsg,
+		eventEmitter:  eventEmitter,
 	}
 }
 
-// GetBrewServices returns all Homebrew-managed services as rows of
-// [name, status, user]. Status is dynamic, so the underlying command is run
+// Services returns all Homebrew-managed services. A missing status is
+// reported as "unknown". Status is dynamic, so the underlying command is run
 // without the shared cache.
-func (s *ServicesService) GetBrewServices() [][]string {
-	output, err := s.executor.RunNoCache("services", "list", "--json")
+func (s *ServicesService) Services() ([]ServiceEntry, error) {
+	output, err := s.runner.RunNoCache("services", "list", "--json")
 	if err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Failed to fetch services: %v", err)}}
+		return nil, fmt.Errorf("Failed to fetch services: %v", err)
 	}
 
 	jsonOutput, _, err := ExtractJSONFromOutput(string(output))
 	if err != nil {
 		// No JSON usually means there are no services at all.
-		return [][]string{}
+		return []ServiceEntry{}, nil
 	}
 
 	var entries []ServiceEntry
 	if err := json.Unmarshal([]byte(jsonOutput), &entries); err != nil {
-		return [][]string{{"Error", fmt.Sprintf("Failed to parse services: %v", err)}}
+		return nil, fmt.Errorf("Failed to parse services: %v", err)
 	}
 
-	services := make([][]string, 0, len(entries))
+	services := make([]ServiceEntry, 0, len(entries))
 	for _, entry := range entries {
 		if entry.Name == "" {
 			continue
 		}
-		status := entry.Status
-		if status == "" {
-			status = "unknown"
+		if entry.Status == "" {
+			entry.Status = "unknown"
 		}
-		services = append(services, []string{entry.Name, status, entry.User})
+		services = append(services, entry)
 	}
 
-	return services
+	return services, nil
 }
 
 // GetBrewServiceInfo returns the raw `brew services info <name>` output for the
 // detail panel.
 func (s *ServicesService) GetBrewServiceInfo(name string) string {
-	output, err := s.executor.Run("services", "info", name)
+	output, err := s.runner.Run("services", "info", name)
 	if err != nil {
 		return fmt.Sprintf("Error: Failed to get service info: %v", err)
 	}
@@ -94,7 +84,7 @@ func (s *ServicesService) GetBrewServiceInfo(name string) string {
 // running or has no PID. `brew services list --json` omits the PID, so this
 // queries `brew services info <name> --json`.
 func (s *ServicesService) GetBrewServicePid(name string) int {
-	output, err := s.executor.RunNoCache("services", "info", name, "--json")
+	output, err := s.runner.RunNoCache("services", "info", name, "--json")
 	if err != nil {
 		return 0
 	}
@@ -119,37 +109,38 @@ func (s *ServicesService) GetBrewServicePid(name string) int {
 
 // StartBrewService starts a service and registers it to launch at login.
 func (s *ServicesService) StartBrewService(ctx context.Context, name string) string {
-	return s.runServiceAction(ctx, "start", name)
+	result, _ := s.runServiceAction(ctx, "start", name)
+	return result
 }
 
 // StopBrewService stops a service and unregisters it.
 func (s *ServicesService) StopBrewService(ctx context.Context, name string) string {
-	return s.runServiceAction(ctx, "stop", name)
+	result, _ := s.runServiceAction(ctx, "stop", name)
+	return result
 }
 
 // RestartBrewService stops then starts a service.
 func (s *ServicesService) RestartBrewService(ctx context.Context, name string) string {
-	return s.runServiceAction(ctx, "restart", name)
+	result, _ := s.runServiceAction(ctx, "restart", name)
+	return result
 }
 
 // RunBrewService runs a service without registering it to launch at login
 // (handy for debugging).
 func (s *ServicesService) RunBrewService(ctx context.Context, name string) string {
-	return s.runServiceAction(ctx, "run", name)
+	result, _ := s.runServiceAction(ctx, "run", name)
+	return result
 }
 
 // runServiceAction executes `brew services <action> <name>` while streaming
 // live output via the shared serviceActionProgress / serviceActionComplete
-// events, mirroring the tap/untap flow.
-func (s *ServicesService) runServiceAction(ctx context.Context, action, name string) string {
+// events, mirroring the tap/untap flow, and reports whether it succeeded.
+func (s *ServicesService) runServiceAction(ctx context.Context, action, name string) (string, bool) {
 	msgParams := map[string]string{"action": action, "name": name}
 	startMessage := s.getBackendMsg("backend.service.start", msgParams)
 	s.eventEmitter.Emit("serviceActionProgress", startMessage)
 
-	cmd := exec.CommandContext(ctx, s.brewPath, "services", action, name)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, []string{"services", action, name},
 		func(line string) { s.eventEmitter.Emit("serviceActionProgress", fmt.Sprintf("📦 %s", line)) },
 		func(line string) { s.eventEmitter.Emit("serviceActionProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -159,26 +150,31 @@ func (s *ServicesService) runServiceAction(ctx context.Context, action, name str
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("serviceActionProgress", errorMsg)
 		s.eventEmitter.Emit("serviceActionComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("serviceActionProgress", errorMsg)
 		s.eventEmitter.Emit("serviceActionComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingService", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("serviceActionProgress", errorMsg)
 		s.eventEmitter.Emit("serviceActionComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("serviceActionProgress", cancelMsg)
+		s.eventEmitter.Emit("serviceActionComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		errorMsg := s.getBackendMsg("backend.service.failed", map[string]string{"action": action, "name": name, "error": err.Error()})
 		s.eventEmitter.Emit("serviceActionProgress", errorMsg)
 		s.eventEmitter.Emit("serviceActionComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	successMsg := s.getBackendMsg("backend.service.success", msgParams)
 	s.eventEmitter.Emit("serviceActionProgress", successMsg)
 	s.eventEmitter.Emit("serviceActionComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -13,14 +14,14 @@ import (
 
 // SizeService provides package size calculation functionality
 type SizeService struct {
-	executor    *Executor
+	executor    Runner
 	logFunc     func(string)
 	extractJSON func(string) (string, string, error)
 	cache       sync.Map // key: cellar/caskroom path → string size
 }
 
 // NewSizeService creates a new size service
-func NewSizeService(executor *Executor, logFunc func(string), extractJSON func(string) (string, string, error)) *SizeService {
+func NewSizeService(executor Runner, logFunc func(string), extractJSON func(string) (string, string, error)) *SizeService {
 	return &SizeService{
 		executor:    executor,
 		logFunc:     logFunc,
@@ -244,3 +245,58 @@ func (s *SizeService) duSize(path string) string {
 	s.cache.Store(path, size)
 	return size
 }
+
+// duUnits are the suffixes `du -h` uses, in increasing powers of 1024.
+const duUnits = "BKMGTP"
+
+// parseDuSize converts a `du -h` size such as "4.0K" or "1.2G" to bytes.
+func parseDuSize(size string) (int64, bool) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, false
+	}
+
+	multiplier := 1.0
+	if i := strings.IndexByte(duUnits, size[len(size)-1]); i >= 0 {
+		for ; i > 0; i-- {
+			multiplier *= 1024
+		}
+		size = size[:len(size)-1]
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(size, ",", ".", 1), 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return int64(value * multiplier), true
+}
+
+// formatDuSize renders bytes the way `du -h` does, so totals read like the
+// per-package sizes they were summed from.
+func formatDuSize(bytes int64) string {
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(duUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	if value < 10 {
+		return fmt.Sprintf("%.1f%c", value, duUnits[unit])
+	}
+	return fmt.Sprintf("%.0f%c", value, duUnits[unit])
+}
+
+// sumDuSizes totals `du -h` sizes. Unknown entries are skipped, so the total
+// is a lower bound when some sizes could not be measured.
+func sumDuSizes(sizes []string) string {
+	var total int64
+	for _, size := range sizes {
+		if bytes, ok := parseDuSize(size); ok {
+			total += bytes
+		}
+	}
+	return formatDuSize(total)
+}
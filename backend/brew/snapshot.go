@@ -0,0 +1,487 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshot records what was installed at one point in time: formulae and
+// casks with their versions, the taps they come from and the services that
+// were running. Package names are tap-qualified outside the official taps so
+// a snapshot can be restored as is.
+type Snapshot struct {
+	ID        string            `json:"id"`
+	Label     string            `json:"label"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Host      string            `json:"host"`
+	Auto      bool              `json:"auto,omitempty"`
+	Imported  bool              `json:"imported,omitempty"`
+	Formulae  []SnapshotPackage `json:"formulae"`
+	Casks     []SnapshotPackage `json:"casks"`
+	Taps      []string          `json:"taps"`
+	Services  []string          `json:"services"`
+}
+
+// SnapshotPackage is a formula or cask as it was installed.
+type SnapshotPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	OnRequest bool   `json:"onRequest,omitempty"`
+}
+
+// SnapshotSummary describes a stored snapshot without its contents.
+type SnapshotSummary struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"createdAt"`
+	Host      string    `json:"host"`
+	Auto      bool      `json:"auto"`
+	Imported  bool      `json:"imported"`
+	Formulae  int       `json:"formulae"`
+	Casks     int       `json:"casks"`
+	Taps      int       `json:"taps"`
+	Services  int       `json:"services"`
+}
+
+// Summary returns the snapshot's listing entry.
+func (s *Snapshot) Summary() SnapshotSummary {
+	return SnapshotSummary{
+		ID:        s.ID,
+		Label:     s.Label,
+		CreatedAt: s.CreatedAt,
+		Host:      s.Host,
+		Auto:      s.Auto,
+		Imported:  s.Imported,
+		Formulae:  len(s.Formulae),
+		Casks:     len(s.Casks),
+		Taps:      len(s.Taps),
+		Services:  len(s.Services),
+	}
+}
+
+// newSnapshot builds a snapshot of the live system from what brew reported.
+func newSnapshot(inventory *Inventory, taps []Tap, services []ServiceEntry) *Snapshot {
+	snapshot := &Snapshot{
+		CreatedAt: time.Now(),
+		Formulae:  make([]SnapshotPackage, 0, len(inventory.Formulae)),
+		Casks:     make([]SnapshotPackage, 0, len(inventory.Casks)),
+		Taps:      make([]string, 0, len(taps)),
+		Services:  []string{},
+	}
+	snapshot.Host, _ = os.Hostname()
+
+	for _, f := range inventory.Formulae {
+		snapshot.Formulae = append(snapshot.Formulae, SnapshotPackage{
+			Name:      qualifiedName(f.Name, f.Tap),
+			Version:   f.Version,
+			Pinned:    f.Pinned,
+			OnRequest: f.InstallReason == InstallReasonOnRequest,
+		})
+	}
+	for _, c := range inventory.Casks {
+		snapshot.Casks = append(snapshot.Casks, SnapshotPackage{
+			Name:    qualifiedName(c.Name, c.Tap),
+			Version: c.Version,
+		})
+	}
+	for _, tap := range taps {
+		snapshot.Taps = append(snapshot.Taps, tap.Name)
+	}
+	for _, service := range services {
+		if service.Status == "started" {
+			snapshot.Services = append(snapshot.Services, service.Name)
+		}
+	}
+
+	sort.Slice(snapshot.Formulae, func(i, j int) bool { return snapshot.Formulae[i].Name < snapshot.Formulae[j].Name })
+	sort.Slice(snapshot.Casks, func(i, j int) bool { return snapshot.Casks[i].Name < snapshot.Casks[j].Name })
+	sort.Strings(snapshot.Taps)
+	sort.Strings(snapshot.Services)
+	return snapshot
+}
+
+// qualifiedName prefixes name with its tap unless it comes from one of the
+// official taps, which brew resolves on its own.
+func qualifiedName(name, tap string) string {
+	if _, ok := tapOf(tap + "/" + name); ok {
+		return tap + "/" + name
+	}
+	return name
+}
+
+// SnapshotDiff is what changed going from one snapshot to another. Added
+// entries are only in the newer side, Removed ones only in the older side.
+type SnapshotDiff struct {
+	Formulae SnapshotPackageDiff `json:"formulae"`
+	Casks    SnapshotPackageDiff `json:"casks"`
+	Taps     SnapshotNameDiff    `json:"taps"`
+	Services SnapshotNameDiff    `json:"services"`
+}
+
+// SnapshotPackageDiff lists added, removed and changed formulae or casks.
+type SnapshotPackageDiff struct {
+	Added   []SnapshotPackage       `json:"added"`
+	Removed []SnapshotPackage       `json:"removed"`
+	Changed []SnapshotPackageChange `json:"changed"`
+}
+
+// SnapshotPackageChange is a package present on both sides whose version or
+// pinned state differs.
+type SnapshotPackageChange struct {
+	Name        string `json:"name"`
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	FromPinned  bool   `json:"fromPinned"`
+	ToPinned    bool   `json:"toPinned"`
+}
+
+// SnapshotNameDiff lists added and removed taps or running services.
+type SnapshotNameDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Empty reports whether both sides are the same.
+func (d *SnapshotDiff) Empty() bool {
+	for _, packages := range []SnapshotPackageDiff{d.Formulae, d.Casks} {
+		if len(packages.Added)+len(packages.Removed)+len(packages.Changed) > 0 {
+			return false
+		}
+	}
+	return len(d.Taps.Added)+len(d.Taps.Removed)+len(d.Services.Added)+len(d.Services.Removed) == 0
+}
+
+// DiffSnapshots compares from against to.
+func DiffSnapshots(from, to *Snapshot) *SnapshotDiff {
+	return &SnapshotDiff{
+		Formulae: diffSnapshotPackages(from.Formulae, to.Formulae),
+		Casks:    diffSnapshotPackages(from.Casks, to.Casks),
+		Taps:     diffSnapshotNames(from.Taps, to.Taps),
+		Services: diffSnapshotNames(from.Services, to.Services),
+	}
+}
+
+func diffSnapshotPackages(from, to []SnapshotPackage) SnapshotPackageDiff {
+	diff := SnapshotPackageDiff{
+		Added:   []SnapshotPackage{},
+		Removed: []SnapshotPackage{},
+		Changed: []SnapshotPackageChange{},
+	}
+	before := make(map[string]SnapshotPackage, len(from))
+	for _, pkg := range from {
+		before[pkg.Name] = pkg
+	}
+	after := make(map[string]bool, len(to))
+	for _, pkg := range to {
+		after[pkg.Name] = true
+		old, ok := before[pkg.Name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, pkg)
+		case old.Version != pkg.Version || old.Pinned != pkg.Pinned:
+			diff.Changed = append(diff.Changed, SnapshotPackageChange{
+				Name:        pkg.Name,
+				FromVersion: old.Version,
+				ToVersion:   pkg.Version,
+				FromPinned:  old.Pinned,
+				ToPinned:    pkg.Pinned,
+			})
+		}
+	}
+	for _, pkg := range from {
+		if !after[pkg.Name] {
+			diff.Removed = append(diff.Removed, pkg)
+		}
+	}
+	return diff
+}
+
+func diffSnapshotNames(from, to []string) SnapshotNameDiff {
+	diff := SnapshotNameDiff{Added: []string{}, Removed: []string{}}
+	before := make(map[string]bool, len(from))
+	for _, name := range from {
+		before[name] = true
+	}
+	after := make(map[string]bool, len(to))
+	for _, name := range to {
+		after[name] = true
+		if !before[name] {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for _, name := range from {
+		if !after[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+// restoreSnapshot brings back what snapshot has and live lacks: missing taps
+// first, then missing casks and the formulae that were installed on request,
+// which pull their dependencies in again. Versions are not rolled back.
+// Progress goes out as "snapshotRestoreProgress" and the summary as
+// "snapshotRestoreComplete".
+func restoreSnapshot(
+	ctx context.Context,
+	snapshot, live *Snapshot,
+	tap func(ctx context.Context, name string) string,
+	install func(ctx context.Context, name string) string,
+	getBackendMsg func(string, map[string]string) string,
+	eventEmitter EventEmitter,
+) string {
+	finish := func(message string) string {
+		eventEmitter.Emit("snapshotRestoreProgress", message)
+		eventEmitter.Emit("snapshotRestoreComplete", message)
+		return message
+	}
+
+	diff := DiffSnapshots(live, snapshot)
+
+	type step struct {
+		name    string
+		run     func(ctx context.Context, name string) string
+		success string
+	}
+	var steps []step
+	for _, name := range diff.Taps.Added {
+		steps = append(steps, step{name, tap, "backend.tap.success"})
+	}
+	for _, pkg := range diff.Casks.Added {
+		steps = append(steps, step{pkg.Name, install, "backend.install.success"})
+	}
+	for _, pkg := range diff.Formulae.Added {
+		if pkg.OnRequest {
+			steps = append(steps, step{pkg.Name, install, "backend.install.success"})
+		}
+	}
+	if len(steps) == 0 {
+		return finish(getBackendMsg("backend.snapshot.nothingToRestore", map[string]string{}))
+	}
+
+	var failed []string
+	for i, st := range steps {
+		if ctx.Err() != nil {
+			return finish(getBackendMsg("backend.operation.cancelled", map[string]string{}))
+		}
+		eventEmitter.Emit("snapshotRestoreProgress", getBackendMsg("backend.snapshot.restoreStep", map[string]string{
+			"name":    st.name,
+			"current": fmt.Sprint(i + 1),
+			"total":   fmt.Sprint(len(steps)),
+		}))
+		result := st.run(ctx, st.name)
+		if result != getBackendMsg(st.success, map[string]string{"name": st.name}) {
+			failed = append(failed, st.name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return finish(getBackendMsg("backend.snapshot.restorePartial", map[string]string{
+			"failed": strings.Join(failed, ", "),
+		}))
+	}
+	return finish(getBackendMsg("backend.snapshot.restoreSuccess", map[string]string{"count": fmt.Sprint(len(steps))}))
+}
+
+// maxAutoSnapshots is how many automatic snapshots the store keeps.
+const maxAutoSnapshots = 10
+
+// ErrSnapshotNotFound is returned for an unknown snapshot ID.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+var snapshotIDPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// SnapshotStore keeps snapshots as one JSON file each in a directory.
+type SnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSnapshotStore creates a store in dir, which is created on first save.
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{dir: dir}
+}
+
+// Save assigns the snapshot an ID and writes it. Automatic snapshots over
+// the limit are dropped, oldest first.
+func (s *SnapshotStore) Save(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	snapshot.ID = s.newID(snapshot.CreatedAt)
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path(snapshot.ID), data, 0644); err != nil {
+		return err
+	}
+	if snapshot.Auto {
+		return s.prune()
+	}
+	return nil
+}
+
+// List returns every stored snapshot, newest first. Unreadable files are
+// skipped.
+func (s *SnapshotStore) List() ([]SnapshotSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]SnapshotSummary, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		summaries = append(summaries, snapshot.Summary())
+	}
+	return summaries, nil
+}
+
+// Load reads the snapshot with the given ID.
+func (s *SnapshotStore) Load(id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !snapshotIDPattern.MatchString(id) {
+		return nil, ErrSnapshotNotFound
+	}
+	snapshot, err := readSnapshot(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot, err
+}
+
+// Delete removes the snapshot with the given ID.
+func (s *SnapshotStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !snapshotIDPattern.MatchString(id) {
+		return ErrSnapshotNotFound
+	}
+	err := os.Remove(s.path(id))
+	if os.IsNotExist(err) {
+		return ErrSnapshotNotFound
+	}
+	return err
+}
+
+// Import copies a snapshot file, typically taken on another machine, into
+// the store under a new ID.
+func (s *SnapshotStore) Import(path string) (*Snapshot, error) {
+	snapshot, err := readSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Label == "" {
+		snapshot.Label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	snapshot.Auto = false
+	snapshot.Imported = true
+	if err := s.Save(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *SnapshotStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// newID derives the ID from the creation time, with a counter when several
+// snapshots share a second.
+func (s *SnapshotStore) newID(createdAt time.Time) string {
+	base := createdAt.UTC().Format("20060102-150405")
+	id := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(s.path(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+func (s *SnapshotStore) loadAll() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []*Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		snapshot, err := readSnapshot(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		snapshot.ID = strings.TrimSuffix(entry.Name(), ".json")
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+func (s *SnapshotStore) prune() error {
+	snapshots, err := s.loadAll()
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, snapshot := range snapshots {
+		if !snapshot.Auto {
+			continue
+		}
+		if kept++; kept > maxAutoSnapshots {
+			if err := os.Remove(s.path(snapshot.ID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if snapshot.CreatedAt.IsZero() || snapshot.Formulae == nil || snapshot.Casks == nil {
+		return nil, fmt.Errorf("invalid snapshot %s: not a WailBrew snapshot", path)
+	}
+	if snapshot.Taps == nil {
+		snapshot.Taps = []string{}
+	}
+	if snapshot.Services == nil {
+		snapshot.Services = []string{}
+	}
+	return &snapshot, nil
+}
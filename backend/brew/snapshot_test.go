@@ -0,0 +1,268 @@
+package brew
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const recordedSnapshotInventory = `{
+  "formulae": [
+    {"name": "postgresql@16", "full_name": "postgresql@16", "tap": "homebrew/core", "pinned": true,
+      "installed": [{"version": "16.4", "installed_on_request": true}]},
+    {"name": "icu4c", "full_name": "icu4c", "tap": "homebrew/core",
+      "installed": [{"version": "75.1", "installed_as_dependency": true}]},
+    {"name": "widget", "full_name": "acme/tools/widget", "tap": "acme/tools",
+      "installed": [{"version": "0.3.0", "installed_on_request": true}]}
+  ],
+  "casks": [
+    {"token": "browser", "full_token": "browser", "tap": "homebrew/cask", "installed": "129.0"}
+  ]
+}`
+
+const recordedServicesList = `[
+  {"name": "postgresql@16", "status": "started", "user": "me", "file": "~/Library/LaunchAgents/a.plist"},
+  {"name": "redis", "status": "none"}
+]`
+
+func snapshotFakeBrew() *fakeBrew {
+	return newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedSnapshotInventory}).
+		script("tap", brewRecording{stdout: "acme/tools\nhomebrew/services\n"}).
+		script("services list --json", brewRecording{stdout: recordedServicesList})
+}
+
+func TestCaptureSnapshot(t *testing.T) {
+	service, _ := newFakeService(snapshotFakeBrew())
+
+	snapshot, err := service.CaptureSnapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantFormulae := []SnapshotPackage{
+		{Name: "acme/tools/widget", Version: "0.3.0", OnRequest: true},
+		{Name: "icu4c", Version: "75.1"},
+		{Name: "postgresql@16", Version: "16.4", Pinned: true, OnRequest: true},
+	}
+	if !reflect.DeepEqual(snapshot.Formulae, wantFormulae) {
+		t.Errorf("Formulae = %+v, want %+v", snapshot.Formulae, wantFormulae)
+	}
+	if want := []SnapshotPackage{{Name: "browser", Version: "129.0"}}; !reflect.DeepEqual(snapshot.Casks, want) {
+		t.Errorf("Casks = %+v, want %+v", snapshot.Casks, want)
+	}
+	if want := []string{"acme/tools", "homebrew/services"}; !reflect.DeepEqual(snapshot.Taps, want) {
+		t.Errorf("Taps = %v, want %v", snapshot.Taps, want)
+	}
+	if want := []string{"postgresql@16"}; !reflect.DeepEqual(snapshot.Services, want) {
+		t.Errorf("Services = %v, want only the running ones %v", snapshot.Services, want)
+	}
+}
+
+func TestCaptureSnapshot_withoutServices(t *testing.T) {
+	fb := snapshotFakeBrew().script("services list --json", brewRecording{stderr: "Unknown command", exitCode: 1})
+	service, _ := newFakeService(fb)
+
+	snapshot, err := service.CaptureSnapshot()
+	if err != nil {
+		t.Fatalf("a missing brew services must not fail the snapshot: %v", err)
+	}
+	if len(snapshot.Services) != 0 || len(snapshot.Formulae) != 3 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &Snapshot{
+		Formulae: []SnapshotPackage{{Name: "git", Version: "2.46"}, {Name: "node", Version: "22.1"}, {Name: "wget", Version: "1.24"}},
+		Casks:    []SnapshotPackage{{Name: "browser", Version: "129.0"}},
+		Taps:     []string{"acme/tools"},
+		Services: []string{"redis"},
+	}
+	to := &Snapshot{
+		Formulae: []SnapshotPackage{{Name: "git", Version: "2.47"}, {Name: "node", Version: "22.1", Pinned: true}, {Name: "jq", Version: "1.7"}},
+		Casks:    []SnapshotPackage{{Name: "browser", Version: "129.0"}},
+		Taps:     []string{"acme/tools", "other/tap"},
+		Services: []string{},
+	}
+
+	diff := DiffSnapshots(from, to)
+
+	want := SnapshotPackageDiff{
+		Added:   []SnapshotPackage{{Name: "jq", Version: "1.7"}},
+		Removed: []SnapshotPackage{{Name: "wget", Version: "1.24"}},
+		Changed: []SnapshotPackageChange{
+			{Name: "git", FromVersion: "2.46", ToVersion: "2.47"},
+			{Name: "node", FromVersion: "22.1", ToVersion: "22.1", ToPinned: true},
+		},
+	}
+	if !reflect.DeepEqual(diff.Formulae, want) {
+		t.Errorf("Formulae = %+v, want %+v", diff.Formulae, want)
+	}
+	if len(diff.Casks.Added)+len(diff.Casks.Removed)+len(diff.Casks.Changed) != 0 {
+		t.Errorf("Casks = %+v, want no change", diff.Casks)
+	}
+	if want := (SnapshotNameDiff{Added: []string{"other/tap"}, Removed: []string{}}); !reflect.DeepEqual(diff.Taps, want) {
+		t.Errorf("Taps = %+v, want %+v", diff.Taps, want)
+	}
+	if want := (SnapshotNameDiff{Added: []string{}, Removed: []string{"redis"}}); !reflect.DeepEqual(diff.Services, want) {
+		t.Errorf("Services = %+v, want %+v", diff.Services, want)
+	}
+	if diff.Empty() || !DiffSnapshots(to, to).Empty() {
+		t.Error("Empty() must only hold for identical snapshots")
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	fb := snapshotFakeBrew().
+		script("tap other/tap", brewRecording{}).
+		script("install editor", brewRecording{}).
+		script("install jq", brewRecording{stderr: "Error: No formulae found", exitCode: 1})
+	service, emitter := newFakeService(fb)
+	snapshot := &Snapshot{
+		Formulae: []SnapshotPackage{
+			{Name: "acme/tools/widget", Version: "0.2.0", OnRequest: true},
+			{Name: "jq", Version: "1.7", OnRequest: true},
+			{Name: "oniguruma", Version: "6.9"},
+		},
+		Casks: []SnapshotPackage{{Name: "browser", Version: "128.0"}, {Name: "editor", Version: "1.0"}},
+		Taps:  []string{"acme/tools", "other/tap"},
+	}
+
+	if got := service.RestoreSnapshot(context.Background(), snapshot); got != "backend.snapshot.restorePartial" {
+		t.Fatalf("result = %q", got)
+	}
+
+	// Taps go first; installed packages stay at their version and
+	// dependencies come back with the formulae that need them.
+	var order []string
+	for _, call := range fb.calls {
+		if strings.HasPrefix(call, "install ") || strings.HasPrefix(call, "tap ") {
+			order = append(order, call)
+		}
+	}
+	want := []string{"tap other/tap", "install editor", "install jq"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("calls = %v, want %v", order, want)
+	}
+	if emitter.count("packageInstallComplete") != 2 || emitter.count("snapshotRestoreComplete") != 1 {
+		t.Fatal("expected the regular install events and one restore summary")
+	}
+}
+
+func TestRestoreSnapshot_nothingMissing(t *testing.T) {
+	fb := snapshotFakeBrew()
+	service, _ := newFakeService(fb)
+	snapshot, err := service.CaptureSnapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := service.RestoreSnapshot(context.Background(), snapshot); got != "backend.snapshot.nothingToRestore" {
+		t.Fatalf("result = %q", got)
+	}
+}
+
+func TestSnapshotStore(t *testing.T) {
+	store := NewSnapshotStore(filepath.Join(t.TempDir(), "snapshots"))
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	first := &Snapshot{Label: "before upgrade", CreatedAt: created, Formulae: []SnapshotPackage{{Name: "git"}}, Casks: []SnapshotPackage{}}
+	second := &Snapshot{Label: "same second", CreatedAt: created, Formulae: []SnapshotPackage{}, Casks: []SnapshotPackage{}}
+	for _, snapshot := range []*Snapshot{first, second} {
+		if err := store.Save(snapshot); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if first.ID != "20260301-093000" || second.ID != "20260301-093000-2" {
+		t.Fatalf("IDs = %q, %q", first.ID, second.ID)
+	}
+
+	loaded, err := store.Load(first.ID)
+	if err != nil || loaded.Label != "before upgrade" || len(loaded.Formulae) != 1 {
+		t.Fatalf("Load = %+v, %v", loaded, err)
+	}
+	if _, err := store.Load("../config"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("Load outside the store = %v, want ErrSnapshotNotFound", err)
+	}
+
+	if err := store.Delete(second.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(second.ID); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("second Delete = %v, want ErrSnapshotNotFound", err)
+	}
+	summaries, err := store.List()
+	if err != nil || len(summaries) != 1 || summaries[0].ID != first.ID || summaries[0].Formulae != 1 {
+		t.Fatalf("List = %+v, %v", summaries, err)
+	}
+}
+
+func TestSnapshotStore_prunesAutoSnapshots(t *testing.T) {
+	store := NewSnapshotStore(t.TempDir())
+	manual := &Snapshot{CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Formulae: []SnapshotPackage{}, Casks: []SnapshotPackage{}}
+	if err := store.Save(manual); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	for i := 0; i < maxAutoSnapshots+2; i++ {
+		auto := &Snapshot{
+			Auto:      true,
+			CreatedAt: time.Date(2026, 2, 1, 0, i, 0, 0, time.UTC),
+			Formulae:  []SnapshotPackage{},
+			Casks:     []SnapshotPackage{},
+		}
+		if err := store.Save(auto); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	summaries, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != maxAutoSnapshots+1 {
+		t.Fatalf("kept %d snapshots, want %d automatic ones and the manual one", len(summaries), maxAutoSnapshots)
+	}
+	if summaries[0].ID != "20260201-001100" || summaries[len(summaries)-1].ID != manual.ID {
+		t.Fatalf("List order = %+v", summaries)
+	}
+}
+
+func TestSnapshotStore_Import(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSnapshotStore(filepath.Join(dir, "snapshots"))
+
+	exported := filepath.Join(dir, "work-laptop.json")
+	data := `{"id": "20250101-000000", "createdAt": "2025-01-01T00:00:00Z", "host": "work-laptop", "auto": true,
+		"formulae": [{"name": "git", "version": "2.45"}], "casks": []}`
+	if err := os.WriteFile(exported, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := store.Import(exported)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !imported.Imported || imported.Auto || imported.Label != "work-laptop" || imported.Host != "work-laptop" {
+		t.Fatalf("imported = %+v", imported)
+	}
+	if imported.Taps == nil || imported.Services == nil {
+		t.Fatal("missing lists must read as empty")
+	}
+
+	garbage := filepath.Join(dir, "Brewfile")
+	if err := os.WriteFile(garbage, []byte(`brew "git"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Import(garbage); err == nil {
+		t.Fatal("expected an error for a file that is not a snapshot")
+	}
+	if summaries, _ := store.List(); len(summaries) != 1 {
+		t.Fatalf("List = %+v, want only the imported snapshot", summaries)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"sync"
 )
 
-// StartupData contains all data needed for app initialization
+// StartupData contains all data needed for app initialization. Sections use
+// the row layouts produced by FormulaRows, CaskRows, OutdatedRows and TapRows.
 type StartupData struct {
 	Packages  [][]string `json:"packages"`
 	Casks     [][]string `json:"casks"`
@@ -17,54 +18,64 @@ type StartupData struct {
 type StartupService struct {
 	listService     *ListService
 	outdatedService *OutdatedService
-	databaseService *DatabaseService
+	updateDatabase  func() (string, error)
 }
 
-// NewStartupService creates a new startup service
+// NewStartupService creates a new startup service. updateDatabase runs
+// `brew update`; it is injected so the call goes through the operation queue.
 func NewStartupService(
 	listService *ListService,
 	outdatedService *OutdatedService,
-	databaseService *DatabaseService,
+	updateDatabase func() (string, error),
 ) *StartupService {
 	return &StartupService{
 		listService:     listService,
 		outdatedService: outdatedService,
-		databaseService: databaseService,
+		updateDatabase:  updateDatabase,
 	}
 }
 
+// setInventory fills the installed sections from one inventory snapshot so
+// packages, casks and leaves always describe the same moment.
+func (d *StartupData) setInventory(inventory *Inventory, err error) {
+	if err != nil {
+		d.Packages = FormulaRows(nil, err)
+		d.Casks = CaskRows(nil, err)
+		d.Leaves = LeafNames(nil, err)
+		return
+	}
+	d.Packages = FormulaRows(inventory.Formulae, nil)
+	d.Casks = CaskRows(inventory.Casks, nil)
+	d.Leaves = LeafNames(inventory.Leaves, nil)
+}
+
 // GetStartupData fetches all startup data in parallel with deduplication
 // This replaces multiple individual calls from the frontend
 func (s *StartupService) GetStartupData() *StartupData {
 	var wg sync.WaitGroup
 	result := &StartupData{}
 
-	// Run data fetches in parallel; leaves runs afterward to avoid Homebrew lock contention
-	wg.Add(4)
-
-	go func() {
-		defer wg.Done()
-		result.Packages = s.listService.GetBrewPackages()
-	}()
+	// Installed packages, casks and leaves all come from a single inventory
+	// snapshot; outdated and taps need their own commands
+	wg.Add(3)
 
 	go func() {
 		defer wg.Done()
-		result.Casks = s.listService.GetBrewCasks()
+		result.setInventory(s.listService.LoadInventory())
 	}()
 
 	go func() {
 		defer wg.Done()
-		// GetBrewUpdatablePackages already handles its own validation
-		result.Updatable = s.outdatedService.GetBrewUpdatablePackages()
+		// OutdatedPackages already handles its own validation
+		result.Updatable = OutdatedRows(s.outdatedService.OutdatedPackages())
 	}()
 
 	go func() {
 		defer wg.Done()
-		result.Taps = s.listService.GetBrewTaps()
+		result.Taps = TapRows(s.listService.Taps())
 	}()
 
 	wg.Wait()
-	result.Leaves = s.listService.GetBrewLeaves()
 	return result
 }
 
@@ -81,34 +92,28 @@ func (s *StartupService) GetStartupDataWithUpdate() *StartupData {
 	go func() {
 		defer wg.Done()
 		// Update database - errors are ignored as we can still show current data
-		_, _ = s.databaseService.UpdateBrewDatabaseWithOutput()
+		_, _ = s.updateDatabase()
 	}()
 
 	// Fetch other data in parallel (these don't require fresh database)
-	wg.Add(3)
-
-	go func() {
-		defer wg.Done()
-		result.Packages = s.listService.GetBrewPackages()
-	}()
+	wg.Add(2)
 
 	go func() {
 		defer wg.Done()
-		result.Casks = s.listService.GetBrewCasks()
+		result.setInventory(s.listService.LoadInventory())
 	}()
 
 	go func() {
 		defer wg.Done()
-		result.Taps = s.listService.GetBrewTaps()
+		result.Taps = TapRows(s.listService.Taps())
 	}()
 
 	// Wait for database update and other data to complete
 	wg.Wait()
 
-	// Fetch outdated packages and leaves after parallel brew commands finish
+	// Fetch outdated packages after parallel brew commands finish
 	// to avoid Homebrew lock contention causing false timeouts
-	result.Updatable = s.outdatedService.GetBrewUpdatablePackages()
-	result.Leaves = s.listService.GetBrewLeaves()
+	result.Updatable = OutdatedRows(s.outdatedService.OutdatedPackages())
 
 	return result
 }
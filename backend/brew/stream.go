@@ -2,14 +2,19 @@ package brew
 
 import (
 	"bufio"
+	"context"
 	"os/exec"
 	"strings"
 	"sync"
+
+	"WailBrew/backend/system"
 )
 
 // streamPhase identifies which step of a streaming command failed, so
 // callers can select the right localized error message. phaseNone means the
-// command completed successfully.
+// command completed successfully. phaseCancelled is reported instead of
+// phaseRun when the command was stopped because its context was cancelled, so
+// a user-requested cancel is never presented as a failure.
 type streamPhase int
 
 const (
@@ -18,10 +23,11 @@ const (
 	phaseStderrPipe
 	phaseStart
 	phaseRun
+	phaseCancelled
 )
 
-// runStreamingCommand starts cmd and streams its stdout/stderr line-by-line to
-// onStdout/onStderr (invoked with trimmed, non-empty lines) until the command
+// runStreamingCommand runs name with args and env and streams its stdout/stderr
+// line-by-line to onStdout/onStderr (invoked with trimmed, non-empty lines) until the command
 // exits and all output has been drained. The scanner goroutines are always
 // waited on before cmd.Wait() is called, so a "complete" event fired by the
 // caller right after this returns is guaranteed to follow every progress
@@ -31,7 +37,16 @@ const (
 // the full captured stderr text (trimmed lines, newline-joined) for callers
 // that need to inspect it for known error patterns (e.g. "app already
 // exists", "untrusted tap"), and the underlying error.
-func runStreamingCommand(cmd *exec.Cmd, onStdout, onStderr func(line string)) (phase streamPhase, stderrText string, err error) {
+//
+// The command is bound to ctx. brew is a shell script that execs Ruby, which in
+// turn spawns curl, git and installers, so killing only the direct child would
+// leave the real work running. The command therefore gets its own process
+// group and cancellation kills the whole group.
+func runStreamingCommand(ctx context.Context, name string, args, env []string, onStdout, onStderr func(line string)) (phase streamPhase, stderrText string, err error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	system.ApplyEnvironment(cmd, env)
+	killProcessGroupOnCancel(cmd)
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return phaseStdoutPipe, "", err
@@ -43,6 +58,9 @@ func runStreamingCommand(cmd *exec.Cmd, onStdout, onStderr func(line string)) (p
 	}
 
 	if err := cmd.Start(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return phaseCancelled, "", ctxErr
+		}
 		return phaseStart, "", err
 	}
 
@@ -79,6 +97,9 @@ func runStreamingCommand(cmd *exec.Cmd, onStdout, onStderr func(line string)) (p
 	// Wait for scanners to drain before calling cmd.Wait().
 	wg.Wait()
 	if waitErr := cmd.Wait(); waitErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return phaseCancelled, stderrOutput.String(), ctxErr
+		}
 		return phaseRun, stderrOutput.String(), waitErr
 	}
 	return phaseNone, stderrOutput.String(), nil
@@ -1,15 +1,14 @@
 package brew
 
 import (
-	"os/exec"
+	"context"
 	"testing"
+	"time"
 )
 
 func TestRunStreamingCommand_Success(t *testing.T) {
-	cmd := exec.Command("/bin/sh", "-c", "echo out1; echo err1 >&2; echo out2")
-
 	var stdoutLines, stderrLines []string
-	phase, stderrText, err := runStreamingCommand(cmd,
+	phase, stderrText, err := runStreamingCommand(context.Background(), "/bin/sh", []string{"-c", "echo out1; echo err1 >&2; echo out2"}, nil,
 		func(line string) { stdoutLines = append(stdoutLines, line) },
 		func(line string) { stderrLines = append(stderrLines, line) },
 	)
@@ -32,9 +31,7 @@ func TestRunStreamingCommand_Success(t *testing.T) {
 }
 
 func TestRunStreamingCommand_RunFailure(t *testing.T) {
-	cmd := exec.Command("/bin/sh", "-c", "echo boom >&2; exit 1")
-
-	phase, stderrText, err := runStreamingCommand(cmd, nil, nil)
+	phase, stderrText, err := runStreamingCommand(context.Background(), "/bin/sh", []string{"-c", "echo boom >&2; exit 1"}, nil, nil, nil)
 
 	if phase != phaseRun {
 		t.Fatalf("expected phaseRun, got %v", phase)
@@ -48,9 +45,7 @@ func TestRunStreamingCommand_RunFailure(t *testing.T) {
 }
 
 func TestRunStreamingCommand_StartFailure(t *testing.T) {
-	cmd := exec.Command("/nonexistent-binary-should-not-exist")
-
-	phase, _, err := runStreamingCommand(cmd, nil, nil)
+	phase, _, err := runStreamingCommand(context.Background(), "/nonexistent-binary-should-not-exist", nil, nil, nil, nil)
 
 	if phase != phaseStart {
 		t.Fatalf("expected phaseStart, got %v", phase)
@@ -59,3 +54,37 @@ func TestRunStreamingCommand_StartFailure(t *testing.T) {
 		t.Fatal("expected an error")
 	}
 }
+
+// brew execs Ruby, which spawns its own children that inherit the output pipes.
+// Killing only the direct child would leave them running and keep the pipes
+// open, so a cancel must take down the whole process group and return promptly.
+func TestRunStreamingCommand_CancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	var phase streamPhase
+	var err error
+	go func() {
+		defer close(done)
+		phase, _, err = runStreamingCommand(ctx, "/bin/sh", []string{"-c", "echo ready; sleep 30 & wait"}, nil,
+			func(string) { close(started) }, nil)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runStreamingCommand did not return after cancellation")
+	}
+
+	if phase != phaseCancelled {
+		t.Fatalf("expected phaseCancelled, got %v (err=%v)", phase, err)
+	}
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
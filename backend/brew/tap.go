@@ -3,11 +3,8 @@ package brew
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
-
-	"WailBrew/backend/system"
 )
 
 // untrustedTapRe matches an "owner/repo" tap token in Homebrew trust error output.
@@ -62,38 +59,38 @@ func ExtractUntrustedTap(output string) string {
 
 // TapService provides tap/untap repository functionality
 type TapService struct {
-	brewPath       string
-	getBrewEnvFunc func() []string
-	getBackendMsg  func(string, map[string]string) string
-	eventEmitter   EventEmitter
+	runner        Runner
+	getBackendMsg func(string, map[string]string) string
+	eventEmitter  EventEmitter
 }
 
 // NewTapService creates a new tap service
 func NewTapService(
-	brewPath string,
-	getBrewEnvFunc func() []string,
+	runner Runner,
 	getBackendMsg func(string, map[string]string) string,
 	eventEmitter EventEmitter,
 ) *TapService {
 	return &TapService{
-		brewPath:       brewPath,
-		getBrewEnvFunc: getBrewEnvFunc,
-		getBackendMsg:  getBackendMsg,
-		eventEmitter:   eventEmitter,
+		runner:        runner,
+		getBackendMsg: getBackendMsg,
+		eventEmitter:  eventEmitter,
 	}
 }
 
 // TapBrewRepository taps a repository with live progress updates.
 // repositoryURL is optional; when provided it is passed as the second argument to brew tap.
 func (s *TapService) TapBrewRepository(ctx context.Context, repositoryName, repositoryURL string) string {
+	result, _ := s.tap(ctx, repositoryName, repositoryURL)
+	return result
+}
+
+// tap is TapBrewRepository also reporting whether it succeeded.
+func (s *TapService) tap(ctx context.Context, repositoryName, repositoryURL string) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.tap.start", map[string]string{"name": repositoryName})
 	s.eventEmitter.Emit("repositoryTapProgress", startMessage)
 
-	cmd := exec.Command(s.brewPath, BuildTapArgs(repositoryName, repositoryURL)...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, stderrStr, err := runStreamingCommand(cmd,
+	phase, stderrStr, err := s.runner.Stream(ctx, BuildTapArgs(repositoryName, repositoryURL),
 		func(line string) { s.eventEmitter.Emit("repositoryTapProgress", fmt.Sprintf("📦 %s", line)) },
 		func(line string) { s.eventEmitter.Emit("repositoryTapProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -102,15 +99,20 @@ func (s *TapService) TapBrewRepository(ctx context.Context, repositoryName, repo
 	case phaseStdoutPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingTap", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("repositoryTapProgress", cancelMsg)
+		s.eventEmitter.Emit("repositoryTapComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		// Homebrew 6: tap may be blocked because it is not trusted. Surface a
 		// distinct event so the UI can ask the user to trust it and retry.
@@ -124,26 +126,29 @@ func (s *TapService) TapBrewRepository(ctx context.Context, repositoryName, repo
 		errorMsg := s.getBackendMsg("backend.tap.failed", map[string]string{"name": repositoryName, "error": err.Error()})
 		s.eventEmitter.Emit("repositoryTapProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryTapComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	// Success
 	successMsg := s.getBackendMsg("backend.tap.success", map[string]string{"name": repositoryName})
 	s.eventEmitter.Emit("repositoryTapProgress", successMsg)
 	s.eventEmitter.Emit("repositoryTapComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
 
 // UntapBrewRepository untaps a repository with live progress updates
 func (s *TapService) UntapBrewRepository(ctx context.Context, repositoryName string) string {
+	result, _ := s.untap(ctx, repositoryName)
+	return result
+}
+
+// untap is UntapBrewRepository also reporting whether it succeeded.
+func (s *TapService) untap(ctx context.Context, repositoryName string) (string, bool) {
 	// Emit initial progress
 	startMessage := s.getBackendMsg("backend.untap.start", map[string]string{"name": repositoryName})
 	s.eventEmitter.Emit("repositoryUntapProgress", startMessage)
 
-	cmd := exec.Command(s.brewPath, BuildUntapArgs(repositoryName)...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, BuildUntapArgs(repositoryName),
 		func(line string) { s.eventEmitter.Emit("repositoryUntapProgress", fmt.Sprintf("🗑️ %s", line)) },
 		func(line string) { s.eventEmitter.Emit("repositoryUntapProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -152,39 +157,47 @@ func (s *TapService) UntapBrewRepository(ctx context.Context, repositoryName str
 	case phaseStdoutPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryUntapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryUntapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingUntap", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryUntapProgress", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("repositoryUntapProgress", cancelMsg)
+		s.eventEmitter.Emit("repositoryUntapComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		errorMsg := s.getBackendMsg("backend.untap.failed", map[string]string{"name": repositoryName, "error": err.Error()})
 		s.eventEmitter.Emit("repositoryUntapProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryUntapComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	// Success
 	successMsg := s.getBackendMsg("backend.untap.success", map[string]string{"name": repositoryName})
 	s.eventEmitter.Emit("repositoryUntapProgress", successMsg)
 	s.eventEmitter.Emit("repositoryUntapComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
 
 // TrustBrewTap trusts a (non-official) tap so Homebrew 6 will load and install
 // from it. Streams progress via the "repositoryTrustProgress" event.
 func (s *TapService) TrustBrewTap(ctx context.Context, tapName string) string {
+	result, _ := s.trust(ctx, tapName)
+	return result
+}
+
+// trust is TrustBrewTap also reporting whether it succeeded.
+func (s *TapService) trust(ctx context.Context, tapName string) (string, bool) {
 	startMessage := s.getBackendMsg("backend.trust.start", map[string]string{"name": tapName})
 	s.eventEmitter.Emit("repositoryTrustProgress", startMessage)
 
-	cmd := exec.Command(s.brewPath, BuildTrustArgs(tapName)...)
-	system.ApplyEnvironment(cmd, s.getBrewEnvFunc())
-
-	phase, _, err := runStreamingCommand(cmd,
+	phase, _, err := s.runner.Stream(ctx, BuildTrustArgs(tapName),
 		func(line string) { s.eventEmitter.Emit("repositoryTrustProgress", fmt.Sprintf("🔐 %s", line)) },
 		func(line string) { s.eventEmitter.Emit("repositoryTrustProgress", fmt.Sprintf("⚠️ %s", line)) },
 	)
@@ -194,26 +207,31 @@ func (s *TapService) TrustBrewTap(ctx context.Context, tapName string) string {
 		errorMsg := s.getBackendMsg("backend.errors.creatingPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTrustProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryTrustComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStderrPipe:
 		errorMsg := s.getBackendMsg("backend.errors.creatingErrorPipe", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTrustProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryTrustComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	case phaseStart:
 		errorMsg := s.getBackendMsg("backend.errors.startingTrust", map[string]string{"error": err.Error()})
 		s.eventEmitter.Emit("repositoryTrustProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryTrustComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
+	case phaseCancelled:
+		cancelMsg := s.getBackendMsg("backend.operation.cancelled", map[string]string{})
+		s.eventEmitter.Emit("repositoryTrustProgress", cancelMsg)
+		s.eventEmitter.Emit("repositoryTrustComplete", cancelMsg)
+		return cancelMsg, false
 	case phaseRun:
 		errorMsg := s.getBackendMsg("backend.trust.failed", map[string]string{"name": tapName, "error": err.Error()})
 		s.eventEmitter.Emit("repositoryTrustProgress", errorMsg)
 		s.eventEmitter.Emit("repositoryTrustComplete", errorMsg)
-		return errorMsg
+		return errorMsg, false
 	}
 
 	successMsg := s.getBackendMsg("backend.trust.success", map[string]string{"name": tapName})
 	s.eventEmitter.Emit("repositoryTrustProgress", successMsg)
 	s.eventEmitter.Emit("repositoryTrustComplete", successMsg)
-	return successMsg
+	return successMsg, true
 }
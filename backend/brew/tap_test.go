@@ -0,0 +1,91 @@
+package brew
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTapBrewRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		repo       string
+		url        string
+		wantArgs   string
+		recording  brewRecording
+		wantResult string
+		wantTrust  string
+	}{
+		{
+			name:       "success",
+			repo:       "acme/tools",
+			wantArgs:   "tap acme/tools",
+			recording:  brewRecording{stdout: "==> Tapping acme/tools\n"},
+			wantResult: "backend.tap.success",
+		},
+		{
+			name:       "custom url",
+			repo:       "acme/tools",
+			url:        " https://git.example.com/acme/homebrew-tools ",
+			wantArgs:   "tap acme/tools https://git.example.com/acme/homebrew-tools",
+			wantResult: "backend.tap.success",
+		},
+		{
+			name:       "untrusted tap asks for trust",
+			repo:       "acme/tools",
+			wantArgs:   "tap acme/tools",
+			recording:  brewRecording{stderr: recordedUntrustedTap, exitCode: 1},
+			wantResult: "backend.tap.failed",
+			wantTrust:  "acme/tools",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().script(tt.wantArgs, tt.recording)
+			service, emitter := newFakeService(fb)
+
+			if got := service.TapBrewRepository(context.Background(), tt.repo, tt.url); got != tt.wantResult {
+				t.Fatalf("result = %q, want %q, calls: %v", got, tt.wantResult, fb.calls)
+			}
+			if got := emitter.first("repositoryTapTrustRequired"); got != tt.wantTrust {
+				t.Fatalf("trust event = %q, want %q", got, tt.wantTrust)
+			}
+		})
+	}
+}
+
+func TestTrustThenInstallFromTap(t *testing.T) {
+	fb := newFakeBrew().
+		script("install acme/tools/widget",
+			brewRecording{stderr: recordedUntrustedTap, exitCode: 1},
+			brewRecording{stdout: "==> Installing widget from acme/tools\n"},
+		).
+		script("trust acme/tools", brewRecording{})
+	service, emitter := newFakeService(fb)
+
+	if got := service.InstallBrewPackage(context.Background(), "acme/tools/widget"); got != "backend.install.failed" {
+		t.Fatalf("first install = %q", got)
+	}
+	if emitter.count("packageInstallTrustRequired") != 1 {
+		t.Fatal("expected a trust prompt after the blocked install")
+	}
+	if got := service.TrustBrewTap(context.Background(), "acme/tools"); got != "backend.trust.success" {
+		t.Fatalf("trust = %q", got)
+	}
+	if got := service.InstallBrewPackage(context.Background(), "acme/tools/widget"); got != "backend.install.success" {
+		t.Fatalf("retried install = %q", got)
+	}
+}
+
+func TestUntapBrewRepository_failure(t *testing.T) {
+	fb := newFakeBrew().script("untap acme/tools",
+		brewRecording{stderr: "Error: Refusing to untap acme/tools because it contains the following installed formulae:\nwidget\n", exitCode: 1})
+	service, emitter := newFakeService(fb)
+
+	if got := service.UntapBrewRepository(context.Background(), "acme/tools"); got != "backend.untap.failed" {
+		t.Fatalf("result = %q", got)
+	}
+	if !emitter.has("repositoryUntapProgress", "⚠️ widget") {
+		t.Fatalf("stderr was not streamed: %v", emitter.data)
+	}
+}
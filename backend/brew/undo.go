@@ -0,0 +1,316 @@
+package brew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// JournalInverse is the operation that reverses a journal entry, worked out
+// from the state captured before the entry ran.
+type JournalInverse struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	// Version is the version an uninstalled package had, so undo can tell
+	// when Homebrew no longer offers it.
+	Version string `json:"version,omitempty"`
+	// URL is the custom remote an untapped repository was cloned from.
+	URL  string   `json:"url,omitempty"`
+	Args []string `json:"args"`
+}
+
+// plannedInverse is the inverse kind on target will have if it succeeds, or
+// nil when it cannot be undone or would change nothing worth undoing, such
+// as installing a package that is already installed.
+func (s *serviceImpl) plannedInverse(kind, target string, before *Inventory) *JournalInverse {
+	switch kind {
+	case "install", "uninstall", "pin", "unpin":
+		if before == nil {
+			return nil
+		}
+		version, pinned, installed := findInstalled(before, target)
+		switch {
+		case kind == "install" && !installed:
+			return &JournalInverse{Kind: "uninstall", Target: target, Args: BuildUninstallArgs(target, false, false)}
+		case kind == "uninstall" && installed:
+			return &JournalInverse{Kind: "install", Target: target, Version: version, Args: BuildInstallArgs(target)}
+		case kind == "pin" && installed && !pinned:
+			return &JournalInverse{Kind: "unpin", Target: target, Args: BuildUnpinArgs(target)}
+		case kind == "unpin" && pinned:
+			return &JournalInverse{Kind: "pin", Target: target, Args: BuildPinArgs(target)}
+		}
+	case "link", "unlink":
+		if before == nil {
+			return nil
+		}
+		formula := findFormula(before, target)
+		switch {
+		case formula == nil:
+		case kind == "link" && !formula.Linked:
+			return &JournalInverse{Kind: "unlink", Target: target, Args: BuildUnlinkArgs(target)}
+		case kind == "unlink" && formula.Linked:
+			return &JournalInverse{Kind: "link", Target: target, Args: BuildLinkArgs(target, false, formula.KegOnly)}
+		}
+	case "tap", "untap":
+		taps, err := s.listService.Taps()
+		if err != nil {
+			return nil
+		}
+		tapped := false
+		for _, tap := range taps {
+			tapped = tapped || tap.Name == target
+		}
+		if kind == "tap" && !tapped {
+			return &JournalInverse{Kind: "untap", Target: target, Args: BuildUntapArgs(target)}
+		}
+		if kind == "untap" && tapped {
+			url := s.customTapRemote(target)
+			return &JournalInverse{Kind: "tap", Target: target, URL: url, Args: BuildTapArgs(target, url)}
+		}
+	}
+	return nil
+}
+
+// findInstalled looks name up among the installed formulae and casks.
+func findInstalled(inventory *Inventory, name string) (version string, pinned, installed bool) {
+	if formula := findFormula(inventory, name); formula != nil {
+		return formula.Version, formula.Pinned, true
+	}
+	for _, cask := range inventory.Casks {
+		if cask.Name == name || qualifiedName(cask.Name, cask.Tap) == name {
+			return cask.Version, false, true
+		}
+	}
+	return "", false, false
+}
+
+// findFormula looks name up among the installed formulae.
+func findFormula(inventory *Inventory, name string) *InstalledFormula {
+	for i, formula := range inventory.Formulae {
+		if formula.Name == name || qualifiedName(formula.Name, formula.Tap) == name {
+			return &inventory.Formulae[i]
+		}
+	}
+	return nil
+}
+
+// customTapRemote returns the remote a tap was cloned from when it is not the
+// default GitHub one, which brew tap finds again by name.
+func (s *serviceImpl) customTapRemote(name string) string {
+	output, err := s.runner.Run("tap-info", "--json=v1", name)
+	if err != nil {
+		return ""
+	}
+	jsonOutput, _, err := s.extractJSON(string(output))
+	if err != nil {
+		return ""
+	}
+	var taps []struct {
+		Remote       string `json:"remote"`
+		CustomRemote bool   `json:"custom_remote"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &taps); err != nil || len(taps) == 0 || !taps[0].CustomRemote {
+		return ""
+	}
+	return taps[0].Remote
+}
+
+type undoOfKey struct{}
+
+// withUndoOf marks the operation ctx belongs to as the undo of entry id.
+func withUndoOf(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, undoOfKey{}, id)
+}
+
+func undoneEntry(ctx context.Context) string {
+	id, _ := ctx.Value(undoOfKey{}).(string)
+	return id
+}
+
+// LastUndoable returns the newest operation that has not been undone yet,
+// skipping undo operations themselves and failed operations that changed
+// nothing. It returns nil when there is none.
+func (j *OperationJournal) LastUndoable() (*JournalEntry, error) {
+	entries, err := j.entries()
+	if err != nil {
+		return nil, err
+	}
+	undone := map[string]bool{}
+	for _, entry := range entries {
+		if entry.UndoOf != "" && entry.Success {
+			undone[entry.UndoOf] = true
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.UndoOf != "" || undone[entry.ID] || (!entry.Success && len(entry.Changes) == 0) {
+			continue
+		}
+		return &entry, nil
+	}
+	return nil, nil
+}
+
+// GetLastUndoableOperation returns the operation UndoLastOperation would
+// reverse, or nil when there is nothing to undo.
+func (s *serviceImpl) GetLastUndoableOperation() (*JournalEntry, error) {
+	if s.journal == nil {
+		return nil, nil
+	}
+	return s.journal.LastUndoable()
+}
+
+// UndoLastOperation reverses the newest operation in the journal by running
+// its inverse through the regular queued action, whose own progress events
+// stream as usual. Steps and warnings go to "undoProgress" and the outcome to
+// "undoComplete". When the inverse cannot restore the exact previous state,
+// for example because the removed version is no longer offered, it still
+// runs and the warnings say what differs.
+func (s *serviceImpl) UndoLastOperation(ctx context.Context) string {
+	finish := func(message string) string {
+		s.eventEmitter.Emit("undoProgress", message)
+		s.eventEmitter.Emit("undoComplete", message)
+		return message
+	}
+
+	entry, err := s.GetLastUndoableOperation()
+	if err != nil {
+		return finish(s.getBackendMsg("backend.undo.failed", map[string]string{"operation": "", "error": err.Error()}))
+	}
+	if entry == nil {
+		return finish(s.getBackendMsg("backend.undo.nothingToUndo", map[string]string{}))
+	}
+	params := map[string]string{"operation": strings.TrimSpace(entry.Kind + " " + entry.Target)}
+	if entry.Inverse == nil {
+		return finish(s.getBackendMsg("backend.undo.notUndoable", params))
+	}
+	inverse := entry.Inverse
+
+	s.eventEmitter.Emit("undoProgress", s.getBackendMsg("backend.undo.start",
+		map[string]string{"operation": params["operation"], "command": FormatCommand(inverse.Args)}))
+	warnings := s.undoWarnings(entry)
+	for _, warning := range warnings {
+		s.eventEmitter.Emit("undoProgress", warning)
+	}
+
+	result, ok := s.mutateOK(withUndoOf(ctx, entry.ID), "undo", inverse.Target, func(ctx context.Context) (string, bool) {
+		return s.runInverse(ctx, inverse)
+	})
+	if !ok {
+		params["error"] = result
+		return finish(s.getBackendMsg("backend.undo.failed", params))
+	}
+	if len(warnings) > 0 {
+		return finish(s.getBackendMsg("backend.undo.inexact", params))
+	}
+	return finish(s.getBackendMsg("backend.undo.success", params))
+}
+
+// runInverse runs inverse with the action that normally performs it and
+// reports whether it succeeded.
+func (s *serviceImpl) runInverse(ctx context.Context, inverse *JournalInverse) (string, bool) {
+	switch inverse.Kind {
+	case "install":
+		return s.actionsService.installPackage(ctx, inverse.Target)
+	case "uninstall":
+		return s.actionsService.removePackage(ctx, inverse.Target, false)
+	case "pin":
+		return s.actionsService.setPinned(ctx, inverse.Target, BuildPinArgs(inverse.Target), "backend.pin")
+	case "unpin":
+		return s.actionsService.setPinned(ctx, inverse.Target, BuildUnpinArgs(inverse.Target), "backend.unpin")
+	case "link":
+		return s.actionsService.linkPackage(ctx, inverse.Target, false, slices.Contains(inverse.Args, "--force"))
+	case "unlink":
+		return s.actionsService.unlinkPackage(ctx, inverse.Target)
+	case "tap":
+		return s.tapService.tap(ctx, inverse.Target, inverse.URL)
+	case "untap":
+		return s.tapService.untap(ctx, inverse.Target)
+	}
+	return s.getBackendMsg("backend.undo.notUndoable", map[string]string{"operation": inverse.Kind + " " + inverse.Target}), false
+}
+
+// undoWarnings lists how undoing entry will fall short of the state before
+// it ran.
+func (s *serviceImpl) undoWarnings(entry *JournalEntry) []string {
+	inverse := entry.Inverse
+	params := map[string]string{"name": inverse.Target}
+	var warnings []string
+
+	switch inverse.Kind {
+	case "install":
+		for _, command := range entry.Commands {
+			if slices.Contains(command.Args, "--zap") {
+				warnings = append(warnings, s.getBackendMsg("backend.undo.zapped", params))
+				break
+			}
+		}
+		if current, err := s.availableVersion(inverse.Target); err == nil && inverse.Version != "" &&
+			stripRevision(inverse.Version) != stripRevision(current) {
+			warnings = append(warnings, s.getBackendMsg("backend.undo.versionChanged", map[string]string{
+				"name": inverse.Target, "version": inverse.Version, "current": current,
+			}))
+		}
+	case "unlink":
+		for _, command := range entry.Commands {
+			if slices.Contains(command.Args, "--overwrite") {
+				warnings = append(warnings, s.getBackendMsg("backend.undo.overwritten", params))
+				break
+			}
+		}
+	case "uninstall":
+		added := 0
+		for _, change := range entry.Changes {
+			if change.From == "" && !samePackage(change.Name, inverse.Target) {
+				added++
+			}
+		}
+		if added > 0 {
+			params["count"] = fmt.Sprintf("%d", added)
+			warnings = append(warnings, s.getBackendMsg("backend.undo.dependenciesRemain", params))
+		}
+	}
+	return warnings
+}
+
+// availableVersion is the version brew would install for name today.
+func (s *serviceImpl) availableVersion(name string) (string, error) {
+	output, err := s.runner.Run("info", "--json=v2", name)
+	if err != nil {
+		return "", err
+	}
+	jsonOutput, _, err := s.extractJSON(string(output))
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		Formulae []struct {
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+		} `json:"formulae"`
+		Casks []struct {
+			Version string `json:"version"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal([]byte(jsonOutput), &info); err != nil {
+		return "", err
+	}
+	switch {
+	case len(info.Formulae) > 0:
+		return info.Formulae[0].Versions.Stable, nil
+	case len(info.Casks) > 0:
+		return info.Casks[0].Version, nil
+	}
+	return "", fmt.Errorf("no formula or cask named %s", name)
+}
+
+// stripRevision drops a formula's rebuild suffix, e.g. 1.7.1_1 -> 1.7.1.
+func stripRevision(version string) string {
+	if i := strings.LastIndex(version, "_"); i > 0 {
+		return version[:i]
+	}
+	return version
+}
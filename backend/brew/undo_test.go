@@ -0,0 +1,145 @@
+package brew
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	inventoryWithoutWget = `{"formulae": [], "casks": []}`
+	inventoryWithWget    = `{"formulae": [{"name": "wget", "full_name": "wget", "tap": "homebrew/core",
+		"installed": [{"version": "1.24.5", "installed_on_request": true}]}], "casks": []}`
+	inventoryWithPinnedWget = `{"formulae": [{"name": "wget", "full_name": "wget", "tap": "homebrew/core", "pinned": true,
+		"installed": [{"version": "1.24.5", "installed_on_request": true}]}], "casks": []}`
+)
+
+func TestUndoLastOperation_install(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed",
+			brewRecording{stdout: inventoryWithoutWget}, brewRecording{stdout: inventoryWithWget},
+			brewRecording{stdout: inventoryWithWget}, brewRecording{stdout: inventoryWithoutWget}).
+		script("install wget", brewRecording{}).
+		script("uninstall wget", brewRecording{})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, emitter := newFakeServiceWithJournal(fb, journal)
+
+	service.InstallBrewPackage(context.Background(), "wget")
+	last, err := service.GetLastUndoableOperation()
+	if err != nil || last == nil || last.Inverse == nil {
+		t.Fatalf("GetLastUndoableOperation = %+v, %v", last, err)
+	}
+	if want := BuildUninstallArgs("wget", false, false); !reflect.DeepEqual(last.Inverse.Args, want) {
+		t.Fatalf("inverse args = %v, want %v", last.Inverse.Args, want)
+	}
+
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.success" {
+		t.Fatalf("UndoLastOperation = %q", got)
+	}
+	if fb.invoked("uninstall wget") != 1 || emitter.count("undoComplete") != 1 {
+		t.Fatal("expected the inverse to run through the regular uninstall")
+	}
+	page, _ := service.GetOperationHistory(JournalFilter{Kind: "undo"})
+	if page.Total != 1 || page.Entries[0].UndoOf != last.ID || page.Entries[0].Inverse != nil {
+		t.Fatalf("undo entry = %+v, want one recording what it undid and no inverse of its own", page.Entries)
+	}
+
+	// Neither the undone install nor the undo itself can be undone again.
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.nothingToUndo" {
+		t.Fatalf("second UndoLastOperation = %q", got)
+	}
+}
+
+func TestUndoLastOperation_inexact(t *testing.T) {
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	entry := JournalEntry{
+		ID:       "1",
+		Kind:     "uninstall",
+		Target:   "browser",
+		Success:  true,
+		Commands: []JournalCommand{{Args: BuildUninstallArgs("browser", true, true)}},
+		Inverse:  &JournalInverse{Kind: "install", Target: "browser", Version: "128.0", Args: BuildInstallArgs("browser")},
+	}
+	if err := journal.Append(entry); err != nil {
+		t.Fatal(err)
+	}
+	fb := newFakeBrew().
+		script("info --json=v2 browser", brewRecording{stdout: `{"formulae": [], "casks": [{"token": "browser", "version": "129.0"}]}`}).
+		script("install browser", brewRecording{})
+	service, emitter := newFakeServiceWithJournal(fb, journal)
+
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.inexact" {
+		t.Fatalf("UndoLastOperation = %q", got)
+	}
+	if !emitter.has("undoProgress", "backend.undo.zapped") || !emitter.has("undoProgress", "backend.undo.versionChanged") {
+		t.Fatal("expected warnings for the zapped data and the newer version")
+	}
+	if fb.invoked("install browser") != 1 {
+		t.Fatal("an inexact inverse must still run")
+	}
+}
+
+func TestUndoLastOperation_notUndoable(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: inventoryWithPinnedWget}).
+		script("pin wget", brewRecording{})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	// Pinning an already pinned formula changes nothing, so unpinning it
+	// would not restore the previous state.
+	service.PinBrewPackage(context.Background(), "wget")
+
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.notUndoable" {
+		t.Fatalf("UndoLastOperation = %q", got)
+	}
+	if fb.invoked("unpin wget") != 0 {
+		t.Fatal("nothing may run when there is no inverse")
+	}
+}
+
+func TestUndoLastOperation_unlink(t *testing.T) {
+	linked := `{"formulae": [{"name": "python@3.11", "full_name": "python@3.11", "tap": "homebrew/core",
+		"linked_keg": "3.11.10", "installed": [{"version": "3.11.10", "installed_on_request": true}]}], "casks": []}`
+	unlinked := `{"formulae": [{"name": "python@3.11", "full_name": "python@3.11", "tap": "homebrew/core",
+		"linked_keg": null, "installed": [{"version": "3.11.10", "installed_on_request": true}]}], "casks": []}`
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: linked}, brewRecording{stdout: unlinked},
+			brewRecording{stdout: unlinked}, brewRecording{stdout: linked}).
+		script("unlink python@3.11", brewRecording{}).
+		script("link python@3.11", brewRecording{})
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	service.UnlinkBrewPackage(context.Background(), "python@3.11")
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.success" {
+		t.Fatalf("UndoLastOperation = %q", got)
+	}
+	if fb.invoked("link python@3.11") != 1 {
+		t.Fatal("expected the unlink to be undone by linking again")
+	}
+}
+
+func TestUndoLastOperation_failed(t *testing.T) {
+	journal := NewOperationJournal(filepath.Join(t.TempDir(), "operation-journal.jsonl"))
+	entry := JournalEntry{
+		ID:      "1",
+		Kind:    "untap",
+		Target:  "acme/tools",
+		Success: true,
+		Inverse: &JournalInverse{Kind: "tap", Target: "acme/tools", Args: BuildTapArgs("acme/tools", "")},
+	}
+	if err := journal.Append(entry); err != nil {
+		t.Fatal(err)
+	}
+	fb := newFakeBrew().script("tap acme/tools", brewRecording{stderr: "Error: repository not found", exitCode: 1})
+	service, _ := newFakeServiceWithJournal(fb, journal)
+
+	if got := service.UndoLastOperation(context.Background()); got != "backend.undo.failed" {
+		t.Fatalf("UndoLastOperation = %q", got)
+	}
+	if last, _ := service.GetLastUndoableOperation(); last == nil || last.ID != "1" {
+		t.Fatalf("a failed undo must leave the operation undoable, got %+v", last)
+	}
+}
@@ -0,0 +1,107 @@
+package brew
+
+// UninstallPlan describes what removing a set of packages would do, so it can
+// be confirmed before anything runs.
+type UninstallPlan struct {
+	Packages []string `json:"packages"`
+	// NotInstalled lists requested names that are not installed.
+	NotInstalled []string `json:"notInstalled"`
+	// Blockers are packages with installed dependents outside the plan;
+	// `brew uninstall` refuses to remove them, and Blocked is set.
+	Blockers []UninstallBlocker `json:"blockers"`
+	Blocked  bool               `json:"blocked"`
+	// Orphans are dependencies nothing would need afterwards. Homebrew's
+	// autoremove uninstalls them once the packages are gone.
+	Orphans []OrphanedFormula `json:"orphans"`
+	// Services are the Homebrew services that uninstalling stops.
+	Services      []ServiceEntry `json:"services"`
+	ReclaimedSize string         `json:"reclaimedSize"`
+	OrphanedSize  string         `json:"orphanedSize"`
+}
+
+// UninstallBlocker is a package that other installed packages still depend on.
+type UninstallBlocker struct {
+	Name       string   `json:"name"`
+	Dependents []string `json:"dependents"`
+}
+
+// PlanUninstall works out the consequences of removing packageNames from the
+// dependency graph, the service list and the size service, without running
+// any mutating command.
+func (s *serviceImpl) PlanUninstall(packageNames []string) (*UninstallPlan, error) {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &UninstallPlan{
+		Packages:     []string{},
+		NotInstalled: []string{},
+		Blockers:     []UninstallBlocker{},
+		Orphans:      []OrphanedFormula{},
+		Services:     []ServiceEntry{},
+	}
+
+	// Names are compared by the full name of their graph node, since the
+	// request, the graph and the service list each may use either form.
+	removing := make(map[string]bool)
+	var formulae, casks []string
+	for _, name := range packageNames {
+		node := graph.find(name)
+		if node == nil {
+			plan.NotInstalled = append(plan.NotInstalled, name)
+			continue
+		}
+		plan.Packages = append(plan.Packages, name)
+		removing[node.fullName] = true
+		if node.cask {
+			casks = append(casks, name)
+		} else {
+			formulae = append(formulae, name)
+		}
+	}
+
+	for _, name := range plan.Packages {
+		var blocking []string
+		for _, user := range graph.find(name).users {
+			if !removing[user] {
+				blocking = append(blocking, user)
+			}
+		}
+		if len(blocking) > 0 {
+			blocking = graph.names(blocking)
+			plan.Blockers = append(plan.Blockers, UninstallBlocker{Name: name, Dependents: blocking})
+		}
+	}
+	plan.Blocked = len(plan.Blockers) > 0
+
+	orphans := graph.Orphans(plan.Packages)
+	orphanSizes := s.sizeService.GetPackageSizes(orphans, false)
+	sizes := make([]string, 0, len(orphans))
+	for _, name := range orphans {
+		plan.Orphans = append(plan.Orphans, OrphanedFormula{Name: name, Size: orphanSizes[name]})
+		sizes = append(sizes, orphanSizes[name])
+	}
+	plan.OrphanedSize = sumDuSizes(sizes)
+
+	sizes = sizes[:0]
+	for _, size := range s.sizeService.GetPackageSizes(formulae, false) {
+		sizes = append(sizes, size)
+	}
+	for _, size := range s.sizeService.GetPackageSizes(casks, true) {
+		sizes = append(sizes, size)
+	}
+	plan.ReclaimedSize = sumDuSizes(sizes)
+
+	// Services are only available on macOS; without them there is simply
+	// nothing to stop.
+	if services, err := s.servicesService.Services(); err == nil {
+		for _, service := range services {
+			if removing[graph.resolve(service.Name)] && service.Status != "none" {
+				plan.Services = append(plan.Services, service)
+			}
+		}
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,164 @@
+package brew
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanUninstall(t *testing.T) {
+	tests := []struct {
+		name         string
+		packages     []string
+		wantBlockers []UninstallBlocker
+		wantOrphans  []string
+		wantServices []string
+		wantMissing  []string
+	}{
+		{
+			name:         "dependents block removal",
+			packages:     []string{"lib"},
+			wantBlockers: []UninstallBlocker{{Name: "lib", Dependents: []string{"app", "legacy", "tool-app"}}},
+			wantOrphans:  []string{"base"},
+		},
+		{
+			name:         "removing every dependent unblocks and orphans the chain",
+			packages:     []string{"app", "legacy", "tool-app"},
+			wantOrphans:  []string{"base", "lib"},
+			wantServices: []string{"app"},
+		},
+		{
+			name:         "shared dependency is kept",
+			packages:     []string{"app"},
+			wantServices: []string{"app"},
+		},
+		{
+			name:        "not installed",
+			packages:    []string{"missing"},
+			wantMissing: []string{"missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBrew().
+				script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+				script("services list --json", brewRecording{stdout: `[
+					{"name": "app", "status": "started", "user": "me"},
+					{"name": "lib", "status": "none"},
+					{"name": "other", "status": "started"}]`})
+			service, _ := newFakeService(fb)
+
+			plan, err := service.PlanUninstall(tt.packages)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantBlockers == nil {
+				tt.wantBlockers = []UninstallBlocker{}
+			}
+			if !reflect.DeepEqual(plan.Blockers, tt.wantBlockers) || plan.Blocked != (len(tt.wantBlockers) > 0) {
+				t.Fatalf("blockers = %+v (blocked %v), want %+v", plan.Blockers, plan.Blocked, tt.wantBlockers)
+			}
+
+			orphans := []string{}
+			for _, orphan := range plan.Orphans {
+				orphans = append(orphans, orphan.Name)
+			}
+			if tt.wantOrphans == nil {
+				tt.wantOrphans = []string{}
+			}
+			if !reflect.DeepEqual(orphans, tt.wantOrphans) {
+				t.Fatalf("orphans = %v, want %v", orphans, tt.wantOrphans)
+			}
+
+			services := []string{}
+			for _, entry := range plan.Services {
+				services = append(services, entry.Name)
+			}
+			if tt.wantServices == nil {
+				tt.wantServices = []string{}
+			}
+			if !reflect.DeepEqual(services, tt.wantServices) {
+				t.Fatalf("services = %v, want %v", services, tt.wantServices)
+			}
+
+			if tt.wantMissing == nil {
+				tt.wantMissing = []string{}
+			}
+			if !reflect.DeepEqual(plan.NotInstalled, tt.wantMissing) {
+				t.Fatalf("notInstalled = %v, want %v", plan.NotInstalled, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestPlanUninstall_fullNames(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: `{"formulae": [
+			{"name": "openssl@3", "full_name": "openssl@3",
+				"installed": [{"version": "3.3.2", "installed_as_dependency": true}]},
+			{"name": "python@3.12", "full_name": "python@3.12", "dependencies": ["openssl@3"],
+				"installed": [{"version": "3.12.7", "installed_on_request": true, "runtime_dependencies": [
+					{"full_name": "openssl@3", "declared_directly": true}]}]}
+		], "casks": []}`}).
+		script("services list --json", brewRecording{stdout: `[{"name": "openssl@3", "status": "started"}]`})
+	service, _ := newFakeService(fb)
+
+	plan, err := service.PlanUninstall([]string{"homebrew/core/openssl@3", "python@3.12"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Blocked || len(plan.NotInstalled) != 0 {
+		t.Fatalf("plan = %+v, want openssl@3 to be removable along with its only dependent", plan)
+	}
+	if len(plan.Services) != 1 || plan.Services[0].Name != "openssl@3" {
+		t.Fatalf("services = %+v, want the openssl@3 service", plan.Services)
+	}
+}
+
+func TestPlanUninstall_doesNotMutate(t *testing.T) {
+	fb := newFakeBrew().script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory})
+	service, _ := newFakeService(fb)
+
+	if _, err := service.PlanUninstall([]string{"app"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range fb.calls {
+		switch call {
+		case "info --json=v2 --installed", "services list --json", "info --json=v2 app":
+		default:
+			t.Fatalf("planning issued %q", call)
+		}
+	}
+}
+
+func TestDuSizes(t *testing.T) {
+	tests := []struct {
+		size  string
+		bytes int64
+		ok    bool
+	}{
+		{"512B", 512, true},
+		{"4.0K", 4096, true},
+		{"1.5M", 1572864, true},
+		{"2G", 2147483648, true},
+		{"1,5M", 1572864, true},
+		{"Unknown", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if bytes, ok := parseDuSize(tt.size); bytes != tt.bytes || ok != tt.ok {
+			t.Errorf("parseDuSize(%q) = %d, %v; want %d, %v", tt.size, bytes, ok, tt.bytes, tt.ok)
+		}
+	}
+
+	if got := sumDuSizes([]string{"512K", "512K", "Unknown", "27M"}); got != "28M" {
+		t.Errorf("sumDuSizes() = %q, want 28M", got)
+	}
+	if got := sumDuSizes(nil); got != "0B" {
+		t.Errorf("sumDuSizes(nil) = %q, want 0B", got)
+	}
+	if got := formatDuSize(1288490188); got != "1.2G" {
+		t.Errorf("formatDuSize() = %q, want 1.2G", got)
+	}
+}
@@ -0,0 +1,173 @@
+package brew
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// UpgradePreview is what an upgrade would change, parsed from
+// `brew upgrade --dry-run` with the same arguments the upgrade runs.
+type UpgradePreview struct {
+	Command  string                `json:"command"`
+	Packages []UpgradePreviewEntry `json:"packages"`
+	// Warnings are Homebrew diagnostics printed by the dry run.
+	Warnings []string `json:"warnings"`
+}
+
+// UpgradePreviewEntry is one package an upgrade would touch.
+type UpgradePreviewEntry struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	// DependencyOnly is set for packages upgraded because something that
+	// was asked for needs them; RequiredBy names it when brew says so.
+	DependencyOnly bool   `json:"dependencyOnly"`
+	RequiredBy     string `json:"requiredBy,omitempty"`
+	// Greedy is set for casks only upgraded because of the configured
+	// --greedy or --greedy-auto-updates flag.
+	Greedy bool `json:"greedy"`
+}
+
+// dryRunForPattern captures the package a dependency section belongs to, as
+// in "==> Would upgrade 2 dependencies for wget:".
+var dryRunForPattern = regexp.MustCompile(`\bfor (\S+?):?$`)
+
+// parseUpgradeDryRun reads `brew upgrade --dry-run` output. Packages are
+// listed under "==> Would upgrade ..." headers, one per line or comma
+// separated, as "name old -> new" or "name new"; anything outside those
+// sections is informational and skipped. Headers mentioning
+// dependencies or dependents mark the entries below them as dependency-only.
+func parseUpgradeDryRun(output string) ([]UpgradePreviewEntry, []string) {
+	entries := []UpgradePreviewEntry{}
+	warnings := []string{}
+	seen := make(map[string]bool)
+
+	listing := false
+	dependencySection := false
+	requiredBy := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "==>"):
+			listing = strings.Contains(lower, "would upgrade")
+			dependencySection = strings.Contains(lower, "dependenc") || strings.Contains(lower, "dependent")
+			requiredBy = ""
+			if m := dryRunForPattern.FindStringSubmatch(line); dependencySection && m != nil {
+				requiredBy = m[1]
+			}
+			continue
+		case strings.HasPrefix(lower, "warning:") || strings.HasPrefix(lower, "error:"):
+			warnings = append(warnings, line)
+			continue
+		case !listing:
+			continue
+		}
+
+		for _, item := range strings.Split(line, ",") {
+			fields := strings.Fields(item)
+			var entry UpgradePreviewEntry
+			switch {
+			case len(fields) == 4 && fields[2] == "->":
+				entry = UpgradePreviewEntry{Name: fields[0], FromVersion: fields[1], ToVersion: fields[3]}
+			case len(fields) == 2:
+				entry = UpgradePreviewEntry{Name: fields[0], ToVersion: fields[1]}
+			default:
+				continue
+			}
+			if seen[entry.Name] {
+				continue
+			}
+			seen[entry.Name] = true
+			entry.DependencyOnly = dependencySection
+			entry.RequiredBy = requiredBy
+			entries = append(entries, entry)
+		}
+	}
+	return entries, warnings
+}
+
+// PreviewUpgrade dry-runs the upgrade the matching action would run: every
+// outdated package when packageNames is empty, otherwise the named ones.
+func (s *serviceImpl) PreviewUpgrade(packageNames []string) (*UpgradePreview, error) {
+	graph, err := s.listService.DependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	outdatedFlag := s.getOutdatedFlag()
+	var args, held []string
+	switch len(packageNames) {
+	case 0:
+		args, held, err = s.actionsService.upgradeAllPlan()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to check for updates: %v", err)
+		}
+		if args == nil {
+			// Everything outdated is pinned or held back by a policy.
+			return &UpgradePreview{Packages: []UpgradePreviewEntry{}, Warnings: []string{}}, nil
+		}
+	case 1:
+		args = BuildUpgradeArgs(packageNames[0], graph.IsCask(packageNames[0]), outdatedFlag, false)
+	default:
+		args = BuildUpgradeSelectedArgs(packageNames)
+	}
+	args = BuildDryRunArgs(args)
+
+	output, err := s.runner.RunNoCache(args...)
+	if err != nil {
+		return nil, fmt.Errorf("brew upgrade --dry-run failed: %v", err)
+	}
+
+	entries, warnings := parseUpgradeDryRun(string(output))
+	// Held formulae are pinned for the real run, so the dry run overstates it.
+	entries = slices.DeleteFunc(entries, func(entry UpgradePreviewEntry) bool {
+		return containsPackage(held, entry.Name)
+	})
+
+	// brew prints tap formulae by full name, so compare resolved names.
+	requested := make(map[string]bool, len(packageNames))
+	for _, name := range packageNames {
+		requested[graph.resolve(name)] = true
+	}
+	for i := range entries {
+		entry := &entries[i]
+		entry.Type = PackageTypeFormula
+		if graph.IsCask(entry.Name) {
+			entry.Type = PackageTypeCask
+			entry.Greedy = graph.greedyOnly(entry.Name, outdatedFlag)
+		}
+		if len(packageNames) > 0 {
+			entry.DependencyOnly = !requested[graph.resolve(entry.Name)]
+		}
+	}
+
+	return &UpgradePreview{
+		Command:  FormatCommand(args),
+		Packages: entries,
+		Warnings: warnings,
+	}, nil
+}
+
+// greedyOnly reports whether a cask is only upgraded because of the greedy
+// flag: --greedy covers auto-updating and :latest casks,
+// --greedy-auto-updates only the former.
+func (g *DependencyGraph) greedyOnly(name, outdatedFlag string) bool {
+	node := g.find(name)
+	if node == nil || !node.cask {
+		return false
+	}
+	switch outdatedFlag {
+	case OutdatedFlagGreedy:
+		return node.autoUpdates || node.latest
+	case OutdatedFlagGreedyAutoUpdate:
+		return node.autoUpdates
+	default:
+		return false
+	}
+}
@@ -0,0 +1,118 @@
+package brew
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordedUpgradeDryRun is `brew upgrade --dry-run lib` output where the
+// upgrade pulls in a dependency and rebuilds a dependent.
+const recordedUpgradeDryRun = "==> Would upgrade 1 outdated package:\n" +
+	"lib 2.0 -> 2.1\n" +
+	"==> Would upgrade 1 dependency for lib:\n" +
+	"base 3.0 -> 3.1\n" +
+	"==> Would upgrade 2 dependents of upgraded formulae:\n" +
+	"app 1.0 -> 1.0_1, acme/tools/widget 0.3.0 -> 0.3.0_1\n" +
+	"Warning: acme/tools is a third-party tap.\n"
+
+func TestParseUpgradeDryRun(t *testing.T) {
+	entries, warnings := parseUpgradeDryRun(recordedUpgradeDryRun)
+
+	want := []UpgradePreviewEntry{
+		{Name: "lib", FromVersion: "2.0", ToVersion: "2.1"},
+		{Name: "base", FromVersion: "3.0", ToVersion: "3.1", DependencyOnly: true, RequiredBy: "lib"},
+		{Name: "app", FromVersion: "1.0", ToVersion: "1.0_1", DependencyOnly: true},
+		{Name: "acme/tools/widget", FromVersion: "0.3.0", ToVersion: "0.3.0_1", DependencyOnly: true},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("entries:\n got %+v\nwant %+v", entries, want)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "Warning: acme/tools") {
+		t.Fatalf("warnings = %v", warnings)
+	}
+
+	if entries, _ := parseUpgradeDryRun("Nothing to upgrade\n"); len(entries) != 0 {
+		t.Fatalf("informational output parsed as packages: %+v", entries)
+	}
+}
+
+func TestPreviewUpgrade(t *testing.T) {
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("upgrade --dry-run lib widget", brewRecording{stdout: recordedUpgradeDryRun})
+	service, _ := newFakeService(fb)
+
+	preview, err := service.PreviewUpgrade([]string{"lib", "widget"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Command != "brew upgrade --dry-run lib widget" {
+		t.Fatalf("command = %q", preview.Command)
+	}
+
+	dependencyOnly := map[string]bool{}
+	for _, entry := range preview.Packages {
+		if entry.Type != PackageTypeFormula {
+			t.Fatalf("%s typed as %q", entry.Name, entry.Type)
+		}
+		dependencyOnly[entry.Name] = entry.DependencyOnly
+	}
+	want := map[string]bool{"lib": false, "base": true, "app": true, "acme/tools/widget": false}
+	if !reflect.DeepEqual(dependencyOnly, want) {
+		t.Fatalf("dependency-only = %v, want %v", dependencyOnly, want)
+	}
+
+	fb.script("upgrade --dry-run", brewRecording{stderr: "Error: Homebrew is locked.", exitCode: 1})
+	if _, err := service.PreviewUpgrade(nil); err == nil {
+		t.Fatal("expected a failed dry run to return an error")
+	}
+}
+
+func TestPreviewUpgrade_dropsHeldDependencies(t *testing.T) {
+	const outdated = `{"formulae":[` +
+		`{"name":"lib","installed_versions":["2.0"],"current_version":"2.1"},` +
+		`{"name":"base","installed_versions":["3.0"],"current_version":"3.1"}],"casks":[]}`
+	fb := newFakeBrew().
+		script("info --json=v2 --installed", brewRecording{stdout: recordedDependencyInventory}).
+		script("outdated --json=v2", brewRecording{stdout: outdated}).
+		script("upgrade --dry-run lib", brewRecording{stdout: recordedUpgradeDryRun})
+	service, _ := newFakeServiceWithPolicies(fb, map[string]string{"base": UpgradePolicyNever})
+
+	preview, err := service.PreviewUpgrade(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v, calls: %v", err, fb.calls)
+	}
+	for _, entry := range preview.Packages {
+		if entry.Name == "base" {
+			t.Fatalf("held dependency listed in the preview: %+v", preview.Packages)
+		}
+	}
+	if len(preview.Packages) != 3 {
+		t.Fatalf("packages = %+v", preview.Packages)
+	}
+}
+
+func TestPreviewUpgrade_flagsGreedyCasks(t *testing.T) {
+	graph := newRecordedGraph(t)
+
+	tests := []struct {
+		cask         string
+		outdatedFlag string
+		want         bool
+	}{
+		{"browser", OutdatedFlagNone, false},
+		{"browser", OutdatedFlagGreedy, true},
+		{"browser", OutdatedFlagGreedyAutoUpdate, true},
+		{"nightly", OutdatedFlagGreedy, true},
+		{"nightly", OutdatedFlagGreedyAutoUpdate, false},
+		{"tool-app", OutdatedFlagGreedy, false},
+		{"lib", OutdatedFlagGreedy, false},
+	}
+
+	for _, tt := range tests {
+		if got := graph.greedyOnly(tt.cask, tt.outdatedFlag); got != tt.want {
+			t.Errorf("greedyOnly(%q, %q) = %v, want %v", tt.cask, tt.outdatedFlag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package brew
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version is a parsed Homebrew formula or cask version. Formula versions may
+// carry a revision ("1.2.3_1") and cask versions a build after a comma
+// ("1.2,345"); both rank below the version itself.
+type Version struct {
+	raw      string
+	latest   bool
+	tokens   []versionToken
+	revision int
+	build    []versionToken
+}
+
+// VersionChange classifies an upgrade by the most significant part of the
+// version that changes.
+type VersionChange string
+
+const (
+	VersionChangeNone     VersionChange = ""
+	VersionChangeMajor    VersionChange = "major"
+	VersionChangeMinor    VersionChange = "minor"
+	VersionChangePatch    VersionChange = "patch"
+	VersionChangeRevision VersionChange = "revision"
+	VersionChangeRebuild  VersionChange = "rebuild"
+	// VersionChangeUnknown is used when either side is "latest", which has
+	// no order.
+	VersionChangeUnknown VersionChange = "unknown"
+)
+
+// versionToken is a run of digits or letters. Pre-release words rank below a
+// missing token and other words above it, so 1.0rc1 < 1.0 < 1.0a.
+type versionToken struct {
+	text    string
+	number  uint64
+	numeric bool
+	pre     int // rank of a pre-release word, 0 for other words
+}
+
+var preReleaseRanks = map[string]int{"alpha": 1, "beta": 2, "pre": 3, "rc": 4}
+
+// ParseVersion parses a version string as printed by brew. It never fails;
+// anything unrecognised is compared word by word.
+func ParseVersion(s string) Version {
+	s = strings.TrimSpace(s)
+	v := Version{raw: s}
+	if strings.EqualFold(s, "latest") {
+		v.latest = true
+		return v
+	}
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && unicode.IsDigit(rune(s[1])) {
+		s = s[1:]
+	}
+
+	main, build, _ := strings.Cut(s, ",")
+	if i := strings.LastIndex(main, "_"); i > 0 {
+		if revision, err := strconv.Atoi(main[i+1:]); err == nil {
+			v.revision = revision
+			main = main[:i]
+		}
+	}
+	v.tokens = tokenizeVersion(main)
+	v.build = tokenizeVersion(build)
+	return v
+}
+
+func (v Version) String() string {
+	return v.raw
+}
+
+// IsLatest reports whether this is a cask's unversioned "latest".
+func (v Version) IsLatest() bool {
+	return v.latest
+}
+
+// tokenizeVersion splits on separators and on every switch between digits
+// and letters.
+func tokenizeVersion(s string) []versionToken {
+	var tokens []versionToken
+	var current strings.Builder
+	digits := false
+
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		text := current.String()
+		current.Reset()
+		token := versionToken{text: text, numeric: digits}
+		if digits {
+			n, err := strconv.ParseUint(text, 10, 64)
+			if err != nil {
+				n = ^uint64(0)
+			}
+			token.number = n
+		} else {
+			token.text = strings.ToLower(text)
+			token.pre = preReleaseRanks[token.text]
+		}
+		tokens = append(tokens, token)
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			if !digits {
+				flush()
+			}
+			digits = true
+			current.WriteRune(r)
+		case unicode.IsLetter(r):
+			if digits {
+				flush()
+			}
+			digits = false
+			current.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// compareTokens orders two tokens; nil stands for a missing one.
+func compareTokens(a, b *versionToken) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -compareTokens(b, nil)
+	case b == nil:
+		switch {
+		case a.numeric && a.number == 0:
+			return 0
+		case a.numeric:
+			return 1
+		case a.pre > 0:
+			return -1
+		default:
+			return 1
+		}
+	case a.numeric && b.numeric:
+		return compareUint(a.number, b.number)
+	case a.numeric:
+		return 1
+	case b.numeric:
+		return -1
+	case a.pre > 0 || b.pre > 0:
+		if a.pre == 0 {
+			return 1
+		}
+		if b.pre == 0 {
+			return -1
+		}
+		return compareUint(uint64(a.pre), uint64(b.pre))
+	default:
+		return strings.Compare(a.text, b.text)
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareTokenLists compares token by token and returns the result with the
+// index of the first difference, or -1 when equal.
+func compareTokenLists(a, b []versionToken) (int, int) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ta, tb *versionToken
+		if i < len(a) {
+			ta = &a[i]
+		}
+		if i < len(b) {
+			tb = &b[i]
+		}
+		if c := compareTokens(ta, tb); c != 0 {
+			return c, i
+		}
+	}
+	return 0, -1
+}
+
+// Compare returns -1, 0 or 1 as v is older than, equal to or newer than
+// other. "latest" equals itself and ranks above every concrete version.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.latest && other.latest:
+		return 0
+	case v.latest:
+		return 1
+	case other.latest:
+		return -1
+	}
+	if c, _ := compareTokenLists(v.tokens, other.tokens); c != 0 {
+		return c
+	}
+	if c := compareUint(uint64(v.revision), uint64(other.revision)); c != 0 {
+		return c
+	}
+	c, _ := compareTokenLists(v.build, other.build)
+	return c
+}
+
+// CompareVersions parses and compares two version strings, see
+// Version.Compare.
+func CompareVersions(a, b string) int {
+	return ParseVersion(a).Compare(ParseVersion(b))
+}
+
+// ClassifyUpgrade names the most significant part that changes going from
+// one version to another: the first, second or a later component of the
+// version, then the formula revision, then the cask build. It returns
+// VersionChangeNone when to is not newer.
+func ClassifyUpgrade(from, to string) VersionChange {
+	vf, vt := ParseVersion(from), ParseVersion(to)
+	if vf.latest || vt.latest {
+		return VersionChangeUnknown
+	}
+	if vt.Compare(vf) <= 0 {
+		return VersionChangeNone
+	}
+
+	if c, i := compareTokenLists(vt.tokens, vf.tokens); c != 0 {
+		switch i {
+		case 0:
+			return VersionChangeMajor
+		case 1:
+			return VersionChangeMinor
+		default:
+			return VersionChangePatch
+		}
+	}
+	if vt.revision != vf.revision {
+		return VersionChangeRevision
+	}
+	return VersionChangeRebuild
+}
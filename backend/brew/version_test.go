@@ -0,0 +1,60 @@
+package brew
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.0", "1.0.0", 0},
+		{"v2.0.0", "1.9", 1},
+		{"1.2.3_1", "1.2.3", 1},
+		{"1.2.3_2", "1.2.3_10", -1},
+		{"1.2,345", "1.2,344", 1},
+		{"1.2,345", "1.3,100", -1},
+		{"1.1.1w", "1.1.1", 1},
+		{"1.1.1w", "1.1.1v", 1},
+		{"1.1.2", "1.1.1w", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0-beta2", "1.0.0-rc1", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"20240201", "20240101", 1},
+		{"latest", "latest", 0},
+		{"latest", "9.9", 1},
+		{"1.0", "latest", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyUpgrade(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     VersionChange
+	}{
+		{"22.1.0", "23.0.0", VersionChangeMajor},
+		{"3.12.4", "3.13.0", VersionChangeMinor},
+		{"22", "22.1", VersionChangeMinor},
+		{"1.24.4", "1.24.5", VersionChangePatch},
+		{"1.7", "1.7.1", VersionChangePatch},
+		{"1.1.1v", "1.1.1w", VersionChangePatch},
+		{"1.2.3", "1.2.3_1", VersionChangeRevision},
+		{"1.2,345", "1.2,346", VersionChangeRebuild},
+		{"1.2.3", "1.2.3", VersionChangeNone},
+		{"1.2.4", "1.2.3", VersionChangeNone},
+		{"latest", "latest", VersionChangeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyUpgrade(tt.from, tt.to); got != tt.want {
+			t.Errorf("ClassifyUpgrade(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
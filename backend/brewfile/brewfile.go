@@ -0,0 +1,584 @@
+// Package brewfile reads and writes Homebrew Bundle's Brewfile format without
+// running brew.
+//
+// It understands the tap, brew, cask, mas and vscode directives with the
+// options WailBrew acts on; every other option is kept verbatim, and lines
+// it does not understand (comments, cask_args, Ruby blocks, directives built
+// from arbitrary Ruby) are kept as text. Entries guarded by a Ruby condition
+// are marked as conditional. Writing a parsed file back reproduces it byte
+// for byte until entries are changed, which are then written in the
+// canonical one-line form `brew bundle dump` uses.
+package brewfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind is a Brewfile directive.
+type Kind string
+
+// Supported directives, in the order `brew bundle dump` writes them.
+const (
+	Tap    Kind = "tap"
+	Brew   Kind = "brew"
+	Cask   Kind = "cask"
+	Mas    Kind = "mas"
+	VSCode Kind = "vscode"
+)
+
+var kinds = []Kind{Tap, Brew, Cask, Mas, VSCode}
+
+// Option is a keyword option. Value is the Ruby literal as written, e.g.
+// `"~/Applications"`, `true` or `:changed`.
+type Option struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Entry is one directive.
+type Entry struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+	// URL is a tap's custom clone URL.
+	URL string `json:"url,omitempty"`
+	// ID is a Mac App Store app's ID.
+	ID int64 `json:"id,omitempty"`
+	// Args are a formula's install arguments and CaskArgs a cask's, both
+	// from `args:`.
+	Args     []string `json:"args,omitempty"`
+	CaskArgs []Option `json:"caskArgs,omitempty"`
+	// RestartService is "true", "false" or "changed" when set.
+	RestartService string `json:"restartService,omitempty"`
+	// Link is a formula's `link:` option when set.
+	Link    *bool    `json:"link,omitempty"`
+	Options []Option `json:"options,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+	// Condition is a trailing `if`/`unless` modifier as written, e.g.
+	// `if OS.mac?`, and Blocks the headers of the Ruby blocks the entry sits
+	// in, such as `if OS.mac?`, outermost first.
+	Condition string   `json:"condition,omitempty"`
+	Blocks    []string `json:"blocks,omitempty"`
+
+	// raw is the source text and parsed its canonical form at parse time,
+	// so an unchanged entry is written back exactly as it was read.
+	raw    string
+	parsed string
+}
+
+// Key identifies an entry within a file.
+func (e *Entry) Key() string {
+	return string(e.Kind) + " " + e.Name
+}
+
+// Conditional reports whether the entry only applies when a Ruby condition
+// holds.
+func (e *Entry) Conditional() bool {
+	return e.Condition != "" || len(e.Blocks) > 0
+}
+
+// String renders the entry in canonical form, e.g.
+// `brew "postgresql@16", restart_service: :changed`.
+func (e *Entry) String() string {
+	var b strings.Builder
+	b.WriteString(string(e.Kind))
+	b.WriteByte(' ')
+	b.WriteString(Quote(e.Name))
+	if e.URL != "" {
+		b.WriteString(", ")
+		b.WriteString(Quote(e.URL))
+	}
+	if len(e.Args) > 0 {
+		quoted := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			quoted[i] = Quote(arg)
+		}
+		fmt.Fprintf(&b, ", args: [%s]", strings.Join(quoted, ", "))
+	}
+	if len(e.CaskArgs) > 0 {
+		fmt.Fprintf(&b, ", args: { %s }", formatOptions(e.CaskArgs))
+	}
+	if e.ID != 0 {
+		fmt.Fprintf(&b, ", id: %d", e.ID)
+	}
+	switch e.RestartService {
+	case "":
+	case "true", "false":
+		b.WriteString(", restart_service: " + e.RestartService)
+	default:
+		b.WriteString(", restart_service: :" + e.RestartService)
+	}
+	if e.Link != nil {
+		fmt.Fprintf(&b, ", link: %t", *e.Link)
+	}
+	if len(e.Options) > 0 {
+		b.WriteString(", ")
+		b.WriteString(formatOptions(e.Options))
+	}
+	if e.Condition != "" {
+		b.WriteByte(' ')
+		b.WriteString(e.Condition)
+	}
+	if e.Comment != "" {
+		b.WriteString(" # ")
+		b.WriteString(e.Comment)
+	}
+	return b.String()
+}
+
+func formatOptions(options []Option) string {
+	parts := make([]string, len(options))
+	for i, option := range options {
+		parts[i] = option.Key + ": " + option.Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Line is one line of a Brewfile: an entry, or text kept as is. An entry
+// parsed from several physical lines is a single Line.
+type Line struct {
+	Entry *Entry
+	Text  string
+}
+
+// File is a parsed Brewfile.
+type File struct {
+	Lines []Line
+}
+
+// Parse parses a Brewfile. Directives that cannot be parsed, such as ones
+// built from Ruby expressions, are kept as text like any other Ruby.
+func Parse(data []byte) *File {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+
+	file := &File{}
+	var blocks []string
+	for i := 0; i < len(lines); i++ {
+		if !isEntryLine(lines[i]) {
+			blocks = trackBlocks(blocks, lines[i])
+			file.Lines = append(file.Lines, Line{Text: lines[i]})
+			continue
+		}
+
+		start, raw := i, lines[i]
+		entry, err := parseLogicalLine(raw)
+		for err == errIncomplete && i+1 < len(lines) {
+			i++
+			raw += "\n" + lines[i]
+			entry, err = parseLogicalLine(raw)
+		}
+		if err != nil {
+			// Keep the first line only; the ones after it may parse alone.
+			i = start
+			file.Lines = append(file.Lines, Line{Text: lines[i]})
+			continue
+		}
+		entry.raw = raw
+		entry.parsed = entry.String()
+		if len(blocks) > 0 {
+			entry.Blocks = slices.Clone(blocks)
+		}
+		file.Lines = append(file.Lines, Line{Entry: entry})
+	}
+	return file
+}
+
+// errIncomplete marks a directive continued on the next line.
+var errIncomplete = errors.New("incomplete directive")
+
+// parseLogicalLine parses one directive, which may span several physical
+// lines.
+func parseLogicalLine(raw string) (*Entry, error) {
+	tokens, comment, condition, err := lex(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !balanced(tokens) {
+		return nil, errIncomplete
+	}
+	entry, err := parseEntry(tokens)
+	if err != nil {
+		return nil, err
+	}
+	entry.Comment = comment
+	entry.Condition = condition
+	return entry, nil
+}
+
+// trackBlocks updates the headers of the open Ruby blocks for a line kept as
+// text: `if`/`unless` and other block openers push, `elsif`/`else` replace
+// the innermost header and `end` pops it.
+func trackBlocks(blocks []string, line string) []string {
+	line = strings.TrimSpace(line)
+	if i := strings.Index(line, " #"); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return blocks
+	}
+	first, last := fields[0], fields[len(fields)-1]
+	switch {
+	case first == "end" || first == "end)":
+		if len(blocks) > 0 {
+			blocks = blocks[:len(blocks)-1]
+		}
+	case first == "elsif" || first == "else" || first == "when":
+		if len(blocks) > 0 {
+			blocks[len(blocks)-1] = line
+		}
+	case last == "end":
+		// A one-line block opens and closes here.
+	case slices.Contains(blockKeywords, first) || last == "do" || strings.HasSuffix(last, "|") && slices.Contains(fields, "do"):
+		blocks = append(blocks, line)
+	}
+	return blocks
+}
+
+// blockKeywords start a Ruby block that ends with `end`.
+var blockKeywords = []string{"if", "unless", "case", "while", "until", "begin", "def", "class", "module"}
+
+// ReadFile parses the Brewfile at path.
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// isEntryLine reports whether a line starts with a supported directive.
+func isEntryLine(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, kind := range kinds {
+		if rest, ok := strings.CutPrefix(line, string(kind)); ok && rest != "" && (rest[0] == ' ' || rest[0] == '(') {
+			return true
+		}
+	}
+	return false
+}
+
+// Bytes renders the file. Unchanged entries keep their original text.
+func (f *File) Bytes() []byte {
+	var b strings.Builder
+	for _, line := range f.Lines {
+		switch {
+		case line.Entry == nil:
+			b.WriteString(line.Text)
+		case line.Entry.raw != "" && line.Entry.String() == line.Entry.parsed:
+			b.WriteString(line.Entry.raw)
+		default:
+			b.WriteString(line.Entry.String())
+		}
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// WriteFile writes the file to path.
+func (f *File) WriteFile(path string) error {
+	return os.WriteFile(path, f.Bytes(), 0644)
+}
+
+// Entries returns the entries in file order.
+func (f *File) Entries() []*Entry {
+	entries := []*Entry{}
+	for _, line := range f.Lines {
+		if line.Entry != nil {
+			entries = append(entries, line.Entry)
+		}
+	}
+	return entries
+}
+
+// Find returns the entry of the given kind and name, or nil.
+func (f *File) Find(kind Kind, name string) *Entry {
+	for _, entry := range f.Entries() {
+		if entry.Kind == kind && entry.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Add appends an entry, or replaces the existing entry of the same kind and
+// name in place.
+func (f *File) Add(entry Entry) {
+	for i, line := range f.Lines {
+		if line.Entry != nil && line.Entry.Key() == entry.Key() {
+			f.Lines[i].Entry = &entry
+			return
+		}
+	}
+	f.Lines = append(f.Lines, Line{Entry: &entry})
+}
+
+// Remove deletes the entry of the given kind and name and reports whether
+// there was one.
+func (f *File) Remove(kind Kind, name string) bool {
+	for i, line := range f.Lines {
+		if line.Entry != nil && line.Entry.Kind == kind && line.Entry.Name == name {
+			f.Lines = append(f.Lines[:i], f.Lines[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Diff is what changed between two Brewfiles. Changed holds the new version
+// of entries present in both whose options differ.
+type Diff struct {
+	Added   []*Entry `json:"added"`
+	Removed []*Entry `json:"removed"`
+	Changed []*Entry `json:"changed"`
+}
+
+// Compare lists the entries added, removed and changed going from one file
+// to another, in file order.
+func Compare(from, to *File) Diff {
+	diff := Diff{Added: []*Entry{}, Removed: []*Entry{}, Changed: []*Entry{}}
+	before := make(map[string]*Entry)
+	for _, entry := range from.Entries() {
+		before[entry.Key()] = entry
+	}
+	after := make(map[string]bool)
+	for _, entry := range to.Entries() {
+		after[entry.Key()] = true
+		old, ok := before[entry.Key()]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry)
+		case withoutComment(old) != withoutComment(entry):
+			diff.Changed = append(diff.Changed, entry)
+		}
+	}
+	for _, entry := range from.Entries() {
+		if !after[entry.Key()] {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+	return diff
+}
+
+func withoutComment(entry *Entry) string {
+	copied := *entry
+	copied.Comment = ""
+	return copied.String()
+}
+
+// value is a parsed Ruby literal.
+type value struct {
+	raw   string // canonical source form
+	kind  tokenKind
+	text  string   // strings, symbols and words
+	items []value  // arrays
+	pairs []Option // hashes
+	array bool
+	hash  bool
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the punctuation text if it comes next.
+func (p *parser) accept(text string) bool {
+	if tok, ok := p.peek(); ok && tok.kind == tokPunct && tok.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) value() (value, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return value{}, fmt.Errorf("expected a value")
+	}
+	p.pos++
+	switch {
+	case tok.kind == tokString:
+		return value{raw: Quote(tok.text), kind: tokString, text: tok.text}, nil
+	case tok.kind == tokSymbol:
+		return value{raw: ":" + tok.text, kind: tokSymbol, text: tok.text}, nil
+	case tok.kind == tokWord:
+		return value{raw: tok.text, kind: tokWord, text: tok.text}, nil
+	case tok.kind == tokPunct && tok.text == "[":
+		v := value{array: true}
+		raws := []string{}
+		for !p.accept("]") {
+			if len(v.items) > 0 && !p.accept(",") {
+				return value{}, fmt.Errorf("expected , or ]")
+			}
+			if p.accept("]") { // trailing comma
+				break
+			}
+			item, err := p.value()
+			if err != nil {
+				return value{}, err
+			}
+			v.items = append(v.items, item)
+			raws = append(raws, item.raw)
+		}
+		v.raw = "[" + strings.Join(raws, ", ") + "]"
+		return v, nil
+	case tok.kind == tokPunct && tok.text == "{":
+		v := value{hash: true}
+		for !p.accept("}") {
+			if len(v.pairs) > 0 && !p.accept(",") {
+				return value{}, fmt.Errorf("expected , or }")
+			}
+			if p.accept("}") {
+				break
+			}
+			key, err := p.key()
+			if err != nil {
+				return value{}, err
+			}
+			item, err := p.value()
+			if err != nil {
+				return value{}, err
+			}
+			v.pairs = append(v.pairs, Option{Key: key, Value: item.raw})
+		}
+		v.raw = "{ " + formatOptions(v.pairs) + " }"
+		return v, nil
+	}
+	return value{}, fmt.Errorf("unexpected %q", tok.text)
+}
+
+// key reads a keyword in either `key:` or `:key =>` form.
+func (p *parser) key() (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("expected a keyword")
+	}
+	p.pos++
+	if tok.kind == tokLabel {
+		return tok.text, nil
+	}
+	if (tok.kind == tokSymbol || tok.kind == tokString) && p.accept("=>") {
+		return tok.text, nil
+	}
+	return "", fmt.Errorf("unexpected %q", tok.text)
+}
+
+// isKeyword reports whether a keyword argument comes next.
+func (p *parser) isKeyword() bool {
+	tok, ok := p.peek()
+	if !ok {
+		return false
+	}
+	if tok.kind == tokLabel {
+		return true
+	}
+	next := p.pos + 1
+	return (tok.kind == tokSymbol || tok.kind == tokString) && next < len(p.tokens) &&
+		p.tokens[next].kind == tokPunct && p.tokens[next].text == "=>"
+}
+
+// parseEntry parses the tokens of one directive, starting with its kind.
+func parseEntry(tokens []token) (*Entry, error) {
+	entry := &Entry{Kind: Kind(tokens[0].text)}
+	p := &parser{tokens: tokens, pos: 1}
+	parens := p.accept("(")
+
+	name, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if name.kind != tokString || name.array || name.hash {
+		return nil, fmt.Errorf("%s needs a quoted name", entry.Kind)
+	}
+	entry.Name = name.text
+
+	for p.accept(",") {
+		if !p.isKeyword() {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			if entry.Kind != Tap || entry.URL != "" || v.kind != tokString || v.array || v.hash {
+				return nil, fmt.Errorf("unexpected argument %s", v.raw)
+			}
+			entry.URL = v.text
+			continue
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if err := entry.apply(key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if parens && !p.accept(")") {
+		return nil, fmt.Errorf("expected )")
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+	return entry, nil
+}
+
+// apply stores a keyword option in its typed field, or in Options.
+func (e *Entry) apply(key string, v value) error {
+	switch {
+	case key == "args" && e.Kind == Brew:
+		if !v.array {
+			return fmt.Errorf("args must be an array")
+		}
+		for _, item := range v.items {
+			if item.kind != tokString || item.array || item.hash {
+				return fmt.Errorf("args must be strings")
+			}
+			e.Args = append(e.Args, item.text)
+		}
+	case key == "args" && e.Kind == Cask:
+		if !v.hash {
+			return fmt.Errorf("cask args must be a hash")
+		}
+		e.CaskArgs = v.pairs
+	case key == "restart_service" && e.Kind == Brew:
+		if v.kind == tokWord && v.text != "true" && v.text != "false" || v.array || v.hash {
+			return fmt.Errorf("invalid restart_service %s", v.raw)
+		}
+		e.RestartService = v.text
+	case key == "link" && e.Kind == Brew:
+		if v.kind != tokWord || v.text != "true" && v.text != "false" {
+			return fmt.Errorf("invalid link %s", v.raw)
+		}
+		link := v.text == "true"
+		e.Link = &link
+	case key == "id" && e.Kind == Mas:
+		id, err := strconv.ParseInt(v.text, 10, 64)
+		if err != nil || v.kind != tokWord {
+			return fmt.Errorf("invalid id %s", v.raw)
+		}
+		e.ID = id
+	default:
+		e.Options = append(e.Options, Option{Key: key, Value: v.raw})
+	}
+	return nil
+}
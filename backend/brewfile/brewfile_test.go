@@ -0,0 +1,206 @@
+package brewfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordedBrewfile covers every supported directive and option, with the
+// comments, blank lines and Ruby that must survive a round trip untouched.
+const recordedBrewfile = `# Work laptop
+tap "acme/tools"
+tap "acme/private", "https://git.example.com/acme/homebrew-private.git"
+cask_args appdir: "~/Applications"
+
+brew "wget"
+brew "postgresql@16", restart_service: :changed
+brew "openssl@3", link: false # keg-only anyway
+brew "acme/tools/widget", args: ["with-extras", "HEAD"]
+brew("jq")
+brew "ffmpeg",
+  args: ["with-fdk-aac"],
+  restart_service: true
+if OS.mac?
+  cask "firefox", args: { appdir: "~/Applications", require_sha: true }
+  cask "iterm2", greedy: true
+end
+mas "Xcode", id: 497799835
+vscode "golang.go"
+`
+
+func TestParse(t *testing.T) {
+	file := Parse([]byte(recordedBrewfile))
+
+	no := false
+	want := []Entry{
+		{Kind: Tap, Name: "acme/tools"},
+		{Kind: Tap, Name: "acme/private", URL: "https://git.example.com/acme/homebrew-private.git"},
+		{Kind: Brew, Name: "wget"},
+		{Kind: Brew, Name: "postgresql@16", RestartService: "changed"},
+		{Kind: Brew, Name: "openssl@3", Link: &no, Comment: "keg-only anyway"},
+		{Kind: Brew, Name: "acme/tools/widget", Args: []string{"with-extras", "HEAD"}},
+		{Kind: Brew, Name: "jq"},
+		{Kind: Brew, Name: "ffmpeg", Args: []string{"with-fdk-aac"}, RestartService: "true"},
+		{Kind: Cask, Name: "firefox", CaskArgs: []Option{{"appdir", `"~/Applications"`}, {"require_sha", "true"}}, Blocks: []string{"if OS.mac?"}},
+		{Kind: Cask, Name: "iterm2", Options: []Option{{"greedy", "true"}}, Blocks: []string{"if OS.mac?"}},
+		{Kind: Mas, Name: "Xcode", ID: 497799835},
+		{Kind: VSCode, Name: "golang.go"},
+	}
+
+	entries := file.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		got := *entry
+		got.raw, got.parsed = "", ""
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("entry %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	file := Parse([]byte(recordedBrewfile))
+	if got := string(file.Bytes()); got != recordedBrewfile {
+		t.Fatalf("round trip changed the file:\n%s", got)
+	}
+
+	// Edited entries are written in canonical form; the rest stays as is.
+	file.Find(Brew, "ffmpeg").Args = nil
+	file.Find(Brew, "jq").Comment = "json"
+	got := string(file.Bytes())
+	if !strings.Contains(got, "brew \"ffmpeg\", restart_service: true\nif OS.mac?") ||
+		!strings.Contains(got, "\nbrew \"jq\" # json\n") ||
+		!strings.Contains(got, "brew \"wget\"\nbrew \"postgresql@16\", restart_service: :changed\n") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	reparsed := Parse([]byte(got))
+	if diff := Compare(file, reparsed); len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Fatalf("canonical output differs: %+v", diff)
+	}
+}
+
+func TestEntryString(t *testing.T) {
+	yes := true
+	tests := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{Kind: Tap, Name: "acme/tools", URL: "https://example.com/t.git"}, `tap "acme/tools", "https://example.com/t.git"`},
+		{Entry{Kind: Brew, Name: "mysql", RestartService: "changed", Link: &yes}, `brew "mysql", restart_service: :changed, link: true`},
+		{Entry{Kind: Cask, Name: "zed", CaskArgs: []Option{{"no_quarantine", "true"}}}, `cask "zed", args: { no_quarantine: true }`},
+		{Entry{Kind: Mas, Name: `Say "Hi"`, ID: 42}, `mas "Say \"Hi\"", id: 42`},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestParse_conditionalEntries(t *testing.T) {
+	const input = `brew "gnu-sed" if OS.linux?
+brew "terraform" unless ENV['CI'] # local only
+brew "coreutils", args: %w[with-default-names HEAD] if OS.mac?
+if OS.mac?
+  unless ENV["CI"] == "true"
+    cask "slack"
+  end
+  cask "iterm2"
+else
+  brew "xclip"
+end
+brew "wget"
+`
+	file := Parse([]byte(input))
+
+	want := []Entry{
+		{Kind: Brew, Name: "gnu-sed", Condition: "if OS.linux?"},
+		{Kind: Brew, Name: "terraform", Condition: "unless ENV['CI']", Comment: "local only"},
+		{Kind: Brew, Name: "coreutils", Args: []string{"with-default-names", "HEAD"}, Condition: "if OS.mac?"},
+		{Kind: Cask, Name: "slack", Blocks: []string{"if OS.mac?", `unless ENV["CI"] == "true"`}},
+		{Kind: Cask, Name: "iterm2", Blocks: []string{"if OS.mac?"}},
+		{Kind: Brew, Name: "xclip", Blocks: []string{"else"}},
+		{Kind: Brew, Name: "wget"},
+	}
+	entries := file.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		got := *entry
+		got.raw, got.parsed = "", ""
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("entry %d = %+v, want %+v", i, got, want[i])
+		}
+		if got.Conditional() != (i < len(want)-1) {
+			t.Errorf("%s conditional = %v", got.Name, got.Conditional())
+		}
+	}
+
+	if got := string(file.Bytes()); got != input {
+		t.Fatalf("round trip changed the file:\n%s", got)
+	}
+	file.Find(Brew, "terraform").Args = []string{"HEAD"}
+	if got := string(file.Bytes()); !strings.Contains(got, "brew \"terraform\", args: [\"HEAD\"] unless ENV['CI'] # local only\n") {
+		t.Fatalf("edited entry lost its condition:\n%s", got)
+	}
+}
+
+func TestParse_keepsUnparseableLines(t *testing.T) {
+	const input = `tap "a/b"
+brew "x", args: "y"
+brew wget
+mas "Xcode", id: :xcode
+cask "x", args: ["y"]
+brew "unterminated
+brew "x", link: maybe
+brew "ffmpeg", args: [
+brew "jq"
+`
+	file := Parse([]byte(input))
+
+	var keys []string
+	for _, entry := range file.Entries() {
+		keys = append(keys, entry.Key())
+	}
+	if want := []string{"tap a/b", "brew jq"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("entries = %v, want %v", keys, want)
+	}
+	if got := string(file.Bytes()); got != input {
+		t.Fatalf("round trip changed the file:\n%s", got)
+	}
+}
+
+func TestFileEditing(t *testing.T) {
+	file := Parse([]byte("tap \"acme/tools\"\nbrew \"wget\"\nbrew \"jq\"\n"))
+	original := Parse(file.Bytes())
+
+	file.Add(Entry{Kind: Brew, Name: "git"})
+	file.Add(Entry{Kind: Brew, Name: "wget", Args: []string{"HEAD"}})
+	if !file.Remove(Brew, "jq") || file.Remove(Brew, "jq") {
+		t.Fatal("Remove should succeed exactly once")
+	}
+
+	want := "tap \"acme/tools\"\nbrew \"wget\", args: [\"HEAD\"]\nbrew \"git\"\n"
+	if got := string(file.Bytes()); got != want {
+		t.Fatalf("Bytes() = %q, want %q", got, want)
+	}
+
+	diff := Compare(original, file)
+	keys := func(entries []*Entry) []string {
+		out := []string{}
+		for _, entry := range entries {
+			out = append(out, entry.Key())
+		}
+		return out
+	}
+	if !reflect.DeepEqual(keys(diff.Added), []string{"brew git"}) ||
+		!reflect.DeepEqual(keys(diff.Removed), []string{"brew jq"}) ||
+		!reflect.DeepEqual(keys(diff.Changed), []string{"brew wget"}) {
+		t.Fatalf("unexpected diff: added %v, removed %v, changed %v", keys(diff.Added), keys(diff.Removed), keys(diff.Changed))
+	}
+}
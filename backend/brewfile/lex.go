@@ -0,0 +1,239 @@
+package brewfile
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // identifiers, numbers, true/false/nil
+	tokLabel                   // "args:" in keyword arguments
+	tokString                  // text holds the unquoted value
+	tokSymbol                  // text holds the name without the colon
+	tokPunct                   // , ( ) [ ] { } =>
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lex splits one logical Brewfile line, which may span several physical
+// lines, into tokens. Comments are returned separately without their "#",
+// and so is a trailing `if`/`unless` modifier, which is kept as written.
+func lex(line string) (tokens []token, comment, condition string, err error) {
+	var comments []string
+	runes := []rune(line)
+	depth := 0
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case depth == 0 && len(tokens) > 0 && isModifier(runes[i:]):
+			condition, trailing := lexCondition(runes[i:])
+			if trailing != "" {
+				comments = append(comments, trailing)
+			}
+			return tokens, strings.Join(comments, " "), condition, nil
+		case r == '%' && i+2 < len(runes) && strings.ContainsRune("wWiI", runes[i+1]):
+			words, n, err := lexWordArray(runes[i:])
+			if err != nil {
+				return nil, "", "", err
+			}
+			tokens = append(tokens, words...)
+			i += n
+		case r == '#':
+			j := i
+			for j < len(runes) && runes[j] != '\n' {
+				j++
+			}
+			comments = append(comments, strings.TrimSpace(string(runes[i+1:j])))
+			i = j
+		case r == '"' || r == '\'':
+			text, n, err := lexString(runes[i:])
+			if err != nil {
+				return nil, "", "", err
+			}
+			tokens = append(tokens, token{tokString, text})
+			i += n
+		case r == '=' && i+1 < len(runes) && runes[i+1] == '>':
+			tokens = append(tokens, token{tokPunct, "=>"})
+			i += 2
+		case strings.ContainsRune(",()[]{}", r):
+			if strings.ContainsRune("([{", r) {
+				depth++
+			} else if strings.ContainsRune(")]}", r) {
+				depth--
+			}
+			tokens = append(tokens, token{tokPunct, string(r)})
+			i++
+		case r == ':' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\''):
+			text, n, err := lexString(runes[i+1:])
+			if err != nil {
+				return nil, "", "", err
+			}
+			tokens = append(tokens, token{tokSymbol, text})
+			i += n + 1
+		case r == ':' && i+1 < len(runes) && isWordRune(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokSymbol, string(runes[i+1 : j])})
+			i = j
+		case isWordRune(r) || r == '-':
+			j := i + 1
+			for j < len(runes) && (isWordRune(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			// "key:" is a label unless it starts a "::" constant path.
+			if j < len(runes) && runes[j] == ':' && (j+1 == len(runes) || runes[j+1] != ':') {
+				tokens = append(tokens, token{tokLabel, string(runes[i:j])})
+				i = j + 1
+				continue
+			}
+			tokens = append(tokens, token{tokWord, string(runes[i:j])})
+			i = j
+		default:
+			return nil, "", "", fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, strings.Join(comments, " "), "", nil
+}
+
+// isModifier reports whether runes start with an `if` or `unless` keyword.
+func isModifier(runes []rune) bool {
+	for _, keyword := range []string{"if", "unless"} {
+		n := len(keyword)
+		if len(runes) > n && string(runes[:n]) == keyword && unicode.IsSpace(runes[n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// lexCondition splits a trailing modifier into the condition, which may be
+// any Ruby, and a comment after it.
+func lexCondition(runes []rune) (string, string) {
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '"' || r == '\'':
+			if _, n, err := lexString(runes[i:]); err == nil {
+				i += n - 1
+			}
+		case r == '#':
+			return strings.TrimSpace(string(runes[:i])), strings.TrimSpace(string(runes[i+1:]))
+		}
+	}
+	return strings.TrimSpace(string(runes)), ""
+}
+
+// lexWordArray reads a %w[] or %i[] literal starting at runes[0] as the
+// tokens of the equivalent array of strings or symbols, and returns its
+// length in runes.
+func lexWordArray(runes []rune) ([]token, int, error) {
+	kind := tokString
+	if runes[1] == 'i' || runes[1] == 'I' {
+		kind = tokSymbol
+	}
+	closing, ok := map[rune]rune{'[': ']', '(': ')', '{': '}', '<': '>'}[runes[2]]
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected %q", string(runes[:3]))
+	}
+	for i := 3; i < len(runes); i++ {
+		if runes[i] != closing {
+			continue
+		}
+		tokens := []token{{tokPunct, "["}}
+		for j, word := range strings.Fields(string(runes[3:i])) {
+			if j > 0 {
+				tokens = append(tokens, token{tokPunct, ","})
+			}
+			tokens = append(tokens, token{kind, word})
+		}
+		return append(tokens, token{tokPunct, "]"}), i + 1, nil
+	}
+	return nil, 0, fmt.Errorf("unterminated %s", string(runes[:2]))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '?' || r == '!' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lexString reads a quoted Ruby string starting at runes[0] and returns its
+// value and length in runes. Interpolation is kept literally.
+func lexString(runes []rune) (string, int, error) {
+	quote := runes[0]
+	var b strings.Builder
+	for i := 1; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == quote:
+			return b.String(), i + 1, nil
+		case r == '\\' && i+1 < len(runes):
+			i++
+			next := runes[i]
+			switch {
+			case next == quote || next == '\\':
+				b.WriteRune(next)
+			case quote == '"' && next == 'n':
+				b.WriteRune('\n')
+			case quote == '"' && next == 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(next)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// Quote returns s as a double-quoted Ruby string literal.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// balanced reports whether a logical line is complete: every bracket is
+// closed and it does not end in a comma awaiting more arguments.
+func balanced(tokens []token) bool {
+	depth := 0
+	for _, tok := range tokens {
+		if tok.kind != tokPunct {
+			continue
+		}
+		switch tok.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+	}
+	if depth > 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind != tokPunct || last.text != ","
+}
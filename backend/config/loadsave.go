@@ -4,8 +4,26 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 )
 
+// DefaultOutdatedCheckInterval is the background outdated check interval in
+// minutes used until the user picks another one. It keeps the 15-minute
+// cadence the frontend timer used before the check moved to the backend.
+const DefaultOutdatedCheckInterval = 15
+
+// AutoUpgrade configures unattended upgrades (see brew.AutoUpgrader).
+type AutoUpgrade struct {
+	Enabled     bool     `json:"enabled"`
+	Days        []string `json:"days,omitempty"`        // "mon".."sun", "weekdays" or "weekend"; empty means every day
+	WindowStart string   `json:"windowStart"`           // Local "HH:MM" the window opens
+	WindowEnd   string   `json:"windowEnd"`             // Local "HH:MM" the window closes; before WindowStart wraps past midnight
+	Allow       []string `json:"allow,omitempty"`       // Only upgrade these packages when set
+	Deny        []string `json:"deny,omitempty"`        // Never upgrade these packages
+	MaxPackages int      `json:"maxPackages,omitempty"` // Packages upgraded per run, 0 for no limit
+}
+
 // Config holds application configuration
 type Config struct {
 	BrewPath           string `json:"brewPath"` // Homebrew binary path (e.g., "/opt/homebrew/bin/brew")
@@ -27,9 +45,17 @@ type Config struct {
 
 	UninstallCaskWithZap bool `json:"uninstallCaskWithZap"` // Pass --zap when uninstalling a cask, removing leftover preferences/caches
 
+	OutdatedCheckInterval int `json:"outdatedCheckInterval"` // Minutes between background `brew update` + outdated checks; 0 disables them
+
 	Favorites          []string `json:"favorites,omitempty"`          // Names of formulae/casks marked as favorites
 	SortFavoritesToTop bool     `json:"sortFavoritesToTop,omitempty"` // Pin favorited packages to the top of package tables
 
+	AutoUpgrade AutoUpgrade `json:"autoUpgrade"` // Unattended upgrades in a maintenance window
+
+	// Per-package limits on what upgrade-all may install: "never", "patch",
+	// "minor" or "skip:<version>" (see brew.PolicyAllows).
+	UpgradePolicies map[string]string `json:"upgradePolicies,omitempty"`
+
 	// Window geometry — persisted across launches so the window opens where it
 	// was last left. Zero values mean "not yet captured" and the app falls back
 	// to its built-in defaults.
@@ -40,6 +66,99 @@ type Config struct {
 	WindowMaximized bool `json:"windowMaximized,omitempty"`
 
 	resolvedPath string // internal: remembers which file was loaded so Save() writes back to the same location
+
+	// mu guards the fields read by background goroutines (see the accessors
+	// below) and the whole struct while it is loaded or saved.
+	mu sync.RWMutex
+}
+
+// AutoUpgradeSettings returns a copy of the unattended upgrade settings.
+func (c *Config) AutoUpgradeSettings() AutoUpgrade {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	settings := c.AutoUpgrade
+	settings.Days = slices.Clone(settings.Days)
+	settings.Allow = slices.Clone(settings.Allow)
+	settings.Deny = slices.Clone(settings.Deny)
+	return settings
+}
+
+// SetAutoUpgrade replaces the unattended upgrade settings.
+func (c *Config) SetAutoUpgrade(settings AutoUpgrade) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AutoUpgrade = settings
+}
+
+// UpgradePolicySnapshot returns the configured upgrade policies. The map is
+// replaced rather than modified on change, so it can be kept and read from
+// any goroutine.
+func (c *Config) UpgradePolicySnapshot() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.UpgradePolicies
+}
+
+// SetUpgradePolicy sets the upgrade policy of a package, or removes it when
+// policy is empty.
+func (c *Config) SetUpgradePolicy(name, policy string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	policies := make(map[string]string, len(c.UpgradePolicies)+1)
+	for pkg, p := range c.UpgradePolicies {
+		policies[pkg] = p
+	}
+	if policy == "" {
+		delete(policies, name)
+	} else {
+		policies[name] = policy
+	}
+	c.UpgradePolicies = policies
+}
+
+// OutdatedCheckMinutes returns the minutes between background outdated
+// checks, 0 when they are disabled.
+func (c *Config) OutdatedCheckMinutes() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.OutdatedCheckInterval
+}
+
+// SetOutdatedCheckMinutes changes the minutes between background outdated
+// checks.
+func (c *Config) SetOutdatedCheckMinutes(minutes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OutdatedCheckInterval = minutes
+}
+
+// CleanupAfterUpgrade reports whether `brew cleanup` runs after upgrades.
+func (c *Config) CleanupAfterUpgrade() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AutoCleanupAfterUpgrade
+}
+
+// SetCleanupAfterUpgrade changes whether `brew cleanup` runs after upgrades.
+func (c *Config) SetCleanupAfterUpgrade(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AutoCleanupAfterUpgrade = enabled
+}
+
+// OutdatedMode returns the configured outdated detection flag, empty when
+// none has been chosen.
+func (c *Config) OutdatedMode() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.OutdatedFlag
+}
+
+// SetOutdatedMode changes the outdated detection flag.
+func (c *Config) SetOutdatedMode(flag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OutdatedFlag = flag
 }
 
 // GetConfigPath resolves the config file path using a cascading lookup:
@@ -97,6 +216,9 @@ func (c *Config) Load() error {
 	// config file at all) keeps the intended default, while an explicit value in
 	// the file still wins.
 	c.AutoRelaunch = true // relaunch a running app after an upgrade by default
+	c.OutdatedCheckInterval = DefaultOutdatedCheckInterval
+	c.AutoUpgrade.Days = []string{"weekdays"}
+	c.AutoUpgrade.WindowStart, c.AutoUpgrade.WindowEnd = "12:00", "13:00"
 
 	configPath, err := GetConfigPath()
 	if err != nil {
@@ -112,6 +234,8 @@ func (c *Config) Load() error {
 		return err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return json.Unmarshal(data, c)
 }
 
@@ -133,7 +257,9 @@ func (c *Config) Save() error {
 		return err
 	}
 
+	c.mu.RLock()
 	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.RUnlock()
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,8 @@ type AppInterface interface {
 	OpenURL(url string)
 	GetTranslation(key string, params map[string]string) string
 	ExportBrewfile(filePath string) error
+	ExportFilteredBrewfile(filePath string, leavesOnly bool, favoritesOnly bool) error
+	ExportDependencyGraph(filePath string, format string, packageNames []string, includeBuild bool) error
 	OpenConfigFile() error
 }
 
@@ -112,17 +114,19 @@ func Build(app AppInterface) *menu.Menu {
 		rt.EventsEmit(getCtx(), "refreshPackagesData")
 	})
 	ToolsMenu.AddSeparator()
-	ToolsMenu.AddText(getT("menu.tools.exportBrewfile"), keys.CmdOrCtrl("e"), func(cd *menu.CallbackData) {
+	// exportBrewfile asks where to save a Brewfile, writes it with export and
+	// reports the outcome.
+	exportBrewfile := func(title string, export func(path string) error) {
 		ctx := getCtx()
 		// Open file picker dialog to save Brewfile
 		saveDialog, err := rt.SaveFileDialog(ctx, rt.SaveDialogOptions{
 			DefaultFilename:      "Brewfile",
-			Title:                getT("menu.tools.exportBrewfile"),
+			Title:                title,
 			CanCreateDirectories: true,
 		})
 
 		if err == nil && saveDialog != "" {
-			err := app.ExportBrewfile(saveDialog)
+			err := export(saveDialog)
 			if err != nil {
 				_, _ = rt.MessageDialog(ctx, rt.MessageDialogOptions{
 					Type:    rt.ErrorDialog,
@@ -137,7 +141,87 @@ func Build(app AppInterface) *menu.Menu {
 				})
 			}
 		}
+	}
+	ToolsMenu.AddText(getT("menu.tools.exportBrewfile"), keys.CmdOrCtrl("e"), func(cd *menu.CallbackData) {
+		exportBrewfile(getT("menu.tools.exportBrewfile"), app.ExportBrewfile)
+	})
+	FilteredMenu := ToolsMenu.AddSubmenu(getT("menu.tools.exportFilteredBrewfile"))
+	FilteredMenu.AddText(getT("menu.tools.exportLeavesBrewfile"), nil, func(cd *menu.CallbackData) {
+		exportBrewfile(getT("menu.tools.exportLeavesBrewfile"), func(path string) error {
+			return app.ExportFilteredBrewfile(path, true, false)
+		})
+	})
+	FilteredMenu.AddText(getT("menu.tools.exportFavoritesBrewfile"), nil, func(cd *menu.CallbackData) {
+		exportBrewfile(getT("menu.tools.exportFavoritesBrewfile"), func(path string) error {
+			return app.ExportFilteredBrewfile(path, false, true)
+		})
+	})
+	ToolsMenu.AddText(getT("menu.tools.applyBrewfile"), keys.Combo("e", keys.CmdOrCtrlKey, keys.ShiftKey), func(cd *menu.CallbackData) {
+		ctx := getCtx()
+		// The frontend previews the Brewfile and asks before applying it
+		openDialog, err := rt.OpenFileDialog(ctx, rt.OpenDialogOptions{
+			Title: getT("menu.tools.applyBrewfile"),
+		})
+		if err == nil && openDialog != "" {
+			rt.EventsEmit(ctx, "showBrewfilePreview", openDialog)
+		}
+	})
+	ToolsMenu.AddText(getT("menu.tools.cleanupBrewfile"), nil, func(cd *menu.CallbackData) {
+		ctx := getCtx()
+		// The frontend lists what would be removed and lets the user keep some
+		openDialog, err := rt.OpenFileDialog(ctx, rt.OpenDialogOptions{
+			Title: getT("menu.tools.cleanupBrewfile"),
+		})
+		if err == nil && openDialog != "" {
+			rt.EventsEmit(ctx, "showBrewfileCleanup", openDialog)
+		}
+	})
+	ToolsMenu.AddText(getT("menu.tools.snapshots"), nil, func(cd *menu.CallbackData) {
+		rt.EventsEmit(getCtx(), "showSnapshots")
+	})
+	ToolsMenu.AddText(getT("menu.tools.operationHistory"), nil, func(cd *menu.CallbackData) {
+		rt.EventsEmit(getCtx(), "showOperationHistory")
+	})
+	ToolsMenu.AddText(getT("menu.tools.undoLastOperation"), nil, func(cd *menu.CallbackData) {
+		rt.EventsEmit(getCtx(), "undoLastOperation")
 	})
+	ToolsMenu.AddText(getT("menu.tools.formulaVersions"), nil, func(cd *menu.CallbackData) {
+		rt.EventsEmit(getCtx(), "showFormulaVersions")
+	})
+	GraphMenu := ToolsMenu.AddSubmenu(getT("menu.tools.exportGraph"))
+	includeBuild := GraphMenu.AddCheckbox(getT("menu.tools.graphIncludeBuild"), false, nil, nil)
+	GraphMenu.AddSeparator()
+	for _, export := range []struct{ label, format, filename string }{
+		{"menu.tools.graphJSON", "json", "dependencies.json"},
+		{"menu.tools.graphDOT", "dot", "dependencies.dot"},
+		{"menu.tools.graphMermaid", "mermaid", "dependencies.mmd"},
+	} {
+		GraphMenu.AddText(getT(export.label), nil, func(cd *menu.CallbackData) {
+			ctx := getCtx()
+			saveDialog, err := rt.SaveFileDialog(ctx, rt.SaveDialogOptions{
+				DefaultFilename:      export.filename,
+				Title:                getT("menu.tools.exportGraph"),
+				CanCreateDirectories: true,
+			})
+
+			if err == nil && saveDialog != "" {
+				err := app.ExportDependencyGraph(saveDialog, export.format, nil, includeBuild.Checked)
+				if err != nil {
+					_, _ = rt.MessageDialog(ctx, rt.MessageDialogOptions{
+						Type:    rt.ErrorDialog,
+						Title:   getT("menu.tools.exportFailed"),
+						Message: fmt.Sprintf("Failed to export dependency graph: %v", err),
+					})
+				} else {
+					_, _ = rt.MessageDialog(ctx, rt.MessageDialogOptions{
+						Type:    rt.InfoDialog,
+						Title:   getT("menu.tools.exportSuccess"),
+						Message: fmt.Sprintf(getT("menu.tools.graphExportMessage"), saveDialog),
+					})
+				}
+			}
+		})
+	}
 	ToolsMenu.AddText(getT("menu.tools.openConfigFile"), nil, func(cd *menu.CallbackData) {
 		ctx := getCtx()
 		err := app.OpenConfigFile()
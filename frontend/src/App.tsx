@@ -1,8 +1,10 @@
-import { CheckSquare, Copy, PartyPopper, RefreshCw, Sparkles, Star, X } from "lucide-react";
+import { AlertTriangle, CheckSquare, Copy, PartyPopper, RefreshCw, Sparkles, Star, X } from "lucide-react";
 import { useEffect, useRef, useState } from "react";
 import toast, { Toaster } from "react-hot-toast";
 import { useTranslation } from "react-i18next";
 import {
+    AcknowledgeAutoUpgradeFailures,
+    ApplyBrewfile,
     CheckBrewLocation,
     CheckHomebrewUpdate,
     ClearBrewCache,
@@ -10,6 +12,7 @@ import {
     GetAllBrewPackages,
     GetAppVersion,
     GetAutoCleanupAfterUpgrade,
+    GetAutoUpgradeFailures,
     GetBrewCaskSizes,
     GetBrewCasks,
     GetBrewCleanupDryRun,
@@ -22,22 +25,32 @@ import {
     GetBrewServices,
     GetBrewTapInfo,
     GetBrewUpdatablePackages,
-    GetBrewUpdatablePackagesWithUpdate,
+    GetBrewfileCleanup,
     GetDeprecatedFormulae,
     GetFavorites,
     GetHomebrewVersion,
     GetInstalledDependents,
     GetLandingTab,
+    GetLastUndoableOperation,
+    GetNextOutdatedCheck,
     GetSessionLogs,
     GetSortFavoritesToTop,
     GetStartupDataWithUpdate,
     GetUninstallCaskWithZap,
     InstallBrewPackage,
+    InstallBrewPackageVersion,
+    LinkBrewPackage,
+    PinBrewPackage,
+    PreviewBrewfile,
+    PreviewLink,
+    ReinstallBrewPackage,
     RemoveBrewPackage,
     RestartBrewService,
+    RestoreSnapshot,
     RunBrewCleanup,
     RunBrewCleanupDryRun,
     RunBrewDoctor,
+    RunBrewfileCleanup,
     RunBrewService,
     SaveWindowGeometry,
     SetBrewPath,
@@ -47,26 +60,36 @@ import {
     SetUninstallCaskWithZap,
     StartBrewService,
     StopBrewService,
+    SwitchFormulaVersion,
     TapBrewRepository,
     ToggleFavorite,
     TrustBrewTap,
+    UndoLastOperation,
+    UnlinkBrewPackage,
+    UnpinBrewPackage,
     UntapBrewRepository,
     UpdateAllBrewPackages,
     UpdateBrewPackage,
     UpdateHomebrew,
     UpdateSelectedBrewPackages,
 } from "../wailsjs/go/main/App";
+import type { brew } from "../wailsjs/go/models";
 import { EventsOn, WindowGetPosition, WindowGetSize, WindowIsMaximised } from "../wailsjs/runtime";
 import "./App.css";
 import "./style.css";
 
 import AboutDialog from "./components/AboutDialog";
+import BrewfileCleanupDialog from "./components/BrewfileCleanupDialog";
+import BrewfilePreviewDialog from "./components/BrewfilePreviewDialog";
 import CleanupView from "./components/CleanupView";
 import ConfirmDialog from "./components/ConfirmDialog";
 import DoctorView from "./components/DoctorView";
+import FormulaVersionsDialog from "./components/FormulaVersionsDialog";
 import HeaderRow from "./components/HeaderRow";
 import HomebrewView from "./components/HomebrewView";
+import InstallVersionDialog from "./components/InstallVersionDialog";
 import { LoadingTimer } from "./components/LoadingTimer";
+import LinkDialog from "./components/LinkDialog";
 import LogDialog from "./components/LogDialog";
 import PackageInfo from "./components/PackageInfo";
 import PackageInfoDialog from "./components/PackageInfoDialog";
@@ -79,11 +102,27 @@ import ServicesTable, { type ServiceEntry } from "./components/ServicesTable";
 import SettingsView from "./components/SettingsView";
 import ShortcutsDialog from "./components/ShortcutsDialog";
 import Sidebar from "./components/Sidebar";
+import OperationHistoryDialog from "./components/OperationHistoryDialog";
+import SnapshotsDialog from "./components/SnapshotsDialog";
 import TapInputDialog from "./components/TapInputDialog";
 import TitleBar from "./components/TitleBar";
 import UpdateDialog from "./components/UpdateDialog";
 import { mapToSupportedLanguage } from "./i18n/languageUtils";
-import type { PackageEntry, RepositoryEntry, View } from "./types";
+import type { AutoUpgradeRun, OutdatedDiff, OutdatedPackage, PackageEntry, RepositoryEntry, View } from "./types";
+
+const outdatedToEntry = (pkg: OutdatedPackage): PackageEntry => ({
+    name: pkg.name,
+    installedVersion: pkg.installedVersion,
+    latestVersion: pkg.currentVersion,
+    size: pkg.size,
+    isInstalled: true,
+    warning: pkg.warning || undefined,
+    isCask: pkg.type === "cask",
+    pinned: pkg.pinned,
+    upgradePolicy: pkg.policy || undefined,
+    policyHeld: !pkg.policyAllowed,
+    versionChange: pkg.change || undefined,
+});
 
 const WailBrewApp = () => {
     const { t, i18n } = useTranslation();
@@ -136,6 +175,29 @@ const WailBrewApp = () => {
     const [untapLogs, setUntapLogs] = useState<string | null>(null);
     const [tapLogs, setTapLogs] = useState<string | null>(null);
     const [tappingRepository, setTappingRepository] = useState<string | null>(null);
+    const [brewfilePreview, setBrewfilePreview] = useState<brew.BrewfilePreview | null>(null);
+    const [brewfileLogs, setBrewfileLogs] = useState<string | null>(null);
+    const [isBrewfileRunning, setIsBrewfileRunning] = useState<boolean>(false);
+    const [brewfileCleanup, setBrewfileCleanup] = useState<{ path: string; items: brew.BrewfileCleanupItem[] } | null>(
+        null,
+    );
+    const [brewfileCleanupLogs, setBrewfileCleanupLogs] = useState<string | null>(null);
+    const [isBrewfileCleanupRunning, setIsBrewfileCleanupRunning] = useState<boolean>(false);
+    const [showSnapshots, setShowSnapshots] = useState<boolean>(false);
+    const [showOperationHistory, setShowOperationHistory] = useState<boolean>(false);
+    const [showFormulaVersions, setShowFormulaVersions] = useState<boolean>(false);
+    const [switchCandidate, setSwitchCandidate] = useState<{ family: string; target: string } | null>(null);
+    const [undoCandidate, setUndoCandidate] = useState<brew.JournalEntry | null>(null);
+    const [undoLogs, setUndoLogs] = useState<string | null>(null);
+    const [isUndoRunning, setIsUndoRunning] = useState<boolean>(false);
+    const [installVersionFor, setInstallVersionFor] = useState<PackageEntry | null>(null);
+    const [reinstallTarget, setReinstallTarget] = useState<PackageEntry | null>(null);
+    const [unlinkTarget, setUnlinkTarget] = useState<PackageEntry | null>(null);
+    const [linkPreview, setLinkPreview] = useState<brew.LinkPreview | null>(null);
+    const [packageActionLogs, setPackageActionLogs] = useState<{ title: string; log: string } | null>(null);
+    const [isPackageActionRunning, setIsPackageActionRunning] = useState<boolean>(false);
+    const [snapshotRestoreLogs, setSnapshotRestoreLogs] = useState<string | null>(null);
+    const [isSnapshotRestoreRunning, setIsSnapshotRestoreRunning] = useState<boolean>(false);
     // Homebrew 6 tap trust: when a tap/install is blocked because the tap is not
     // trusted, we prompt the user to trust it and then retry the original action.
     const [trustPrompt, setTrustPrompt] = useState<{ tap: string; retry: () => void | Promise<void> } | null>(null);
@@ -183,8 +245,7 @@ const WailBrewApp = () => {
     // Background update checking state
     const [isBackgroundCheckRunning, setIsBackgroundCheckRunning] = useState<boolean>(false);
     const lastKnownOutdatedCount = useRef<number>(0);
-    const backgroundCheckInterval = useRef<ReturnType<typeof setInterval> | null>(null);
-    const nextCheckTime = useRef<number>(Date.now() + 15 * 60 * 1000); // 15 minutes from now
+    const nextCheckTime = useRef<number>(0); // Unix ms of the next backend check, 0 when disabled
 
     // Track update event listeners for cleanup (prevents duplicate listeners bug)
     const updateListenersRef = useRef<{ progress: (() => void) | null; complete: (() => void) | null }>({
@@ -244,6 +305,24 @@ const WailBrewApp = () => {
         }
     };
 
+    const handleTogglePin = async (pkg: PackageEntry) => {
+        try {
+            const result = pkg.pinned ? await UnpinBrewPackage(pkg.name) : await PinBrewPackage(pkg.name);
+            if (result.includes("❌")) {
+                toast.error(result);
+                return;
+            }
+            toast.success(result);
+            const withPinned = (list: PackageEntry[]) =>
+                list.map((p) => (p.name === pkg.name ? { ...p, pinned: !pkg.pinned } : p));
+            setPackages(withPinned);
+            setUpdatablePackages(withPinned);
+        } catch (error) {
+            console.error("Failed to toggle pin:", error);
+            toast.error(String(error));
+        }
+    };
+
     // Persist window geometry on resize/move so it survives force-quits and
     // crashes (where the Go shutdown hook may not run). Debounced to avoid
     // flooding the config writer during a drag.
@@ -315,13 +394,18 @@ const WailBrewApp = () => {
                     throw new Error(`${t("errors.failedRepositories")}: ${safeRepos[0][1]}`);
                 }
 
-                const installedFormatted = safeInstalled.map(([name, installedVersion, size, installReason]) => ({
-                    name,
-                    installedVersion,
-                    size,
-                    installReason: (installReason as "on_request" | "dependency" | "unknown") || "unknown",
-                    isInstalled: true,
-                }));
+                const installedFormatted = safeInstalled.map(
+                    ([name, installedVersion, size, installReason, pinned, linked, kegOnly]) => ({
+                        name,
+                        installedVersion,
+                        size,
+                        installReason: (installReason as "on_request" | "dependency" | "unknown") || "unknown",
+                        pinned: pinned === "true",
+                        linked: linked === "true",
+                        kegOnly: kegOnly === "true",
+                        isInstalled: true,
+                    }),
+                );
                 const casksFormatted = safeInstalledCasks.map(([name, installedVersion, size]) => ({
                     name,
                     installedVersion,
@@ -330,7 +414,7 @@ const WailBrewApp = () => {
                 }));
                 const updatableFormatted = updatableErrorMessage
                     ? []
-                    : safeUpdatable.map(([name, installedVersion, latestVersion, size, warning, type]) => ({
+                    : safeUpdatable.map(([name, installedVersion, latestVersion, size, warning, type, pinned, policy, held, change]) => ({
                           name,
                           installedVersion,
                           latestVersion,
@@ -338,6 +422,10 @@ const WailBrewApp = () => {
                           isInstalled: true,
                           warning: warning || undefined,
                           isCask: type === "cask",
+                          pinned: pinned === "true",
+                          upgradePolicy: policy || undefined,
+                          policyHeld: held === "true",
+                          versionChange: change || undefined,
                       }));
                 // Format leaves packages with their versions and sizes from installed packages
                 const installedMap = new Map(
@@ -359,11 +447,12 @@ const WailBrewApp = () => {
                       });
 
                 // Format repositories
-                const reposFormatted = safeRepos.map(([name, status, trusted]) => ({
+                const reposFormatted = safeRepos.map(([name, status, trusted, managed]) => ({
                     name,
                     status,
                     desc: t("common.notAvailable"),
                     trusted: trusted === "true" ? true : trusted === "false" ? false : undefined,
+                    managed: managed === "true",
                 }));
 
                 setPackages(installedFormatted);
@@ -472,16 +561,6 @@ const WailBrewApp = () => {
                     setLoadingStartTime(null);
                 }, 5000);
             });
-
-        // Start background update checking
-        startBackgroundUpdateCheck();
-
-        // Cleanup on unmount
-        return () => {
-            if (backgroundCheckInterval.current) {
-                clearInterval(backgroundCheckInterval.current);
-            }
-        };
     }, []);
 
     useEffect(() => {
@@ -555,7 +634,8 @@ const WailBrewApp = () => {
     // Update Dock badge when updatable packages count changes
     useEffect(() => {
         const updateBadge = async () => {
-            const count = updatablePackages.length;
+            // Pinned formulae stay outdated on purpose, so they do not count.
+            const count = updatablePackages.filter((pkg) => !pkg.pinned).length;
             console.log(`[WailBrew] Updating dock badge to: ${count}`);
 
             try {
@@ -576,116 +656,240 @@ const WailBrewApp = () => {
         };
 
         updateBadge();
-    }, [updatablePackages.length]);
+    }, [updatablePackages]);
+
+    // Toast shown when the background check finds newly outdated packages
+    const showNewOutdatedToast = (count: number) => {
+        toast(
+            (t_obj) => (
+                <div className="toast-notification">
+                    <div className="toast-leading-icon">
+                        <RefreshCw size={20} color="var(--accent)" />
+                    </div>
+                    <div style={{ flex: 1 }}>
+                        <div style={{ fontWeight: 600, marginBottom: "0.5rem" }}>
+                            {count === 1
+                                ? t("toast.newOutdatedPackages_one", { count })
+                                : t("toast.newOutdatedPackages_other", { count })}
+                        </div>
+                        <button
+                            onClick={() => {
+                                setView("updatable");
+                                toast.dismiss(t_obj.id);
+                            }}
+                            className="toast-action-btn"
+                        >
+                            {t("toast.viewOutdated")}
+                        </button>
+                    </div>
+                    <button
+                        onClick={() => toast.dismiss(t_obj.id)}
+                        className="toast-dismiss-btn"
+                        title="Dismiss"
+                    >
+                        <X size={18} />
+                    </button>
+                </div>
+            ),
+            {
+                id: "startup-outdated-discovered",
+                duration: 8000,
+                position: "bottom-center",
+                style: customToastStyle,
+            },
+        );
+    };
 
-    // Background update checking function
-    const performBackgroundUpdateCheck = async () => {
-        if (isBackgroundCheckRunning) return;
+    // The backend checks for outdated packages on a schedule and reports
+    // what changed; see OutdatedScheduler.
+    useEffect(() => {
+        GetNextOutdatedCheck()
+            .then((next) => {
+                nextCheckTime.current = next;
+            })
+            .catch(() => {});
 
-        setIsBackgroundCheckRunning(true);
-        try {
-            // Use the "with update" version to ensure fresh data for background checks
-            const updatable = await GetBrewUpdatablePackagesWithUpdate();
+        const unlistenStarted = EventsOn("outdatedCheckStarted", () => {
+            setIsBackgroundCheckRunning(true);
+        });
+        // Also sent after every check, including ones that found no changes.
+        const unlistenScheduled = EventsOn("outdatedCheckScheduled", (next: string) => {
+            setIsBackgroundCheckRunning(false);
+            nextCheckTime.current = Number(next);
+        });
+        const unlistenFailed = EventsOn("outdatedCheckFailed", (error: string) => {
+            console.error("Background check failed:", error);
+            setIsBackgroundCheckRunning(false);
+        });
+        const unlistenChanged = EventsOn("outdatedChanged", (data: string) => {
+            setIsBackgroundCheckRunning(false);
+            try {
+                const diff: OutdatedDiff = JSON.parse(data);
+                setUpdatablePackages(diff.packages.map(outdatedToEntry));
+                setUpdatableError("");
 
-            // Check for errors
-            if (updatable.length === 1 && updatable[0][0] === "Error") {
-                console.error("Background check failed:", updatable[0][1]);
-                setUpdatableError(`${t("errors.failedUpdatablePackages")}: ${updatable[0][1]}`);
-                return;
+                // The first check is compared with what the app loaded at startup.
+                const newCount = diff.initial
+                    ? diff.packages.length - lastKnownOutdatedCount.current
+                    : diff.added.length;
+                if (newCount > 0) {
+                    showNewOutdatedToast(newCount);
+                }
+                lastKnownOutdatedCount.current = diff.packages.length;
+            } catch (error) {
+                console.error("Failed to parse outdated changes:", error);
             }
+        });
 
-            const currentCount = updatable.length;
-            const previousCount = lastKnownOutdatedCount.current;
-            const countChanged = currentCount !== previousCount;
+        return () => {
+            unlistenStarted();
+            unlistenScheduled();
+            unlistenFailed();
+            unlistenChanged();
+        };
+        // eslint-disable-next-line react-hooks/exhaustive-deps
+    }, []);
 
-            if (countChanged) {
-                const formatted = updatable.map(([name, installedVersion, latestVersion, size, warning, type]) => ({
-                    name,
-                    installedVersion,
-                    latestVersion,
-                    size,
-                    isInstalled: true,
-                    warning: warning || undefined,
-                    isCask: type === "cask",
-                }));
-                setUpdatablePackages(formatted);
-                setUpdatableError("");
+    // Failed unattended upgrades stay on screen until dismissed, here and
+    // across restarts, since nobody may have been watching when they ran.
+    const showAutoUpgradeFailure = (failures: AutoUpgradeRun[]) => {
+        const latest = failures[failures.length - 1];
+        const date = new Date(latest.startedAt).toLocaleString();
+        toast(
+            (t_obj) => (
+                <div className="toast-notification">
+                    <div className="toast-leading-icon">
+                        <AlertTriangle size={20} color="var(--error-color)" />
+                    </div>
+                    <div style={{ flex: 1 }}>
+                        <div style={{ fontWeight: 600, marginBottom: "0.25rem" }}>
+                            {failures.length === 1
+                                ? t("toast.autoUpgradeFailed_one", { date })
+                                : t("toast.autoUpgradeFailed_other", { count: failures.length, date })}
+                        </div>
+                        <div style={{ fontSize: "0.85rem", opacity: 0.8, wordBreak: "break-word" }}>
+                            {latest.message}
+                            {latest.failed && latest.failed.length > 0 && (
+                                <div>{t("toast.autoUpgradeNotUpgraded", { names: latest.failed.join(", ") })}</div>
+                            )}
+                        </div>
+                    </div>
+                    <button
+                        onClick={async () => {
+                            toast.dismiss(t_obj.id);
+                            try {
+                                await AcknowledgeAutoUpgradeFailures();
+                            } catch (error) {
+                                console.error("Failed to dismiss auto-upgrade failures:", error);
+                            }
+                        }}
+                        className="toast-dismiss-btn"
+                        title="Dismiss"
+                    >
+                        <X size={18} />
+                    </button>
+                </div>
+            ),
+            {
+                id: "auto-upgrade-failed",
+                duration: Infinity,
+                position: "bottom-center",
+                style: customToastStyle,
+            },
+        );
+    };
+
+    useEffect(() => {
+        GetAutoUpgradeFailures()
+            .then((failures) => {
+                if (failures && failures.length > 0) {
+                    showAutoUpgradeFailure(failures);
+                }
+            })
+            .catch((error) => console.error("Failed to load auto-upgrade failures:", error));
+
+        const unlistenFinished = EventsOn("autoUpgradeFinished", (data: string) => {
+            const run: AutoUpgradeRun = JSON.parse(data);
+            if (run.packages.length > 0) {
+                handleRefreshPackages();
+            }
+        });
+        const unlistenFailed = EventsOn("autoUpgradeFailed", async () => {
+            try {
+                showAutoUpgradeFailure(await GetAutoUpgradeFailures());
+            } catch (error) {
+                console.error("Failed to load auto-upgrade failures:", error);
             }
+        });
 
-            // Show toast only when new outdated packages are discovered
-            if (currentCount > previousCount) {
-                const newPackagesCount = currentCount - previousCount;
+        return () => {
+            unlistenFinished();
+            unlistenFailed();
+        };
+        // eslint-disable-next-line react-hooks/exhaustive-deps
+    }, []);
 
-                // Show toast notification
-                toast(
-                    (t_obj) => (
-                        <div className="toast-notification">
-                            <div className="toast-leading-icon">
-                                <RefreshCw size={20} color="var(--accent)" />
-                            </div>
-                            <div style={{ flex: 1 }}>
-                                <div style={{ fontWeight: 600, marginBottom: "0.5rem" }}>
-                                    {newPackagesCount === 1
-                                        ? t("toast.newOutdatedPackages_one", { count: newPackagesCount })
-                                        : t("toast.newOutdatedPackages_other", { count: newPackagesCount })}
-                                </div>
-                                <button
-                                    onClick={() => {
-                                        setView("updatable");
-                                        toast.dismiss(t_obj.id);
-                                    }}
-                                    className="toast-action-btn"
-                                >
-                                    {t("toast.viewOutdated")}
-                                </button>
-                            </div>
-                            <button
-                                onClick={() => toast.dismiss(t_obj.id)}
-                                className="toast-dismiss-btn"
-                                title="Dismiss"
-                            >
-                                <X size={18} />
-                            </button>
-                        </div>
-                    ),
-                    {
-                        id: "startup-outdated-discovered",
-                        duration: 8000,
-                        position: "bottom-center",
-                        style: customToastStyle,
-                    },
-                );
+    useEffect(() => {
+        const unlistenPreview = EventsOn("showBrewfilePreview", async (path: string) => {
+            const loadingToast = toast.loading(t("toast.brewfilePreviewLoading"), { position: "bottom-center" });
+            try {
+                setBrewfilePreview(await PreviewBrewfile(path));
+                toast.dismiss(loadingToast);
+            } catch (error) {
+                toast.error(t("toast.brewfilePreviewFailed", { error: String(error) }), {
+                    id: loadingToast,
+                    position: "bottom-center",
+                });
             }
+        });
 
-            // Update last known count
-            lastKnownOutdatedCount.current = currentCount;
-        } catch (error) {
-            console.error("Background update check error:", error);
-        } finally {
-            setIsBackgroundCheckRunning(false);
-            // Update next check time
-            nextCheckTime.current = Date.now() + 15 * 60 * 1000;
-        }
-    };
+        const unlistenCleanup = EventsOn("showBrewfileCleanup", async (path: string) => {
+            const loadingToast = toast.loading(t("toast.brewfileCleanupLoading"), { position: "bottom-center" });
+            try {
+                const items = await GetBrewfileCleanup(path);
+                setBrewfileCleanup({ path, items: items || [] });
+                toast.dismiss(loadingToast);
+            } catch (error) {
+                toast.error(t("toast.brewfilePreviewFailed", { error: String(error) }), {
+                    id: loadingToast,
+                    position: "bottom-center",
+                });
+            }
+        });
 
-    // Start background update checking
-    const startBackgroundUpdateCheck = () => {
-        // Perform initial check after a short delay
-        setTimeout(() => {
-            performBackgroundUpdateCheck();
-        }, 2000);
-
-        // Set up interval for checking every 15 minutes
-        backgroundCheckInterval.current = setInterval(
-            () => {
-                performBackgroundUpdateCheck();
-            },
-            15 * 60 * 1000,
-        ); // 15 minutes
-    };
+        const unlistenSnapshots = EventsOn("showSnapshots", () => setShowSnapshots(true));
+        const unlistenOperationHistory = EventsOn("showOperationHistory", () => setShowOperationHistory(true));
+        const unlistenFormulaVersions = EventsOn("showFormulaVersions", () => setShowFormulaVersions(true));
+        const unlistenUndo = EventsOn("undoLastOperation", async () => {
+            try {
+                const entry = await GetLastUndoableOperation();
+                if (!entry) {
+                    toast(t("toast.nothingToUndo"), { position: "bottom-center" });
+                } else if (!entry.inverse) {
+                    const operation = `${entry.kind} ${entry.target}`.trim();
+                    toast.error(t("toast.notUndoable", { operation }), { position: "bottom-center" });
+                } else {
+                    setUndoCandidate(entry);
+                }
+            } catch (error) {
+                toast.error(t("toast.operationHistoryFailed", { error: String(error) }), { position: "bottom-center" });
+            }
+        });
 
-    // Get seconds until next background check (computed on demand, no re-renders)
-    const getSecondsUntilNextCheck = (): number => {
+        return () => {
+            unlistenPreview();
+            unlistenCleanup();
+            unlistenSnapshots();
+            unlistenOperationHistory();
+            unlistenFormulaVersions();
+            unlistenUndo();
+        };
+    }, [t]);
+
+    // Get seconds until next background check (computed on demand, no re-renders);
+    // null when background checks are disabled
+    const getSecondsUntilNextCheck = (): number | null => {
+        if (nextCheckTime.current === 0) return null;
         const timeRemaining = Math.max(0, nextCheckTime.current - Date.now());
         return Math.floor(timeRemaining / 1000);
     };
@@ -1466,7 +1670,7 @@ const WailBrewApp = () => {
             // Clear cache and update the package lists after successful update
             await ClearBrewCache();
             const [updated, installedCasks] = await Promise.all([GetBrewUpdatablePackages(), GetBrewCasks()]);
-            const formatted = updated.map(([name, installedVersion, latestVersion, size, warning, type]) => ({
+            const formatted = updated.map(([name, installedVersion, latestVersion, size, warning, type, pinned, policy, held, change]) => ({
                 name,
                 installedVersion,
                 latestVersion,
@@ -1474,6 +1678,10 @@ const WailBrewApp = () => {
                 isInstalled: true,
                 warning: warning || undefined,
                 isCask: type === "cask",
+                pinned: pinned === "true",
+                upgradePolicy: policy || undefined,
+                policyHeld: held === "true",
+                versionChange: change || undefined,
             }));
             setUpdatablePackages(formatted);
 
@@ -1611,7 +1819,7 @@ const WailBrewApp = () => {
     };
 
     const selectAllPackages = () => {
-        const allNames = new Set(filteredPackages.map((pkg) => pkg.name));
+        const allNames = new Set(filteredPackages.filter((pkg) => !pkg.pinned).map((pkg) => pkg.name));
         setSelectedPackages(allNames);
     };
 
@@ -2056,6 +2264,234 @@ const WailBrewApp = () => {
         setShowInstallConfirm(true);
     };
 
+    const handleApplyBrewfile = async () => {
+        if (!brewfilePreview) return;
+        const { path } = brewfilePreview;
+        setBrewfilePreview(null);
+        setBrewfileLogs("");
+        setIsBrewfileRunning(true);
+
+        const progressListener = EventsOn("brewfileApplyProgress", (progress: string) => {
+            setBrewfileLogs((prevLogs) => (prevLogs ? `${prevLogs}\n${progress}` : progress));
+        });
+
+        const completeListener = EventsOn("brewfileApplyComplete", async (_finalMessage: string) => {
+            await handleRefreshPackages();
+            setIsBrewfileRunning(false);
+            progressListener();
+            completeListener();
+        });
+
+        try {
+            await ApplyBrewfile(path);
+        } catch (error) {
+            const errorMsg = `❌ Operation failed: ${String(error)}`;
+            setBrewfileLogs((prev) => (prev ? `${prev}\n${errorMsg}` : errorMsg));
+            setIsBrewfileRunning(false);
+            progressListener();
+            completeListener();
+        }
+    };
+
+    const handleRunBrewfileCleanup = async (confirmed: string[]) => {
+        if (!brewfileCleanup) return;
+        const { path } = brewfileCleanup;
+        setBrewfileCleanup(null);
+        setBrewfileCleanupLogs("");
+        setIsBrewfileCleanupRunning(true);
+
+        // Each removal streams through the regular uninstall and untap events
+        const appendLog = (progress: string) => {
+            setBrewfileCleanupLogs((prevLogs) => (prevLogs ? `${prevLogs}\n${progress}` : progress));
+        };
+        const listeners = [
+            EventsOn("brewfileCleanupProgress", appendLog),
+            EventsOn("packageUninstallProgress", appendLog),
+            EventsOn("repositoryUntapProgress", appendLog),
+        ];
+        const completeListener = EventsOn("brewfileCleanupComplete", async (_finalMessage: string) => {
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+            await handleRefreshPackages();
+            setIsBrewfileCleanupRunning(false);
+        });
+
+        try {
+            await RunBrewfileCleanup(path, confirmed);
+        } catch (error) {
+            appendLog(`❌ Operation failed: ${String(error)}`);
+            setIsBrewfileCleanupRunning(false);
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+        }
+    };
+
+    const handleRestoreSnapshot = async (id: string) => {
+        setShowSnapshots(false);
+        setSnapshotRestoreLogs("");
+        setIsSnapshotRestoreRunning(true);
+
+        // Each step streams through the regular tap and install events
+        const appendLog = (progress: string) => {
+            setSnapshotRestoreLogs((prevLogs) => (prevLogs ? `${prevLogs}\n${progress}` : progress));
+        };
+        const listeners = [
+            EventsOn("snapshotRestoreProgress", appendLog),
+            EventsOn("repositoryTapProgress", appendLog),
+            EventsOn("packageInstallProgress", appendLog),
+        ];
+        const completeListener = EventsOn("snapshotRestoreComplete", async (_finalMessage: string) => {
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+            await handleRefreshPackages();
+            setIsSnapshotRestoreRunning(false);
+        });
+
+        try {
+            await RestoreSnapshot(id);
+        } catch (error) {
+            appendLog(`❌ Operation failed: ${String(error)}`);
+            setIsSnapshotRestoreRunning(false);
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+        }
+    };
+
+    const handleUndoConfirmed = async () => {
+        setUndoCandidate(null);
+        setUndoLogs("");
+        setIsUndoRunning(true);
+
+        // The inverse runs as the regular action, so its own events stream too
+        const appendLog = (progress: string) => {
+            setUndoLogs((prevLogs) => (prevLogs ? `${prevLogs}\n${progress}` : progress));
+        };
+        const listeners = [
+            EventsOn("undoProgress", appendLog),
+            EventsOn("packageInstallProgress", appendLog),
+            EventsOn("packageUninstallProgress", appendLog),
+            EventsOn("packageLinkProgress", appendLog),
+            EventsOn("repositoryTapProgress", appendLog),
+            EventsOn("repositoryUntapProgress", appendLog),
+        ];
+        const completeListener = EventsOn("undoComplete", async (_finalMessage: string) => {
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+            await handleRefreshPackages();
+            setIsUndoRunning(false);
+        });
+
+        try {
+            await UndoLastOperation();
+        } catch (error) {
+            appendLog(`❌ Operation failed: ${String(error)}`);
+            setIsUndoRunning(false);
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+        }
+    };
+
+    // runPackageAction streams a reinstall, link, unlink or version switch into
+    // the package action log and refreshes the packages once the complete event
+    // arrives.
+    const runPackageAction = async (
+        title: string,
+        progressEvents: string[],
+        completeEvent: string,
+        run: () => Promise<string>,
+    ) => {
+        setPackageActionLogs({ title, log: "" });
+        setIsPackageActionRunning(true);
+
+        const appendLog = (progress: string) => {
+            setPackageActionLogs((prev) =>
+                prev ? { ...prev, log: prev.log ? `${prev.log}\n${progress}` : progress } : prev,
+            );
+        };
+        const listeners = progressEvents.map((event) => EventsOn(event, appendLog));
+        const completeListener = EventsOn(completeEvent, async (_finalMessage: string) => {
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+            await handleRefreshPackages();
+            setIsPackageActionRunning(false);
+        });
+
+        try {
+            await run();
+        } catch (error) {
+            appendLog(`❌ Operation failed: ${String(error)}`);
+            setIsPackageActionRunning(false);
+            for (const unlisten of listeners) unlisten();
+            completeListener();
+        }
+    };
+
+    const handleReinstallConfirmed = () => {
+        if (!reinstallTarget) return;
+        const packageName = reinstallTarget.name;
+        setReinstallTarget(null);
+        runPackageAction(
+            t("dialogs.reinstallLogs", { name: packageName }),
+            ["packageReinstallProgress"],
+            "packageReinstallComplete",
+            () => ReinstallBrewPackage(packageName),
+        );
+    };
+
+    const handleToggleLink = async (pkg: PackageEntry) => {
+        if (pkg.linked) {
+            setUnlinkTarget(pkg);
+            return;
+        }
+        const loadingToast = toast.loading(t("toast.linkPreviewLoading", { name: pkg.name }), {
+            position: "bottom-center",
+        });
+        try {
+            setLinkPreview(await PreviewLink(pkg.name));
+            toast.dismiss(loadingToast);
+        } catch (error) {
+            toast.error(t("toast.linkPreviewFailed", { error: String(error) }), {
+                id: loadingToast,
+                position: "bottom-center",
+            });
+        }
+    };
+
+    const handleLinkConfirmed = (overwrite: boolean) => {
+        if (!linkPreview) return;
+        const { name, kegOnly } = linkPreview;
+        setLinkPreview(null);
+        runPackageAction(t("dialogs.linkLogs", { name }), ["packageLinkProgress"], "packageLinkComplete", () =>
+            LinkBrewPackage(name, overwrite, kegOnly),
+        );
+    };
+
+    const handleUnlinkConfirmed = () => {
+        if (!unlinkTarget) return;
+        const packageName = unlinkTarget.name;
+        setUnlinkTarget(null);
+        runPackageAction(
+            t("dialogs.unlinkLogs", { name: packageName }),
+            ["packageLinkProgress"],
+            "packageLinkComplete",
+            () => UnlinkBrewPackage(packageName),
+        );
+    };
+
+    const handleSwitchConfirmed = () => {
+        if (!switchCandidate) return;
+        const { family, target } = switchCandidate;
+        setSwitchCandidate(null);
+        setShowFormulaVersions(false);
+        // Each step runs as the regular action, so its own events stream too
+        runPackageAction(
+            t("dialogs.formulaVersions.logs", { family, name: target }),
+            ["formulaSwitchProgress", "packageLinkProgress", "packageInstallProgress", "serviceActionProgress"],
+            "formulaSwitchComplete",
+            () => SwitchFormulaVersion(family, target),
+        );
+    };
+
     const handleInstallConfirmed = async () => {
         if (!selectedPackage) return;
         const packageName = selectedPackage.name;
@@ -2114,6 +2550,34 @@ const WailBrewApp = () => {
         }
     };
 
+    const handleInstallVersionConfirmed = async (version: string, pin: boolean) => {
+        if (!installVersionFor) return;
+        const packageName = installVersionFor.name;
+        setInstallVersionFor(null);
+        setInstallLogs(t("dialogs.installVersion.installing", { name: packageName, version }));
+        setIsInstallRunning(true);
+
+        const progressListener = EventsOn("packageInstallProgress", (progress: string) => {
+            setInstallLogs((prevLogs) => (prevLogs ? `${prevLogs}\n${progress}` : progress));
+        });
+        const completeListener = EventsOn("packageInstallComplete", async (_finalMessage: string) => {
+            progressListener();
+            completeListener();
+            await handleRefreshPackages();
+            setIsInstallRunning(false);
+        });
+
+        try {
+            await InstallBrewPackageVersion(packageName, version, pin);
+        } catch (error) {
+            const errorMsg = `❌ Operation failed: ${String(error)}`;
+            setInstallLogs((prev) => (prev ? `${prev}\n${errorMsg}` : errorMsg));
+            setIsInstallRunning(false);
+            progressListener();
+            completeListener();
+        }
+    };
+
     const handleTrustConfirmed = async () => {
         if (!trustPrompt) return;
         const { tap, retry } = trustPrompt;
@@ -2394,13 +2858,18 @@ const WailBrewApp = () => {
                 setPackages([]);
                 checkBrewLocation();
             } else {
-                const formatted = safeInstalled.map(([name, installedVersion, size, installReason]) => ({
-                    name,
-                    installedVersion,
-                    size,
-                    installReason: (installReason as "on_request" | "dependency" | "unknown") || "unknown",
-                    isInstalled: true,
-                }));
+                const formatted = safeInstalled.map(
+                    ([name, installedVersion, size, installReason, pinned, linked, kegOnly]) => ({
+                        name,
+                        installedVersion,
+                        size,
+                        installReason: (installReason as "on_request" | "dependency" | "unknown") || "unknown",
+                        pinned: pinned === "true",
+                        linked: linked === "true",
+                        kegOnly: kegOnly === "true",
+                        isInstalled: true,
+                    }),
+                );
                 setPackages(formatted);
 
                 // Lazy load package sizes in the background
@@ -2464,7 +2933,7 @@ const WailBrewApp = () => {
                 setUpdatablePackages([]);
                 setUpdatableError(`${t("errors.failedUpdatablePackages")}: ${safeUpdatable[0][1]}`);
             } else {
-                const formatted = safeUpdatable.map(([name, installedVersion, latestVersion, size, warning, type]) => ({
+                const formatted = safeUpdatable.map(([name, installedVersion, latestVersion, size, warning, type, pinned, policy, held, change]) => ({
                     name,
                     installedVersion,
                     latestVersion,
@@ -2472,6 +2941,10 @@ const WailBrewApp = () => {
                     isInstalled: true,
                     warning: warning || undefined,
                     isCask: type === "cask",
+                    pinned: pinned === "true",
+                    upgradePolicy: policy || undefined,
+                    policyHeld: held === "true",
+                    versionChange: change || undefined,
                 }));
                 setUpdatablePackages(formatted);
                 setUpdatableError("");
@@ -2502,10 +2975,11 @@ const WailBrewApp = () => {
             if (safeRepos.length === 1 && safeRepos[0][0] === "Error") {
                 setRepositories([]);
             } else {
-                const formatted = safeRepos.map(([name, status, trusted]) => ({
+                const formatted = safeRepos.map(([name, status, trusted, managed]) => ({
                     name,
                     status,
                     trusted: trusted === "true" ? true : trusted === "false" ? false : undefined,
+                    managed: managed === "true",
                 }));
                 setRepositories(formatted);
             }
@@ -2660,6 +3134,10 @@ const WailBrewApp = () => {
                                 columns={columnsInstalled}
                                 onUninstall={handleUninstallPackage}
                                 onShowInfo={handleShowPackageInfo}
+                                onTogglePin={handleTogglePin}
+                                onInstallVersion={setInstallVersionFor}
+                                onReinstall={setReinstallTarget}
+                                onToggleLink={handleToggleLink}
                             />
                             <div className="info-footer-container">
                                 <div className="package-info">
@@ -2793,6 +3271,7 @@ const WailBrewApp = () => {
                                     onUninstall={handleUninstallPackage}
                                     onShowInfo={handleShowInfoLogs}
                                     onUpdate={handleUpdate}
+                                    onTogglePin={handleTogglePin}
                                     multiSelectMode={true}
                                     selectedPackages={selectedPackages}
                                     onTogglePackageSelect={togglePackageSelection}
@@ -3289,6 +3768,127 @@ const WailBrewApp = () => {
                             setIsInstallRunning(false);
                         }}
                     />
+                    <BrewfilePreviewDialog
+                        preview={brewfilePreview}
+                        onApply={handleApplyBrewfile}
+                        onCancel={() => setBrewfilePreview(null)}
+                    />
+                    <BrewfileCleanupDialog
+                        path={brewfileCleanup?.path ?? ""}
+                        items={brewfileCleanup?.items ?? null}
+                        onRun={handleRunBrewfileCleanup}
+                        onCancel={() => setBrewfileCleanup(null)}
+                    />
+                    <LogDialog
+                        open={brewfileCleanupLogs !== null}
+                        title={t("dialogs.brewfileCleanupLogs")}
+                        log={brewfileCleanupLogs}
+                        isRunning={isBrewfileCleanupRunning}
+                        onClose={() => {
+                            setBrewfileCleanupLogs(null);
+                            setIsBrewfileCleanupRunning(false);
+                        }}
+                    />
+                    <InstallVersionDialog
+                        packageName={installVersionFor?.name ?? null}
+                        onConfirm={handleInstallVersionConfirmed}
+                        onCancel={() => setInstallVersionFor(null)}
+                    />
+                    <ConfirmDialog
+                        open={reinstallTarget !== null}
+                        message={t("dialogs.confirmReinstall", { name: reinstallTarget?.name ?? "" })}
+                        onConfirm={handleReinstallConfirmed}
+                        onCancel={() => setReinstallTarget(null)}
+                        commandSpec={
+                            reinstallTarget ? { action: "reinstall", targets: [reinstallTarget.name] } : undefined
+                        }
+                    />
+                    <ConfirmDialog
+                        open={unlinkTarget !== null}
+                        message={t("dialogs.confirmUnlink", { name: unlinkTarget?.name ?? "" })}
+                        onConfirm={handleUnlinkConfirmed}
+                        onCancel={() => setUnlinkTarget(null)}
+                        commandSpec={unlinkTarget ? { action: "unlink", targets: [unlinkTarget.name] } : undefined}
+                    />
+                    <LinkDialog
+                        preview={linkPreview}
+                        onConfirm={handleLinkConfirmed}
+                        onCancel={() => setLinkPreview(null)}
+                    />
+                    <LogDialog
+                        open={packageActionLogs !== null}
+                        title={packageActionLogs?.title ?? ""}
+                        log={packageActionLogs?.log ?? null}
+                        isRunning={isPackageActionRunning}
+                        onClose={() => {
+                            setPackageActionLogs(null);
+                            setIsPackageActionRunning(false);
+                        }}
+                    />
+                    <SnapshotsDialog
+                        open={showSnapshots}
+                        onRestore={handleRestoreSnapshot}
+                        onClose={() => setShowSnapshots(false)}
+                    />
+                    <FormulaVersionsDialog
+                        open={showFormulaVersions}
+                        onSwitch={(family, target) => setSwitchCandidate({ family, target })}
+                        onClose={() => setShowFormulaVersions(false)}
+                    />
+                    <ConfirmDialog
+                        open={switchCandidate !== null}
+                        message={t("dialogs.formulaVersions.confirm", {
+                            family: switchCandidate?.family ?? "",
+                            name: switchCandidate?.target ?? "",
+                        })}
+                        onConfirm={handleSwitchConfirmed}
+                        onCancel={() => setSwitchCandidate(null)}
+                        confirmLabel={t("dialogs.formulaVersions.use")}
+                    />
+                    <OperationHistoryDialog
+                        open={showOperationHistory}
+                        onClose={() => setShowOperationHistory(false)}
+                    />
+                    <ConfirmDialog
+                        open={undoCandidate !== null}
+                        message={t("dialogs.undo.confirm", {
+                            operation: undoCandidate ? `${undoCandidate.kind} ${undoCandidate.target}`.trim() : "",
+                            command: undoCandidate?.inverse ? `brew ${undoCandidate.inverse.args.join(" ")}` : "",
+                        })}
+                        onConfirm={handleUndoConfirmed}
+                        onCancel={() => setUndoCandidate(null)}
+                        confirmLabel={t("dialogs.undo.confirmButton")}
+                    />
+                    <LogDialog
+                        open={undoLogs !== null}
+                        title={t("dialogs.undoLogs")}
+                        log={undoLogs}
+                        isRunning={isUndoRunning}
+                        onClose={() => {
+                            setUndoLogs(null);
+                            setIsUndoRunning(false);
+                        }}
+                    />
+                    <LogDialog
+                        open={snapshotRestoreLogs !== null}
+                        title={t("dialogs.snapshotRestoreLogs")}
+                        log={snapshotRestoreLogs}
+                        isRunning={isSnapshotRestoreRunning}
+                        onClose={() => {
+                            setSnapshotRestoreLogs(null);
+                            setIsSnapshotRestoreRunning(false);
+                        }}
+                    />
+                    <LogDialog
+                        open={brewfileLogs !== null}
+                        title={t("dialogs.brewfileApplyLogs")}
+                        log={brewfileLogs}
+                        isRunning={isBrewfileRunning}
+                        onClose={() => {
+                            setBrewfileLogs(null);
+                            setIsBrewfileRunning(false);
+                        }}
+                    />
                     <LogDialog
                         open={uninstallLogs !== null}
                         title={
@@ -0,0 +1,88 @@
+import type React from "react";
+import { useEffect, useState } from "react";
+import { useTranslation } from "react-i18next";
+import type { brew } from "../../wailsjs/go/models";
+
+interface BrewfileCleanupDialogProps {
+    path: string;
+    items: brew.BrewfileCleanupItem[] | null;
+    // Receives the names to remove: the listed items the user did not keep.
+    onRun: (confirmed: string[]) => void;
+    onCancel: () => void;
+}
+
+const BrewfileCleanupDialog: React.FC<BrewfileCleanupDialogProps> = ({ path, items, onRun, onCancel }) => {
+    const { t } = useTranslation();
+    const [keep, setKeep] = useState<Set<string>>(new Set());
+
+    useEffect(() => {
+        setKeep(new Set());
+    }, [items]);
+
+    if (!items) return null;
+
+    const toggleKeep = (name: string) => {
+        setKeep((prev) => {
+            const next = new Set(prev);
+            if (next.has(name)) {
+                next.delete(name);
+            } else {
+                next.add(name);
+            }
+            return next;
+        });
+    };
+
+    const removeCount = items.length - keep.size;
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview">
+                <p>{t("dialogs.brewfileCleanup.title", { path })}</p>
+                <span className="brewfile-preview-summary">
+                    {items.length === 0
+                        ? t("dialogs.brewfileCleanup.nothingToRemove")
+                        : t("dialogs.brewfileCleanup.hint")}
+                </span>
+                {items.length > 0 && (
+                    <div className="brewfile-preview-group">
+                        {items.map((item) => (
+                            <label key={`${item.type}:${item.name}`} className="brewfile-cleanup-item">
+                                <input
+                                    type="checkbox"
+                                    checked={keep.has(item.name)}
+                                    onChange={() => toggleKeep(item.name)}
+                                />
+                                <span className="brewfile-cleanup-name">{item.name}</span>
+                                <span className="brewfile-cleanup-meta">
+                                    {t(`dialogs.brewfileCleanup.types.${item.type}`)}
+                                    {item.size && ` · ${item.size}`}
+                                </span>
+                                {item.dependents.length > 0 && (
+                                    <span className="confirm-dependents-title">
+                                        ⚠{" "}
+                                        {t("dialogs.brewfileCleanup.requiredBy", {
+                                            names: item.dependents.join(", "),
+                                        })}
+                                    </span>
+                                )}
+                            </label>
+                        ))}
+                    </div>
+                )}
+                <div className="confirm-actions">
+                    <button
+                        className="destructive"
+                        onClick={() => onRun(items.filter((item) => !keep.has(item.name)).map((item) => item.name))}
+                        disabled={removeCount === 0}
+                    >
+                        {t("dialogs.brewfileCleanup.remove", { count: removeCount })}
+                    </button>
+                    <button onClick={onCancel}>{t("buttons.cancel")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default BrewfileCleanupDialog;
@@ -0,0 +1,71 @@
+import type React from "react";
+import { useTranslation } from "react-i18next";
+import type { brew } from "../../wailsjs/go/models";
+
+interface BrewfilePreviewDialogProps {
+    preview: brew.BrewfilePreview | null;
+    onApply: () => void;
+    onCancel: () => void;
+}
+
+const BrewfilePreviewDialog: React.FC<BrewfilePreviewDialogProps> = ({ preview, onApply, onCancel }) => {
+    const { t } = useTranslation();
+
+    if (!preview) return null;
+
+    const groups = [
+        { key: "taps", names: preview.taps },
+        { key: "install", names: preview.install },
+        { key: "upgrade", names: preview.upgrade },
+    ].filter((group) => group.names.length > 0);
+    const satisfied = preview.entries.filter((entry) => entry.status === "satisfied").length;
+    const conditional = preview.entries.filter((entry) => entry.conditional).length;
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview">
+                <p>{t("dialogs.brewfilePreview.title", { path: preview.path })}</p>
+                <span className="brewfile-preview-summary">
+                    {t("dialogs.brewfilePreview.summary", { satisfied, total: preview.entries.length })}
+                </span>
+                {conditional > 0 && (
+                    <span className="brewfile-preview-summary">
+                        {t("dialogs.brewfilePreview.conditional", { count: conditional })}
+                    </span>
+                )}
+                {groups.length === 0 ? (
+                    <span className="brewfile-preview-summary">{t("dialogs.brewfilePreview.nothingToDo")}</span>
+                ) : (
+                    groups.map((group) => (
+                        <div key={group.key} className="brewfile-preview-group">
+                            <span className="brewfile-preview-group-title">
+                                {t(`dialogs.brewfilePreview.${group.key}`, { count: group.names.length })}
+                            </span>
+                            <div className="confirm-dependents-chips">
+                                {group.names.map((name) => (
+                                    <span key={name} className="brewfile-preview-chip">
+                                        {name}
+                                    </span>
+                                ))}
+                            </div>
+                        </div>
+                    ))
+                )}
+                <div className="confirm-command">
+                    <span className="confirm-command-label">{t("dialogs.commandPreview")}</span>
+                    <code className="confirm-command-text" dir="ltr">
+                        {preview.command}
+                    </code>
+                </div>
+                <div className="confirm-actions">
+                    <button onClick={onApply} disabled={groups.length === 0}>
+                        {t("dialogs.brewfilePreview.apply")}
+                    </button>
+                    <button onClick={onCancel}>{t("buttons.cancel")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default BrewfilePreviewDialog;
@@ -6,7 +6,18 @@ import { useTranslation } from "react-i18next";
 import { PreviewBrewCommand } from "../../wailsjs/go/main/App";
 
 export interface CommandSpec {
-    action: "install" | "uninstall" | "upgrade" | "upgrade-selected" | "upgrade-all" | "tap" | "untap" | "trust";
+    action:
+        | "install"
+        | "uninstall"
+        | "reinstall"
+        | "upgrade"
+        | "upgrade-selected"
+        | "upgrade-all"
+        | "link"
+        | "unlink"
+        | "tap"
+        | "untap"
+        | "trust";
     targets: string[];
     isCask?: boolean;
     zap?: boolean;
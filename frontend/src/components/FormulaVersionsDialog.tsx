@@ -0,0 +1,95 @@
+import type React from "react";
+import { useCallback, useEffect, useState } from "react";
+import toast from "react-hot-toast";
+import { useTranslation } from "react-i18next";
+import { GetFormulaFamilies } from "../../wailsjs/go/main/App";
+import type { brew } from "../../wailsjs/go/models";
+
+interface FormulaVersionsDialogProps {
+    open: boolean;
+    onSwitch: (family: string, target: string) => void;
+    onClose: () => void;
+}
+
+const FormulaVersionsDialog: React.FC<FormulaVersionsDialogProps> = ({ open, onSwitch, onClose }) => {
+    const { t } = useTranslation();
+    const [families, setFamilies] = useState<brew.FormulaFamily[] | null>(null);
+
+    const load = useCallback(async () => {
+        try {
+            setFamilies(await GetFormulaFamilies());
+        } catch (error) {
+            toast.error(t("toast.formulaVersionsFailed", { error: String(error) }), {
+                position: "bottom-center",
+            });
+        }
+    }, [t]);
+
+    useEffect(() => {
+        if (!open) {
+            setFamilies(null);
+            return;
+        }
+        load();
+    }, [open, load]);
+
+    if (!open) return null;
+
+    const describeMember = (member: brew.FamilyMember) => {
+        if (!member.installed) return t("dialogs.formulaVersions.notInstalled");
+        const parts = [member.version];
+        if (member.linked) parts.push(t("dialogs.formulaVersions.linked"));
+        if (member.kegOnly) parts.push(t("dialogs.formulaVersions.kegOnly"));
+        if (member.serviceRunning) parts.push(t("dialogs.formulaVersions.serviceRunning"));
+        return parts.join(" · ");
+    };
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview snapshots-dialog">
+                <p>{t("dialogs.formulaVersions.title")}</p>
+                <span className="brewfile-preview-summary">{t("dialogs.formulaVersions.hint")}</span>
+
+                <div className="brewfile-preview-group">
+                    {families && families.length === 0 && (
+                        <span className="brewfile-cleanup-meta">{t("dialogs.formulaVersions.empty")}</span>
+                    )}
+                    {(families ?? []).map((family) => (
+                        <div key={family.name} className="brewfile-preview-group">
+                            <span className="brewfile-preview-group-title">
+                                {family.linked
+                                    ? t("dialogs.formulaVersions.familyLinked", {
+                                          family: family.name,
+                                          linked: family.linked,
+                                      })
+                                    : t("dialogs.formulaVersions.familyUnlinked", { family: family.name })}
+                            </span>
+                            {family.members.map((member) => (
+                                <div key={member.name} className="snapshots-item">
+                                    <span className="brewfile-cleanup-name">{member.name}</span>
+                                    <span className="brewfile-cleanup-meta">{describeMember(member)}</span>
+                                    <div className="snapshots-item-actions">
+                                        <button
+                                            onClick={() => onSwitch(family.name, member.name)}
+                                            disabled={member.linked}
+                                        >
+                                            {member.installed
+                                                ? t("dialogs.formulaVersions.use")
+                                                : t("dialogs.formulaVersions.installAndUse")}
+                                        </button>
+                                    </div>
+                                </div>
+                            ))}
+                        </div>
+                    ))}
+                </div>
+
+                <div className="confirm-actions">
+                    <button onClick={onClose}>{t("buttons.close")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default FormulaVersionsDialog;
@@ -0,0 +1,88 @@
+import type React from "react";
+import { useEffect, useRef, useState } from "react";
+import { useTranslation } from "react-i18next";
+
+interface InstallVersionDialogProps {
+    packageName: string | null;
+    onConfirm: (version: string, pin: boolean) => void;
+    onCancel: () => void;
+}
+
+const versionPattern = /^[0-9A-Za-z][0-9A-Za-z._+-]*$/;
+
+const InstallVersionDialog: React.FC<InstallVersionDialogProps> = ({ packageName, onConfirm, onCancel }) => {
+    const { t } = useTranslation();
+    const [version, setVersion] = useState("");
+    const [pin, setPin] = useState(true);
+    const [error, setError] = useState<string | null>(null);
+    const inputRef = useRef<HTMLInputElement>(null);
+
+    useEffect(() => {
+        setVersion("");
+        setPin(true);
+        setError(null);
+        if (packageName) {
+            inputRef.current?.focus();
+        }
+    }, [packageName]);
+
+    if (!packageName) return null;
+
+    const handleConfirm = () => {
+        const trimmed = version.trim();
+        if (!versionPattern.test(trimmed)) {
+            setError(t("dialogs.installVersion.invalid"));
+            return;
+        }
+        onConfirm(trimmed, pin);
+    };
+
+    const handleKeyDown = (e: React.KeyboardEvent) => {
+        if (e.key === "Enter") {
+            e.preventDefault();
+            handleConfirm();
+        } else if (e.key === "Escape") {
+            e.preventDefault();
+            onCancel();
+        }
+    };
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box">
+                <p>{t("dialogs.installVersion.title", { name: packageName })}</p>
+                <span className="brewfile-preview-summary">{t("dialogs.installVersion.hint")}</span>
+                <div className="dialog-input-row">
+                    <input
+                        ref={inputRef}
+                        className="dialog-input"
+                        type="text"
+                        value={version}
+                        placeholder={t("dialogs.installVersion.placeholder")}
+                        onChange={(e) => {
+                            setVersion(e.target.value);
+                            setError(null);
+                        }}
+                        onKeyDown={handleKeyDown}
+                    />
+                </div>
+                {error && <span className="result error">{error}</span>}
+                <div className="confirm-checkbox">
+                    <label>
+                        <input type="checkbox" checked={pin} onChange={(e) => setPin(e.target.checked)} />
+                        {t("dialogs.installVersion.pin")}
+                    </label>
+                    <span className="confirm-checkbox-hint">{t("dialogs.installVersion.pinHint")}</span>
+                </div>
+                <div className="confirm-actions">
+                    <button onClick={handleConfirm} disabled={version.trim() === ""}>
+                        {t("buttons.install", { name: packageName })}
+                    </button>
+                    <button onClick={onCancel}>{t("buttons.cancel")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default InstallVersionDialog;
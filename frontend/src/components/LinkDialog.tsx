@@ -0,0 +1,93 @@
+import type React from "react";
+import { useEffect, useState } from "react";
+import { useTranslation } from "react-i18next";
+import type { brew } from "../../wailsjs/go/models";
+
+interface LinkDialogProps {
+    preview: brew.LinkPreview | null;
+    onConfirm: (overwrite: boolean) => void;
+    onCancel: () => void;
+}
+
+const LinkDialog: React.FC<LinkDialogProps> = ({ preview, onConfirm, onCancel }) => {
+    const { t } = useTranslation();
+    const [overwrite, setOverwrite] = useState(false);
+
+    useEffect(() => {
+        setOverwrite(false);
+    }, [preview]);
+
+    if (!preview) return null;
+
+    const links = preview.links ?? [];
+    const conflicts = preview.conflicts ?? [];
+    // A plain link stops at the first conflicting file, so it only goes ahead
+    // once the user agrees to overwrite them
+    const blocked = conflicts.length > 0 && !overwrite;
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview">
+                <p>{t("dialogs.link.title", { name: preview.name })}</p>
+                {preview.kegOnly && <span className="brewfile-preview-summary">{t("dialogs.link.kegOnly")}</span>}
+                {preview.alreadyLinked && (
+                    <span className="brewfile-preview-summary">{t("dialogs.link.alreadyLinked")}</span>
+                )}
+                {!preview.alreadyLinked && links.length === 0 && (
+                    <span className="brewfile-preview-summary">{t("dialogs.link.nothingToLink")}</span>
+                )}
+                {links.length > 0 && (
+                    <div className="brewfile-preview-group">
+                        <span className="brewfile-preview-group-title">
+                            {t("dialogs.link.links", { count: links.length })}
+                        </span>
+                        {links.map((path) => (
+                            <span key={path} className="brewfile-cleanup-meta" dir="ltr">
+                                {path}
+                            </span>
+                        ))}
+                    </div>
+                )}
+                {conflicts.length > 0 && (
+                    <>
+                        <div className="brewfile-preview-group">
+                            <span className="brewfile-preview-group-title">
+                                ⚠ {t("dialogs.link.conflicts", { count: conflicts.length })}
+                            </span>
+                            {conflicts.map((path) => (
+                                <span key={path} className="brewfile-cleanup-meta" dir="ltr">
+                                    {path}
+                                </span>
+                            ))}
+                        </div>
+                        <div className="confirm-checkbox">
+                            <label>
+                                <input
+                                    type="checkbox"
+                                    checked={overwrite}
+                                    onChange={(e) => setOverwrite(e.target.checked)}
+                                />
+                                <span>{t("dialogs.link.overwrite")}</span>
+                            </label>
+                            <span className="confirm-checkbox-hint">{t("dialogs.link.overwriteHint")}</span>
+                        </div>
+                    </>
+                )}
+                <div className="confirm-command">
+                    <span className="confirm-command-label">{t("dialogs.commandPreview")}</span>
+                    <code className="confirm-command-text" dir="ltr">
+                        {overwrite ? preview.overwriteCommand : preview.command}
+                    </code>
+                </div>
+                <div className="confirm-actions">
+                    <button onClick={() => onConfirm(overwrite)} disabled={preview.alreadyLinked || blocked}>
+                        {t("buttons.link", { name: preview.name })}
+                    </button>
+                    <button onClick={onCancel}>{t("buttons.cancel")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default LinkDialog;
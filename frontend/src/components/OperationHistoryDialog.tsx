@@ -0,0 +1,203 @@
+import type React from "react";
+import { useCallback, useEffect, useState } from "react";
+import toast from "react-hot-toast";
+import { useTranslation } from "react-i18next";
+import { GetOperationHistory } from "../../wailsjs/go/main/App";
+import { brew } from "../../wailsjs/go/models";
+
+interface OperationHistoryDialogProps {
+    open: boolean;
+    onClose: () => void;
+}
+
+const PAGE_SIZE = 50;
+
+const KINDS = [
+    "install",
+    "install-version",
+    "uninstall",
+    "reinstall",
+    "upgrade",
+    "upgrade-selected",
+    "upgrade-all",
+    "pin",
+    "unpin",
+    "link",
+    "unlink",
+    "switch-version",
+    "tap",
+    "untap",
+    "trust",
+    "autoremove",
+    "tab",
+    "brewfile",
+    "service-start",
+    "service-stop",
+    "service-restart",
+    "service-run",
+    "homebrew-update",
+    "undo",
+];
+
+const OperationHistoryDialog: React.FC<OperationHistoryDialogProps> = ({ open, onClose }) => {
+    const { t } = useTranslation();
+    const [packageName, setPackageName] = useState("");
+    const [kind, setKind] = useState("");
+    const [offset, setOffset] = useState(0);
+    const [page, setPage] = useState<brew.JournalPage | null>(null);
+    const [expanded, setExpanded] = useState<string | null>(null);
+
+    const load = useCallback(
+        async (filter: { package: string; kind: string; offset: number }) => {
+            try {
+                const result = await GetOperationHistory(
+                    brew.JournalFilter.createFrom({ ...filter, limit: PAGE_SIZE }),
+                );
+                setPage(result);
+            } catch (error) {
+                toast.error(t("toast.operationHistoryFailed", { error: String(error) }), {
+                    position: "bottom-center",
+                });
+            }
+        },
+        [t],
+    );
+
+    useEffect(() => {
+        if (!open) {
+            setPackageName("");
+            setKind("");
+            setOffset(0);
+            setPage(null);
+            setExpanded(null);
+            return;
+        }
+        load({ package: "", kind: "", offset: 0 });
+    }, [open, load]);
+
+    if (!open) return null;
+
+    const apply = (next: { package?: string; kind?: string; offset?: number }) => {
+        const filter = { package: packageName.trim(), kind, offset: 0, ...next };
+        setOffset(filter.offset);
+        setExpanded(null);
+        load(filter);
+    };
+
+    const entries = page?.entries ?? [];
+    const total = page?.total ?? 0;
+
+    const describeChange = (change: brew.JournalChange) => {
+        if (!change.from) return `+ ${change.name} ${change.to}`;
+        if (!change.to) return `− ${change.name} ${change.from}`;
+        return `~ ${change.name} ${change.from} → ${change.to}`;
+    };
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview snapshots-dialog">
+                <p>{t("dialogs.operationHistory.title")}</p>
+                <span className="brewfile-preview-summary">{t("dialogs.operationHistory.hint")}</span>
+
+                <div className="dialog-input-row">
+                    <input
+                        className="dialog-input"
+                        type="text"
+                        value={packageName}
+                        placeholder={t("dialogs.operationHistory.packagePlaceholder")}
+                        onChange={(e) => setPackageName(e.target.value)}
+                        onKeyDown={(e) => e.key === "Enter" && apply({})}
+                    />
+                    <select
+                        className="dialog-input"
+                        value={kind}
+                        onChange={(e) => {
+                            setKind(e.target.value);
+                            apply({ kind: e.target.value });
+                        }}
+                    >
+                        <option value="">{t("dialogs.operationHistory.allKinds")}</option>
+                        {KINDS.map((k) => (
+                            <option key={k} value={k}>
+                                {k}
+                            </option>
+                        ))}
+                    </select>
+                    <button onClick={() => apply({})}>{t("dialogs.operationHistory.search")}</button>
+                </div>
+
+                <div className="brewfile-preview-group">
+                    {page && entries.length === 0 && (
+                        <span className="brewfile-cleanup-meta">{t("dialogs.operationHistory.empty")}</span>
+                    )}
+                    {entries.map((entry) => (
+                        <div key={entry.id} className="snapshots-item">
+                            <span className="brewfile-cleanup-name">
+                                {entry.success ? "✅" : "❌"} {entry.kind} {entry.target}
+                            </span>
+                            <span className="brewfile-cleanup-meta">
+                                {t("dialogs.operationHistory.meta", {
+                                    date: new Date(entry.startedAt).toLocaleString(),
+                                    seconds: (entry.durationMs / 1000).toFixed(1),
+                                    user: entry.user,
+                                })}
+                            </span>
+                            <div className="snapshots-item-actions">
+                                <button onClick={() => setExpanded(expanded === entry.id ? null : entry.id)}>
+                                    {expanded === entry.id
+                                        ? t("dialogs.operationHistory.hideDetails")
+                                        : t("dialogs.operationHistory.showDetails")}
+                                </button>
+                            </div>
+                            {expanded === entry.id && (
+                                <div className="operation-history-details">
+                                    {entry.commands.map((command, i) => (
+                                        <span key={`${entry.id}-cmd-${i}`} className="brewfile-cleanup-meta">
+                                            {t("dialogs.operationHistory.command", {
+                                                command: `brew ${command.args.join(" ")}`,
+                                                code: command.exitCode,
+                                            })}
+                                        </span>
+                                    ))}
+                                    {entry.changes.map((change) => (
+                                        <span key={`${entry.id}-${change.name}`} className="brewfile-cleanup-name">
+                                            {describeChange(change)}
+                                        </span>
+                                    ))}
+                                    {entry.result && <span className="brewfile-cleanup-meta">{entry.result}</span>}
+                                </div>
+                            )}
+                        </div>
+                    ))}
+                </div>
+
+                {total > PAGE_SIZE && (
+                    <div className="dialog-input-row">
+                        <button onClick={() => apply({ offset: offset - PAGE_SIZE })} disabled={offset === 0}>
+                            {t("dialogs.operationHistory.newer")}
+                        </button>
+                        <span className="brewfile-cleanup-meta">
+                            {t("dialogs.operationHistory.range", {
+                                from: offset + 1,
+                                to: Math.min(offset + PAGE_SIZE, total),
+                                total,
+                            })}
+                        </span>
+                        <button
+                            onClick={() => apply({ offset: offset + PAGE_SIZE })}
+                            disabled={offset + PAGE_SIZE >= total}
+                        >
+                            {t("dialogs.operationHistory.older")}
+                        </button>
+                    </div>
+                )}
+
+                <div className="confirm-actions">
+                    <button onClick={onClose}>{t("buttons.close")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default OperationHistoryDialog;
@@ -2,7 +2,12 @@ import { BarChart3, Calendar, ChevronDown, ExternalLink, GitBranch, TrendingUp }
 import type React from "react";
 import { useEffect, useRef, useState } from "react";
 import { useTranslation } from "react-i18next";
-import { GetInstalledDependencies, GetInstalledDependents } from "../../wailsjs/go/main/App";
+import {
+    GetInstalledDependencies,
+    GetInstalledDependents,
+    GetUpgradePolicy,
+    SetUpgradePolicy,
+} from "../../wailsjs/go/main/App";
 import { BrowserOpenURL } from "../../wailsjs/runtime/runtime";
 import type { PackageEntry } from "../types";
 
@@ -39,6 +44,7 @@ const PackageInfo: React.FC<PackageInfoProps> = ({ packageEntry, loadingDetailsF
     const [showInstalledDependents, setShowInstalledDependents] = useState(false);
     const [installedDependents, setInstalledDependents] = useState<string[]>([]);
     const [loadingInstalledDependents, setLoadingInstalledDependents] = useState(false);
+    const [upgradePolicy, setUpgradePolicy] = useState("");
 
     const dependencies = packageEntry?.dependencies || [];
     const conflicts = packageEntry?.conflicts?.filter(Boolean) || [];
@@ -54,6 +60,30 @@ const PackageInfo: React.FC<PackageInfoProps> = ({ packageEntry, loadingDetailsF
         }
     };
 
+    // Upgrade policy of installed packages, applied by "Update All"
+    useEffect(() => {
+        setUpgradePolicy("");
+        if (!packageEntry?.name || !packageEntry.isInstalled) return;
+        const name = packageEntry.name;
+        GetUpgradePolicy(name)
+            .then((policy) => {
+                if (packageEntry.name === name) setUpgradePolicy(policy);
+            })
+            .catch(() => {});
+    }, [packageEntry?.name, packageEntry?.isInstalled]);
+
+    const handleUpgradePolicyChange = async (policy: string) => {
+        if (!packageEntry) return;
+        const previous = upgradePolicy;
+        setUpgradePolicy(policy);
+        try {
+            await SetUpgradePolicy(packageEntry.name, policy);
+        } catch (error) {
+            console.error("Failed to set upgrade policy:", error);
+            setUpgradePolicy(previous);
+        }
+    };
+
     // Analytics fetch — with cache + isCask-aware endpoint selection
     const fetchRef = useRef<string | null>(null);
     useEffect(() => {
@@ -230,6 +260,33 @@ const PackageInfo: React.FC<PackageInfoProps> = ({ packageEntry, loadingDetailsF
                         </div>
                     ) : null}
 
+                    {/* Upgrade policy — limits what "Update All" may install */}
+                    {view !== "all" && packageEntry.isInstalled && (
+                        <div className="pi-row">
+                            <span className="pi-row-label">{t("packageInfo.labelUpgradePolicy")}</span>
+                            <select
+                                className="pi-policy-select"
+                                value={upgradePolicy}
+                                onChange={(e) => handleUpgradePolicyChange(e.target.value)}
+                            >
+                                <option value="">{t("packageInfo.policyAny")}</option>
+                                <option value="minor">{t("packageInfo.policyMinor")}</option>
+                                <option value="patch">{t("packageInfo.policyPatch")}</option>
+                                <option value="never">{t("packageInfo.policyNever")}</option>
+                                {upgradePolicy.startsWith("skip:") && (
+                                    <option value={upgradePolicy}>
+                                        {t("packageInfo.policySkip", { version: upgradePolicy.slice(5) })}
+                                    </option>
+                                )}
+                                {packageEntry.latestVersion && upgradePolicy !== `skip:${packageEntry.latestVersion}` && (
+                                    <option value={`skip:${packageEntry.latestVersion}`}>
+                                        {t("packageInfo.policySkip", { version: packageEntry.latestVersion })}
+                                    </option>
+                                )}
+                            </select>
+                        </div>
+                    )}
+
                     {/* Homepage */}
                     <div className="pi-row">
                         <span className="pi-row-label">{t("packageInfo.labelHomepage")}</span>
@@ -6,7 +6,14 @@ import {
     CircleCheckBig,
     CirclePlus,
     CircleX,
+    History,
     Info,
+    Link2,
+    Link2Off,
+    Lock,
+    Pin,
+    PinOff,
+    RotateCw,
     Square,
     Star,
     TriangleAlert,
@@ -32,6 +39,10 @@ interface PackageTableProps {
     onSelectAllPackages?: () => void;
     onDeselectAllPackages?: () => void;
     onToggleFavorite?: (pkg: PackageEntry) => void;
+    onTogglePin?: (pkg: PackageEntry) => void;
+    onInstallVersion?: (pkg: PackageEntry) => void;
+    onReinstall?: (pkg: PackageEntry) => void;
+    onToggleLink?: (pkg: PackageEntry) => void;
     sortFavoritesToTop?: boolean;
 }
 
@@ -152,6 +163,10 @@ const PackageTable = React.forwardRef<PackageTableRef, PackageTableProps>(
             onSelectAllPackages,
             onDeselectAllPackages,
             onToggleFavorite,
+            onTogglePin,
+            onInstallVersion,
+            onReinstall,
+            onToggleLink,
             sortFavoritesToTop = false,
         },
         ref,
@@ -391,7 +406,7 @@ const PackageTable = React.forwardRef<PackageTableRef, PackageTableProps>(
             if (col.key === "actions") {
                 return (
                     <div className="action-buttons">
-                        {onUpdate && (
+                        {onUpdate && !pkg.pinned && (
                             <button
                                 className="action-button update-button"
                                 onClick={(e) => {
@@ -403,6 +418,62 @@ const PackageTable = React.forwardRef<PackageTableRef, PackageTableProps>(
                                 <ArrowUpCircle size={20} />
                             </button>
                         )}
+                        {onTogglePin && !pkg.isCask && (
+                            <button
+                                className="action-button pin-button"
+                                onClick={(e) => {
+                                    e.stopPropagation();
+                                    onTogglePin(pkg);
+                                }}
+                                title={
+                                    pkg.pinned
+                                        ? t("buttons.unpin", { name: pkg.name })
+                                        : t("buttons.pin", { name: pkg.name })
+                                }
+                            >
+                                {pkg.pinned ? <PinOff size={20} /> : <Pin size={20} />}
+                            </button>
+                        )}
+                        {onInstallVersion && !pkg.isCask && (
+                            <button
+                                className="action-button"
+                                onClick={(e) => {
+                                    e.stopPropagation();
+                                    onInstallVersion(pkg);
+                                }}
+                                title={t("buttons.installVersion", { name: pkg.name })}
+                            >
+                                <History size={20} />
+                            </button>
+                        )}
+                        {onReinstall && (
+                            <button
+                                className="action-button"
+                                onClick={(e) => {
+                                    e.stopPropagation();
+                                    onReinstall(pkg);
+                                }}
+                                title={t("buttons.reinstall", { name: pkg.name })}
+                            >
+                                <RotateCw size={20} />
+                            </button>
+                        )}
+                        {onToggleLink && !pkg.isCask && pkg.linked !== undefined && (
+                            <button
+                                className="action-button"
+                                onClick={(e) => {
+                                    e.stopPropagation();
+                                    onToggleLink(pkg);
+                                }}
+                                title={
+                                    pkg.linked
+                                        ? t("buttons.unlink", { name: pkg.name })
+                                        : t("buttons.link", { name: pkg.name })
+                                }
+                            >
+                                {pkg.linked ? <Link2Off size={20} /> : <Link2 size={20} />}
+                            </button>
+                        )}
                         {onUninstall && (
                             <button
                                 className="action-button uninstall-button"
@@ -462,17 +533,50 @@ const PackageTable = React.forwardRef<PackageTableRef, PackageTableProps>(
                             {pkg.isCask ? "🖥️" : "📦"}
                         </span>
                     ) : null;
-                if (pkg.warning || typeIcon) {
+                const unlinked = pkg.linked === false;
+                if (pkg.warning || pkg.pinned || pkg.policyHeld || unlinked || typeIcon) {
                     return (
                         <div style={{ display: "inline-flex", alignItems: "center", gap: "6px" }}>
                             {typeIcon}
                             <span>{pkg.name}</span>
+                            {pkg.pinned && (
+                                <span title={t("table.pinned")} style={{ display: "inline-flex", flexShrink: 0 }}>
+                                    <Pin size={14} />
+                                </span>
+                            )}
+                            {unlinked && (
+                                <span
+                                    title={pkg.kegOnly ? t("table.kegOnly") : t("table.unlinked")}
+                                    style={{ display: "inline-flex", flexShrink: 0 }}
+                                >
+                                    <Link2Off size={14} />
+                                </span>
+                            )}
+                            {pkg.policyHeld && (
+                                <span
+                                    title={t("table.policyHeld", { policy: pkg.upgradePolicy ?? "" })}
+                                    style={{ display: "inline-flex", flexShrink: 0 }}
+                                >
+                                    <Lock size={14} />
+                                </span>
+                            )}
                             {pkg.warning && <WarningIconTooltip warning={pkg.warning} />}
                         </div>
                     );
                 }
                 return pkg.name;
             }
+            if (col.key === "latestVersion" && pkg.versionChange && pkg.versionChange !== "unknown") {
+                const value = pkg.latestVersion ?? "";
+                return (
+                    <span title={`${value} (${t(`table.versionChange.${pkg.versionChange}`)})`}>
+                        {value}
+                        {pkg.versionChange === "major" && (
+                            <span className="version-change-badge">{t("table.versionChange.major")}</span>
+                        )}
+                    </span>
+                );
+            }
             if (col.key === "installedVersion" || col.key === "latestVersion" || col.key === "size") {
                 const value = ((pkg as any)[col.key] ?? "") as string;
                 return <span title={value}>{value}</span>;
@@ -186,6 +186,11 @@ const RepositoryTable: React.FC<RepositoryTableProps> = ({
                             {t("repository.untrusted")}
                         </span>
                     )}
+                    {repo.managed && (
+                        <span className="brewfile-preview-chip" title={t("repository.managedHint")}>
+                            {t("repository.managed")}
+                        </span>
+                    )}
                     {repo.trusted === true && (
                         <span
                             style={{ color: "#3ba55d", display: "inline-flex", alignItems: "center", gap: "4px" }}
@@ -1,4 +1,5 @@
 import {
+    CalendarClock,
     Check,
     ChevronRight,
     Code2,
@@ -28,6 +29,7 @@ import {
     GetAdminUsername,
     GetAutoCleanupAfterUpgrade,
     GetAutoRelaunch,
+    GetAutoUpgrade,
     GetBrewPath,
     GetCaskAppDir,
     GetCustomCaskOpts,
@@ -37,6 +39,7 @@ import {
     GetMacOSVersion,
     GetMirrorSource,
     GetNoQuarantine,
+    GetOutdatedCheckInterval,
     GetOutdatedFlag,
     GetProxy,
     GetSortFavoritesToTop,
@@ -46,6 +49,7 @@ import {
     SetAdminUsername,
     SetAutoCleanupAfterUpgrade,
     SetAutoRelaunch,
+    SetAutoUpgrade,
     SetBrewPath,
     SetCaskAppDir,
     SetCustomCaskOpts,
@@ -53,12 +57,14 @@ import {
     SetLandingTab,
     SetMirrorSource,
     SetNoQuarantine,
+    SetOutdatedCheckInterval,
     SetOutdatedFlag,
     SetProxy,
     SetSortFavoritesToTop,
     SetUninstallCaskWithZap,
     TestProxyConnection,
 } from "../../wailsjs/go/main/App";
+import { config } from "../../wailsjs/go/models";
 
 interface SettingsViewProps {
     onRefreshPackages: () => void;
@@ -113,6 +119,16 @@ const SettingsView: React.FC<SettingsViewProps> = ({ onRefreshPackages, onSortFa
     const [savingLandingTab, setSavingLandingTab] = useState<boolean>(false);
     const [isLandingTabExpanded, setIsLandingTabExpanded] = useState<boolean>(false);
 
+    const [outdatedCheckInterval, setOutdatedCheckInterval] = useState<number>(15);
+    const [savingOutdatedCheckInterval, setSavingOutdatedCheckInterval] = useState<boolean>(false);
+    const [isOutdatedCheckIntervalExpanded, setIsOutdatedCheckIntervalExpanded] = useState<boolean>(false);
+    const [allowFrequentChecks, setAllowFrequentChecks] = useState<boolean>(true);
+
+    const [autoUpgrade, setAutoUpgrade] = useState<config.AutoUpgrade>(new config.AutoUpgrade());
+    const [newAutoUpgrade, setNewAutoUpgrade] = useState<config.AutoUpgrade>(new config.AutoUpgrade());
+    const [savingAutoUpgrade, setSavingAutoUpgrade] = useState<boolean>(false);
+    const [isAutoUpgradeExpanded, setIsAutoUpgradeExpanded] = useState<boolean>(false);
+
     const [noQuarantine, setNoQuarantine] = useState<boolean>(false);
     const [autoRelaunch, setAutoRelaunch] = useState<boolean>(true);
     const [autoCleanup, setAutoCleanup] = useState<boolean>(false);
@@ -130,6 +146,8 @@ const SettingsView: React.FC<SettingsViewProps> = ({ onRefreshPackages, onSortFa
         loadSystemInfo();
         loadCurrentProxy();
         loadLandingTab();
+        loadOutdatedCheckInterval();
+        loadAutoUpgrade();
         loadNoQuarantine();
         loadAutoRelaunch();
         loadAutoCleanup();
@@ -673,6 +691,124 @@ const SettingsView: React.FC<SettingsViewProps> = ({ onRefreshPackages, onSortFa
         toast.success(t("settings.messages.landingTabReset"));
     };
 
+    // Minutes between background outdated checks; 0 turns them off. Every
+    // check runs `brew update`, so intervals under an hour sit behind a
+    // toggle, which the 15-minute default starts switched on. Switching it
+    // off falls back to hourly checks.
+    const outdatedCheckIntervalOptions = [0, 60, 180, 360, 1440];
+    const frequentOutdatedCheckIntervalOptions = [15, 30];
+    const infrequentOutdatedCheckIntervalFallback = 60;
+    const isFrequentOutdatedCheckInterval = (minutes: number) => minutes > 0 && minutes < 60;
+
+    const formatOutdatedCheckInterval = (minutes: number): string => {
+        if (minutes === 0) return t("settings.outdatedCheckInterval.off");
+        if (minutes % 60 === 0) return t("settings.outdatedCheckInterval.hours", { count: minutes / 60 });
+        return t("settings.outdatedCheckInterval.minutes", { count: minutes });
+    };
+
+    const loadOutdatedCheckInterval = async () => {
+        try {
+            const minutes = await GetOutdatedCheckInterval();
+            setOutdatedCheckInterval(minutes);
+            setAllowFrequentChecks(isFrequentOutdatedCheckInterval(minutes));
+        } catch (error) {
+            console.error("Failed to get outdated check interval:", error);
+        }
+    };
+
+    const handleOutdatedCheckIntervalChange = async (minutes: number) => {
+        try {
+            setSavingOutdatedCheckInterval(true);
+            await SetOutdatedCheckInterval(minutes);
+            setOutdatedCheckInterval(minutes);
+            toast.success(t("settings.messages.outdatedCheckIntervalUpdated"));
+        } catch (error) {
+            console.error("Failed to set outdated check interval:", error);
+            toast.error(t("settings.errors.failedToSetOutdatedCheckInterval"));
+        } finally {
+            setSavingOutdatedCheckInterval(false);
+        }
+    };
+
+    const handleToggleFrequentChecks = async () => {
+        const allow = !allowFrequentChecks;
+        setAllowFrequentChecks(allow);
+        if (!allow && isFrequentOutdatedCheckInterval(outdatedCheckInterval)) {
+            await handleOutdatedCheckIntervalChange(infrequentOutdatedCheckIntervalFallback);
+        }
+    };
+
+    const availableOutdatedCheckIntervals = () => {
+        const options = allowFrequentChecks
+            ? [...frequentOutdatedCheckIntervalOptions, ...outdatedCheckIntervalOptions]
+            : outdatedCheckIntervalOptions;
+        return options.includes(outdatedCheckInterval) ? [...options] : [...options, outdatedCheckInterval];
+    };
+
+    const weekdayIds = ["mon", "tue", "wed", "thu", "fri", "sat", "sun"];
+
+    // The backend also accepts "weekdays" and "weekend"; the editor works on
+    // single days, and an empty list means every day.
+    const expandDays = (days?: string[]): string[] => {
+        if (!days || days.length === 0) return weekdayIds;
+        return weekdayIds.filter(
+            (day) =>
+                days.includes(day) ||
+                (days.includes("weekdays") && !["sat", "sun"].includes(day)) ||
+                (days.includes("weekend") && ["sat", "sun"].includes(day)),
+        );
+    };
+
+    const splitNames = (value: string): string[] =>
+        value
+            .split(/[\s,]+/)
+            .map((name) => name.trim())
+            .filter(Boolean);
+
+    const loadAutoUpgrade = async () => {
+        try {
+            const settings = await GetAutoUpgrade();
+            const normalized = new config.AutoUpgrade({ ...settings, days: expandDays(settings.days) });
+            setAutoUpgrade(normalized);
+            setNewAutoUpgrade(normalized);
+        } catch (error) {
+            console.error("Failed to get auto-upgrade settings:", error);
+        }
+    };
+
+    const updateAutoUpgrade = (changes: Partial<config.AutoUpgrade>) => {
+        setNewAutoUpgrade((prev) => new config.AutoUpgrade({ ...prev, ...changes }));
+    };
+
+    const toggleAutoUpgradeDay = (day: string) => {
+        const days = newAutoUpgrade.days ?? [];
+        updateAutoUpgrade({
+            days: days.includes(day)
+                ? days.filter((d) => d !== day)
+                : weekdayIds.filter((d) => d === day || days.includes(d)),
+        });
+    };
+
+    const handleSaveAutoUpgrade = async () => {
+        try {
+            setSavingAutoUpgrade(true);
+            await SetAutoUpgrade(newAutoUpgrade);
+            setAutoUpgrade(newAutoUpgrade);
+            toast.success(t("settings.messages.autoUpgradeUpdated"));
+        } catch (error) {
+            console.error("Failed to set auto-upgrade settings:", error);
+            toast.error(`${t("settings.errors.failedToSetAutoUpgrade")}: ${String(error)}`);
+        } finally {
+            setSavingAutoUpgrade(false);
+        }
+    };
+
+    const handleResetAutoUpgrade = () => {
+        setNewAutoUpgrade(autoUpgrade);
+    };
+
+    const autoUpgradeChanged = JSON.stringify(newAutoUpgrade) !== JSON.stringify(autoUpgrade);
+
     const loadNoQuarantine = async () => {
         try {
             const val = await GetNoQuarantine();
@@ -905,6 +1041,199 @@ const SettingsView: React.FC<SettingsViewProps> = ({ onRefreshPackages, onSortFa
                     </div>
                 </div>
 
+                {/* Outdated Check Interval Card */}
+                <div className={`settings-card ${isOutdatedCheckIntervalExpanded ? "expanded" : ""}`}>
+                    <button
+                        className="settings-card-header"
+                        onClick={() => setIsOutdatedCheckIntervalExpanded(!isOutdatedCheckIntervalExpanded)}
+                        aria-expanded={isOutdatedCheckIntervalExpanded}
+                    >
+                        <div className="settings-card-icon">
+                            <RefreshCw size={20} />
+                        </div>
+                        <div className="settings-card-info">
+                            <h3>{t("settings.outdatedCheckInterval.title")}</h3>
+                            <span className="settings-card-value">
+                                {formatOutdatedCheckInterval(outdatedCheckInterval)}
+                            </span>
+                        </div>
+                        <ChevronRight
+                            className={`settings-card-chevron ${isOutdatedCheckIntervalExpanded ? "rotated" : ""}`}
+                            size={20}
+                        />
+                    </button>
+
+                    <div className={`settings-card-content ${isOutdatedCheckIntervalExpanded ? "show" : ""}`}>
+                        <p className="settings-card-description">{t("settings.outdatedCheckInterval.description")}</p>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.outdatedCheckInterval.label")}</label>
+                            <select
+                                value={outdatedCheckInterval}
+                                onChange={(e) => handleOutdatedCheckIntervalChange(Number(e.target.value))}
+                                disabled={savingOutdatedCheckInterval}
+                            >
+                                {availableOutdatedCheckIntervals()
+                                    .sort((a, b) => a - b)
+                                    .map((minutes) => (
+                                        <option key={minutes} value={minutes}>
+                                            {formatOutdatedCheckInterval(minutes)}
+                                        </option>
+                                    ))}
+                            </select>
+                        </div>
+
+                        <div className="settings-input-group settings-inline-toggle">
+                            <label htmlFor="settings-frequent-checks-toggle">
+                                {t("settings.outdatedCheckInterval.allowFrequent")}
+                            </label>
+                            <button
+                                className={`settings-toggle ${allowFrequentChecks ? "active" : ""}`}
+                                onClick={handleToggleFrequentChecks}
+                                aria-checked={allowFrequentChecks}
+                                role="switch"
+                                id="settings-frequent-checks-toggle"
+                                disabled={savingOutdatedCheckInterval}
+                            />
+                        </div>
+                        <p className="settings-card-description">{t("settings.outdatedCheckInterval.frequentHint")}</p>
+                    </div>
+                </div>
+
+                {/* Auto-Upgrade Card */}
+                <div className={`settings-card ${isAutoUpgradeExpanded ? "expanded" : ""}`}>
+                    <button
+                        className="settings-card-header"
+                        onClick={() => setIsAutoUpgradeExpanded(!isAutoUpgradeExpanded)}
+                        aria-expanded={isAutoUpgradeExpanded}
+                    >
+                        <div className="settings-card-icon">
+                            <CalendarClock size={20} />
+                        </div>
+                        <div className="settings-card-info">
+                            <h3>{t("settings.autoUpgrade.title")}</h3>
+                            <span className="settings-card-value">
+                                {autoUpgrade.enabled
+                                    ? t("settings.autoUpgrade.summary", {
+                                          start: autoUpgrade.windowStart,
+                                          end: autoUpgrade.windowEnd,
+                                      })
+                                    : t("settings.autoUpgrade.off")}
+                            </span>
+                        </div>
+                        <ChevronRight
+                            className={`settings-card-chevron ${isAutoUpgradeExpanded ? "rotated" : ""}`}
+                            size={20}
+                        />
+                    </button>
+
+                    <div className={`settings-card-content ${isAutoUpgradeExpanded ? "show" : ""}`}>
+                        <p className="settings-card-description">{t("settings.autoUpgrade.description")}</p>
+
+                        <div className="settings-input-group settings-inline-toggle">
+                            <label htmlFor="settings-auto-upgrade-toggle">{t("settings.autoUpgrade.enable")}</label>
+                            <button
+                                className={`settings-toggle ${newAutoUpgrade.enabled ? "active" : ""}`}
+                                onClick={() => updateAutoUpgrade({ enabled: !newAutoUpgrade.enabled })}
+                                aria-checked={newAutoUpgrade.enabled}
+                                role="switch"
+                                id="settings-auto-upgrade-toggle"
+                            />
+                        </div>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.autoUpgrade.days")}</label>
+                            <div className="settings-day-picker">
+                                {weekdayIds.map((day) => (
+                                    <button
+                                        key={day}
+                                        className={`settings-day ${newAutoUpgrade.days?.includes(day) ? "active" : ""}`}
+                                        onClick={() => toggleAutoUpgradeDay(day)}
+                                        aria-pressed={newAutoUpgrade.days?.includes(day)}
+                                    >
+                                        {t(`settings.autoUpgrade.dayNames.${day}`)}
+                                    </button>
+                                ))}
+                            </div>
+                        </div>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.autoUpgrade.window")}</label>
+                            <div className="settings-input-row">
+                                <input
+                                    type="time"
+                                    value={newAutoUpgrade.windowStart}
+                                    onChange={(e) => updateAutoUpgrade({ windowStart: e.target.value })}
+                                />
+                                <input
+                                    type="time"
+                                    value={newAutoUpgrade.windowEnd}
+                                    onChange={(e) => updateAutoUpgrade({ windowEnd: e.target.value })}
+                                />
+                            </div>
+                        </div>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.autoUpgrade.allow")}</label>
+                            <div className="settings-input-row">
+                                <input
+                                    type="text"
+                                    value={(newAutoUpgrade.allow ?? []).join(", ")}
+                                    onChange={(e) => updateAutoUpgrade({ allow: splitNames(e.target.value) })}
+                                    placeholder={t("settings.autoUpgrade.allowPlaceholder")}
+                                />
+                            </div>
+                        </div>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.autoUpgrade.deny")}</label>
+                            <div className="settings-input-row">
+                                <input
+                                    type="text"
+                                    value={(newAutoUpgrade.deny ?? []).join(", ")}
+                                    onChange={(e) => updateAutoUpgrade({ deny: splitNames(e.target.value) })}
+                                    placeholder={t("settings.autoUpgrade.denyPlaceholder")}
+                                />
+                            </div>
+                        </div>
+
+                        <div className="settings-input-group">
+                            <label>{t("settings.autoUpgrade.maxPackages")}</label>
+                            <div className="settings-input-row">
+                                <input
+                                    type="number"
+                                    min={0}
+                                    value={newAutoUpgrade.maxPackages ?? 0}
+                                    onChange={(e) =>
+                                        updateAutoUpgrade({ maxPackages: Math.max(0, Number(e.target.value) || 0) })
+                                    }
+                                />
+                            </div>
+                        </div>
+
+                        <div className="settings-card-actions">
+                            <button
+                                className="settings-btn-secondary"
+                                onClick={handleResetAutoUpgrade}
+                                disabled={savingAutoUpgrade || !autoUpgradeChanged}
+                            >
+                                <RotateCcw size={16} />
+                                {t("settings.buttons.reset")}
+                            </button>
+                            <button
+                                className="settings-btn-primary"
+                                onClick={handleSaveAutoUpgrade}
+                                disabled={
+                                    savingAutoUpgrade || !autoUpgradeChanged || (newAutoUpgrade.days ?? []).length === 0
+                                }
+                            >
+                                {savingAutoUpgrade ? <Loader2 className="spin" size={16} /> : <Check size={16} />}
+                                {savingAutoUpgrade ? t("settings.buttons.saving") : t("settings.buttons.save")}
+                            </button>
+                        </div>
+                    </div>
+                </div>
+
                 {/* No Quarantine Toggle Card */}
                 <div className={`settings-card ${noQuarantine ? "expanded" : ""}`}>
                     <div className="settings-card-header" style={{ cursor: "default" }}>
@@ -25,7 +25,8 @@ interface SidebarProps {
     sidebarWidth?: number;
     sidebarRef?: React.RefObject<HTMLElement | null>;
     isBackgroundCheckRunning?: boolean;
-    getSecondsUntilNextCheck?: () => number;
+    // Returns null when background checks are disabled.
+    getSecondsUntilNextCheck?: () => number | null;
 }
 
 const Sidebar: React.FC<SidebarProps> = ({
@@ -53,7 +54,8 @@ const Sidebar: React.FC<SidebarProps> = ({
     const iconRef = useRef<HTMLDivElement>(null);
 
     // Format seconds into a readable countdown string
-    const formatCountdown = (seconds: number): string => {
+    const formatCountdown = (seconds: number | null): string => {
+        if (seconds === null) return t("backgroundCheck.disabled");
         if (seconds <= 0) return t("backgroundCheck.checkingNow");
         const minutes = Math.floor(seconds / 60);
         const remainingSeconds = seconds % 60;
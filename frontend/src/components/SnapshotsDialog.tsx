@@ -0,0 +1,240 @@
+import type React from "react";
+import { useCallback, useEffect, useState } from "react";
+import toast from "react-hot-toast";
+import { useTranslation } from "react-i18next";
+import {
+    CreateSnapshot,
+    DeleteSnapshot,
+    DiffSnapshots,
+    ExportSnapshot,
+    ImportSnapshot,
+    ListSnapshots,
+} from "../../wailsjs/go/main/App";
+import type { brew } from "../../wailsjs/go/models";
+
+interface SnapshotsDialogProps {
+    open: boolean;
+    onRestore: (id: string) => void;
+    onClose: () => void;
+}
+
+// An empty ID stands for the live system in DiffSnapshots.
+const LIVE = "";
+
+const SnapshotsDialog: React.FC<SnapshotsDialogProps> = ({ open, onRestore, onClose }) => {
+    const { t } = useTranslation();
+    const [snapshots, setSnapshots] = useState<brew.SnapshotSummary[]>([]);
+    const [label, setLabel] = useState("");
+    const [busy, setBusy] = useState(false);
+    const [fromId, setFromId] = useState<string>(LIVE);
+    const [toId, setToId] = useState<string>(LIVE);
+    const [diff, setDiff] = useState<brew.SnapshotDiff | null>(null);
+
+    const reload = useCallback(async () => {
+        try {
+            const list = (await ListSnapshots()) || [];
+            setSnapshots(list);
+            return list;
+        } catch (error) {
+            toast.error(t("toast.snapshotFailed", { error: String(error) }), { position: "bottom-center" });
+            return [];
+        }
+    }, [t]);
+
+    useEffect(() => {
+        if (!open) {
+            setLabel("");
+            setDiff(null);
+            return;
+        }
+        reload().then((list) => {
+            setFromId(list.length > 0 ? list[0].id : LIVE);
+            setToId(LIVE);
+        });
+    }, [open, reload]);
+
+    if (!open) return null;
+
+    const run = async (action: () => Promise<void>) => {
+        setBusy(true);
+        try {
+            await action();
+        } catch (error) {
+            toast.error(t("toast.snapshotFailed", { error: String(error) }), { position: "bottom-center" });
+        } finally {
+            setBusy(false);
+        }
+    };
+
+    const handleCreate = () =>
+        run(async () => {
+            const created = await CreateSnapshot(label);
+            setLabel("");
+            await reload();
+            if (created) setFromId(created.id);
+            toast.success(t("toast.snapshotCreated"), { position: "bottom-center" });
+        });
+
+    const handleImport = () =>
+        run(async () => {
+            const imported = await ImportSnapshot();
+            if (!imported) return;
+            await reload();
+            setFromId(imported.id);
+            toast.success(t("toast.snapshotImported", { label: imported.label }), { position: "bottom-center" });
+        });
+
+    const handleExport = (id: string) =>
+        run(async () => {
+            const path = await ExportSnapshot(id);
+            if (path) toast.success(t("toast.snapshotExported", { path }), { position: "bottom-center" });
+        });
+
+    const handleDelete = (id: string) =>
+        run(async () => {
+            await DeleteSnapshot(id);
+            if (fromId === id) setFromId(LIVE);
+            if (toId === id) setToId(LIVE);
+            setDiff(null);
+            await reload();
+        });
+
+    const handleCompare = () =>
+        run(async () => {
+            setDiff(await DiffSnapshots(fromId, toId));
+        });
+
+    const describe = (snapshot: brew.SnapshotSummary) => {
+        const name = snapshot.label || t("dialogs.snapshots.untitled");
+        return `${name} · ${new Date(snapshot.createdAt).toLocaleString()}`;
+    };
+
+    const options = (
+        <>
+            <option value={LIVE}>{t("dialogs.snapshots.live")}</option>
+            {snapshots.map((snapshot) => (
+                <option key={snapshot.id} value={snapshot.id}>
+                    {describe(snapshot)}
+                </option>
+            ))}
+        </>
+    );
+
+    const packageLines = (packages: brew.SnapshotPackageDiff) => [
+        ...packages.added.map((p) => `+ ${p.name} ${p.version}`),
+        ...packages.removed.map((p) => `− ${p.name} ${p.version}`),
+        ...packages.changed.map((c) => {
+            const pinKey = c.toPinned ? "dialogs.snapshots.pinned" : "dialogs.snapshots.unpinned";
+            const pin = c.fromPinned === c.toPinned ? "" : ` (${t(pinKey)})`;
+            return `~ ${c.name} ${c.fromVersion} → ${c.toVersion}${pin}`;
+        }),
+    ];
+    const nameLines = (names: brew.SnapshotNameDiff) => [
+        ...names.added.map((name) => `+ ${name}`),
+        ...names.removed.map((name) => `− ${name}`),
+    ];
+    const diffGroups = diff
+        ? [
+              { key: "formulae", lines: packageLines(diff.formulae) },
+              { key: "casks", lines: packageLines(diff.casks) },
+              { key: "taps", lines: nameLines(diff.taps) },
+              { key: "services", lines: nameLines(diff.services) },
+          ].filter((group) => group.lines.length > 0)
+        : [];
+
+    return (
+        <div className="confirm-overlay">
+            <div className="confirm-box brewfile-preview snapshots-dialog">
+                <p>{t("dialogs.snapshots.title")}</p>
+                <span className="brewfile-preview-summary">{t("dialogs.snapshots.hint")}</span>
+
+                <div className="dialog-input-row">
+                    <input
+                        className="dialog-input"
+                        type="text"
+                        value={label}
+                        placeholder={t("dialogs.snapshots.labelPlaceholder")}
+                        onChange={(e) => setLabel(e.target.value)}
+                        onKeyDown={(e) => e.key === "Enter" && !busy && handleCreate()}
+                    />
+                    <button onClick={handleCreate} disabled={busy}>
+                        {t("dialogs.snapshots.create")}
+                    </button>
+                    <button onClick={handleImport} disabled={busy}>
+                        {t("dialogs.snapshots.import")}
+                    </button>
+                </div>
+
+                <div className="brewfile-preview-group">
+                    {snapshots.length === 0 && (
+                        <span className="brewfile-cleanup-meta">{t("dialogs.snapshots.empty")}</span>
+                    )}
+                    {snapshots.map((snapshot) => (
+                        <div key={snapshot.id} className="snapshots-item">
+                            <span className="brewfile-cleanup-name">{describe(snapshot)}</span>
+                            <span className="brewfile-cleanup-meta">
+                                {snapshot.auto && `${t("dialogs.snapshots.auto")} · `}
+                                {snapshot.imported && `${t("dialogs.snapshots.imported", { host: snapshot.host })} · `}
+                                {t("dialogs.snapshots.counts", {
+                                    formulae: snapshot.formulae,
+                                    casks: snapshot.casks,
+                                    taps: snapshot.taps,
+                                })}
+                            </span>
+                            <div className="snapshots-item-actions">
+                                <button onClick={() => onRestore(snapshot.id)} disabled={busy}>
+                                    {t("dialogs.snapshots.restore")}
+                                </button>
+                                <button onClick={() => handleExport(snapshot.id)} disabled={busy}>
+                                    {t("dialogs.snapshots.export")}
+                                </button>
+                                <button
+                                    className="destructive"
+                                    onClick={() => handleDelete(snapshot.id)}
+                                    disabled={busy}
+                                >
+                                    {t("dialogs.snapshots.delete")}
+                                </button>
+                            </div>
+                        </div>
+                    ))}
+                </div>
+
+                <div className="dialog-input-row">
+                    <select className="dialog-input" value={fromId} onChange={(e) => setFromId(e.target.value)}>
+                        {options}
+                    </select>
+                    <span>→</span>
+                    <select className="dialog-input" value={toId} onChange={(e) => setToId(e.target.value)}>
+                        {options}
+                    </select>
+                    <button onClick={handleCompare} disabled={busy || fromId === toId}>
+                        {t("dialogs.snapshots.compare")}
+                    </button>
+                </div>
+
+                {diff && diffGroups.length === 0 && (
+                    <span className="brewfile-preview-summary">{t("dialogs.snapshots.noDifferences")}</span>
+                )}
+                {diffGroups.map((group) => (
+                    <div key={group.key} className="brewfile-preview-group">
+                        <span className="brewfile-preview-group-title">
+                            {t(`dialogs.snapshots.groups.${group.key}`)}
+                        </span>
+                        {group.lines.map((line) => (
+                            <span key={line} className="brewfile-cleanup-name">
+                                {line}
+                            </span>
+                        ))}
+                    </div>
+                ))}
+
+                <div className="confirm-actions">
+                    <button onClick={onClose}>{t("buttons.close")}</button>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default SnapshotsDialog;
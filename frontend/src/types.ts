@@ -15,6 +15,56 @@ export interface PackageEntry {
     warning?: string;
     isCask?: boolean;
     isFavorite?: boolean;
+    // Pinned formulae are skipped by upgrades until unpinned.
+    pinned?: boolean;
+    // Whether the formula is symlinked into the Homebrew prefix, and whether
+    // it is keg-only and so only linked when forced.
+    linked?: boolean;
+    kegOnly?: boolean;
+    // Upgrade policy ("never", "patch", "minor", "skip:<version>") and
+    // whether it keeps latestVersion out of upgrade-all.
+    upgradePolicy?: string;
+    policyHeld?: boolean;
+    // Most significant part of the version that an upgrade to latestVersion
+    // changes: "major", "minor", "patch", "revision", "rebuild" or "unknown".
+    versionChange?: string;
+}
+
+// Outdated package as sent by the backend's background check (brew.OutdatedPackage).
+export interface OutdatedPackage {
+    name: string;
+    installedVersion: string;
+    currentVersion: string;
+    size: string;
+    warning: string;
+    type: string;
+    pinned: boolean;
+    policy?: string;
+    policyAllowed: boolean;
+    change: string;
+}
+
+// Payload of the "outdatedChanged" event (brew.OutdatedDiff).
+export interface OutdatedDiff {
+    initial: boolean;
+    added: OutdatedPackage[];
+    updated: OutdatedPackage[];
+    removed: string[];
+    packages: OutdatedPackage[];
+    count: number;
+}
+
+// An unattended upgrade run (brew.AutoUpgradeRun).
+export interface AutoUpgradeRun {
+    startedAt: string;
+    finishedAt: string;
+    packages: string[];
+    deferred: string[];
+    success: boolean;
+    failed?: string[];
+    message: string;
+    cleanup?: string;
+    acknowledged?: boolean;
 }
 
 export interface RepositoryEntry {
@@ -23,6 +73,8 @@ export interface RepositoryEntry {
     desc?: string;
     // Homebrew 6 tap trust: true = trusted, false = untrusted, undefined = unknown
     trusted?: boolean;
+    // The local tap WailBrew extracts older formula versions into
+    managed?: boolean;
 }
 
 export type View =
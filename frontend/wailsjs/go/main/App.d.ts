@@ -2,8 +2,15 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 import {brew} from '../models';
+import {config} from '../models';
 import {context} from '../models';
 
+export function AcknowledgeAutoUpgradeFailures():Promise<void>;
+
+export function ApplyBrewfile(arg1:string):Promise<string>;
+
+export function CancelOperation(arg1:string):Promise<boolean>;
+
 export function CheckBrewLocation():Promise<main.BrewLocationSuggestion>;
 
 export function CheckForNewPackages():Promise<brew.NewPackagesInfo>;
@@ -14,10 +21,22 @@ export function CheckHomebrewUpdate():Promise<Record<string, any>>;
 
 export function ClearBrewCache():Promise<void>;
 
+export function CreateSnapshot(arg1:string):Promise<brew.SnapshotSummary>;
+
+export function DeleteSnapshot(arg1:string):Promise<void>;
+
+export function DiffSnapshots(arg1:string,arg2:string):Promise<brew.SnapshotDiff>;
+
 export function DownloadAndInstallUpdate(arg1:string):Promise<void>;
 
 export function ExportBrewfile(arg1:string):Promise<void>;
 
+export function ExportDependencyGraph(arg1:string,arg2:string,arg3:Array<string>,arg4:boolean):Promise<void>;
+
+export function ExportFilteredBrewfile(arg1:string,arg2:boolean,arg3:boolean):Promise<void>;
+
+export function ExportSnapshot(arg1:string):Promise<string>;
+
 export function GetAdminUsername():Promise<string>;
 
 export function GetAllBrewCasks():Promise<Array<any>>;
@@ -30,6 +49,14 @@ export function GetAutoCleanupAfterUpgrade():Promise<boolean>;
 
 export function GetAutoRelaunch():Promise<boolean>;
 
+export function GetAutoUpgrade():Promise<config.AutoUpgrade>;
+
+export function GetAutoUpgradeFailures():Promise<Array<brew.AutoUpgradeRun>>;
+
+export function GetAutoUpgradeRuns():Promise<Array<brew.AutoUpgradeRun>>;
+
+export function GetAutoremoveDryRun():Promise<Array<brew.OrphanedFormula>>;
+
 export function GetBrewCaskSizes(arg1:Array<string>):Promise<Record<string, string>>;
 
 export function GetBrewCasks():Promise<Array<any>>;
@@ -62,6 +89,8 @@ export function GetBrewUpdatablePackages():Promise<Array<any>>;
 
 export function GetBrewUpdatablePackagesWithUpdate():Promise<Array<any>>;
 
+export function GetBrewfileCleanup(arg1:string):Promise<Array<brew.BrewfileCleanupItem>>;
+
 export function GetCaskAppDir():Promise<string>;
 
 export function GetContext():Promise<context.Context>;
@@ -72,10 +101,14 @@ export function GetCustomCaskOpts():Promise<string>;
 
 export function GetCustomOutdatedArgs():Promise<string>;
 
+export function GetDependencyTree(arg1:string):Promise<brew.DependencyNode>;
+
 export function GetDeprecatedFormulae(arg1:string):Promise<Array<string>>;
 
 export function GetFavorites():Promise<Array<string>>;
 
+export function GetFormulaFamilies():Promise<Array<brew.FormulaFamily>>;
+
 export function GetHomebrewCaskVersion():Promise<string>;
 
 export function GetHomebrewVersion():Promise<string>;
@@ -86,14 +119,24 @@ export function GetInstalledDependents(arg1:string):Promise<Array<string>>;
 
 export function GetLandingTab():Promise<string>;
 
+export function GetLastUndoableOperation():Promise<brew.JournalEntry>;
+
 export function GetMacOSReleaseName():Promise<string>;
 
 export function GetMacOSVersion():Promise<string>;
 
 export function GetMirrorSource():Promise<Record<string, string>>;
 
+export function GetNextOutdatedCheck():Promise<number>;
+
 export function GetNoQuarantine():Promise<boolean>;
 
+export function GetOperationHistory(arg1:brew.JournalFilter):Promise<brew.JournalPage>;
+
+export function GetOperationQueue():Promise<Array<brew.Operation>>;
+
+export function GetOutdatedCheckInterval():Promise<number>;
+
 export function GetOutdatedFlag():Promise<string>;
 
 export function GetProxy():Promise<string>;
@@ -108,26 +151,62 @@ export function GetStartupDataWithUpdate():Promise<brew.StartupData>;
 
 export function GetSystemArchitecture():Promise<string>;
 
+export function GetTransitiveDependents(arg1:string):Promise<Array<string>>;
+
 export function GetTranslation(arg1:string,arg2:Record<string, string>):Promise<string>;
 
 export function GetUninstallCaskWithZap():Promise<boolean>;
 
+export function GetUpgradePolicy(arg1:string):Promise<string>;
+
+export function ImportSnapshot():Promise<brew.SnapshotSummary>;
+
 export function InstallBrewPackage(arg1:string):Promise<string>;
 
+export function InstallBrewPackageVersion(arg1:string,arg2:string,arg3:boolean):Promise<string>;
+
+export function LinkBrewPackage(arg1:string,arg2:boolean,arg3:boolean):Promise<string>;
+
+export function ListSnapshots():Promise<Array<brew.SnapshotSummary>>;
+
+export function MarkInstalledOnRequest(arg1:Array<string>):Promise<string>;
+
+export function MoveQueuedOperation(arg1:string,arg2:number):Promise<void>;
+
 export function OpenConfigFile():Promise<void>;
 
 export function OpenURL(arg1:string):Promise<void>;
 
 export function ParseNewPackagesFromUpdateOutput(arg1:string):Promise<brew.NewPackagesInfo>;
 
+export function PinBrewPackage(arg1:string):Promise<string>;
+
+export function PlanUninstall(arg1:Array<string>):Promise<brew.UninstallPlan>;
+
 export function PreviewBrewCommand(arg1:string,arg2:Array<string>,arg3:boolean,arg4:boolean):Promise<string>;
 
+export function PreviewBrewfile(arg1:string):Promise<brew.BrewfilePreview>;
+
+export function PreviewInstall(arg1:string):Promise<brew.InstallPreview>;
+
+export function PreviewLink(arg1:string):Promise<brew.LinkPreview>;
+
+export function PreviewUpgrade(arg1:Array<string>):Promise<brew.UpgradePreview>;
+
+export function ReinstallBrewPackage(arg1:string):Promise<string>;
+
 export function RemoveBrewPackage(arg1:string,arg2:boolean):Promise<string>;
 
+export function RemoveQueuedOperation(arg1:string):Promise<void>;
+
 export function RestartApp():Promise<void>;
 
 export function RestartBrewService(arg1:string):Promise<string>;
 
+export function RestoreSnapshot(arg1:string):Promise<string>;
+
+export function RunAutoremove():Promise<string>;
+
 export function RunBrewCleanup():Promise<string>;
 
 export function RunBrewCleanupDryRun():Promise<string>;
@@ -136,6 +215,8 @@ export function RunBrewDoctor():Promise<string>;
 
 export function RunBrewService(arg1:string):Promise<string>;
 
+export function RunBrewfileCleanup(arg1:string,arg2:Array<string>):Promise<string>;
+
 export function SaveWindowGeometry(arg1:number,arg2:number,arg3:number,arg4:number,arg5:boolean):Promise<void>;
 
 export function SelectCaskAppDir():Promise<string>;
@@ -146,6 +227,8 @@ export function SetAutoCleanupAfterUpgrade(arg1:boolean):Promise<void>;
 
 export function SetAutoRelaunch(arg1:boolean):Promise<void>;
 
+export function SetAutoUpgrade(arg1:config.AutoUpgrade):Promise<void>;
+
 export function SetBrewPath(arg1:string):Promise<void>;
 
 export function SetCaskAppDir(arg1:string):Promise<void>;
@@ -170,6 +253,8 @@ export function SetMirrorSource(arg1:string,arg2:string):Promise<void>;
 
 export function SetNoQuarantine(arg1:boolean):Promise<void>;
 
+export function SetOutdatedCheckInterval(arg1:number):Promise<void>;
+
 export function SetOutdatedFlag(arg1:string):Promise<void>;
 
 export function SetProxy(arg1:string):Promise<void>;
@@ -178,12 +263,16 @@ export function SetSortFavoritesToTop(arg1:boolean):Promise<void>;
 
 export function SetUninstallCaskWithZap(arg1:boolean):Promise<void>;
 
+export function SetUpgradePolicy(arg1:string,arg2:string):Promise<void>;
+
 export function SetWindowTheme(arg1:boolean):Promise<void>;
 
 export function StartBrewService(arg1:string):Promise<string>;
 
 export function StopBrewService(arg1:string):Promise<string>;
 
+export function SwitchFormulaVersion(arg1:string,arg2:string):Promise<string>;
+
 export function TapBrewRepository(arg1:string,arg2:string):Promise<string>;
 
 export function TestProxyConnection(arg1:string,arg2:string):Promise<string>;
@@ -192,6 +281,12 @@ export function ToggleFavorite(arg1:string):Promise<void>;
 
 export function TrustBrewTap(arg1:string):Promise<string>;
 
+export function UndoLastOperation():Promise<string>;
+
+export function UnlinkBrewPackage(arg1:string):Promise<string>;
+
+export function UnpinBrewPackage(arg1:string):Promise<string>;
+
 export function UntapBrewRepository(arg1:string):Promise<string>;
 
 export function UpdateAllBrewPackages():Promise<string>;
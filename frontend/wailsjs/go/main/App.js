@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AcknowledgeAutoUpgradeFailures() {
+  return window['go']['main']['App']['AcknowledgeAutoUpgradeFailures']();
+}
+
+export function ApplyBrewfile(arg1) {
+  return window['go']['main']['App']['ApplyBrewfile'](arg1);
+}
+
+export function CancelOperation(arg1) {
+  return window['go']['main']['App']['CancelOperation'](arg1);
+}
+
 export function CheckBrewLocation() {
   return window['go']['main']['App']['CheckBrewLocation']();
 }
@@ -22,6 +34,18 @@ export function ClearBrewCache() {
   return window['go']['main']['App']['ClearBrewCache']();
 }
 
+export function CreateSnapshot(arg1) {
+  return window['go']['main']['App']['CreateSnapshot'](arg1);
+}
+
+export function DeleteSnapshot(arg1) {
+  return window['go']['main']['App']['DeleteSnapshot'](arg1);
+}
+
+export function DiffSnapshots(arg1, arg2) {
+  return window['go']['main']['App']['DiffSnapshots'](arg1, arg2);
+}
+
 export function DownloadAndInstallUpdate(arg1) {
   return window['go']['main']['App']['DownloadAndInstallUpdate'](arg1);
 }
@@ -30,6 +54,18 @@ export function ExportBrewfile(arg1) {
   return window['go']['main']['App']['ExportBrewfile'](arg1);
 }
 
+export function ExportDependencyGraph(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportDependencyGraph'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportFilteredBrewfile(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportFilteredBrewfile'](arg1, arg2, arg3);
+}
+
+export function ExportSnapshot(arg1) {
+  return window['go']['main']['App']['ExportSnapshot'](arg1);
+}
+
 export function GetAdminUsername() {
   return window['go']['main']['App']['GetAdminUsername']();
 }
@@ -54,6 +90,22 @@ export function GetAutoRelaunch() {
   return window['go']['main']['App']['GetAutoRelaunch']();
 }
 
+export function GetAutoUpgrade() {
+  return window['go']['main']['App']['GetAutoUpgrade']();
+}
+
+export function GetAutoUpgradeFailures() {
+  return window['go']['main']['App']['GetAutoUpgradeFailures']();
+}
+
+export function GetAutoUpgradeRuns() {
+  return window['go']['main']['App']['GetAutoUpgradeRuns']();
+}
+
+export function GetAutoremoveDryRun() {
+  return window['go']['main']['App']['GetAutoremoveDryRun']();
+}
+
 export function GetBrewCaskSizes(arg1) {
   return window['go']['main']['App']['GetBrewCaskSizes'](arg1);
 }
@@ -118,6 +170,10 @@ export function GetBrewUpdatablePackagesWithUpdate() {
   return window['go']['main']['App']['GetBrewUpdatablePackagesWithUpdate']();
 }
 
+export function GetBrewfileCleanup(arg1) {
+  return window['go']['main']['App']['GetBrewfileCleanup'](arg1);
+}
+
 export function GetCaskAppDir() {
   return window['go']['main']['App']['GetCaskAppDir']();
 }
@@ -138,6 +194,10 @@ export function GetCustomOutdatedArgs() {
   return window['go']['main']['App']['GetCustomOutdatedArgs']();
 }
 
+export function GetDependencyTree(arg1) {
+  return window['go']['main']['App']['GetDependencyTree'](arg1);
+}
+
 export function GetDeprecatedFormulae(arg1) {
   return window['go']['main']['App']['GetDeprecatedFormulae'](arg1);
 }
@@ -146,6 +206,10 @@ export function GetFavorites() {
   return window['go']['main']['App']['GetFavorites']();
 }
 
+export function GetFormulaFamilies() {
+  return window['go']['main']['App']['GetFormulaFamilies']();
+}
+
 export function GetHomebrewCaskVersion() {
   return window['go']['main']['App']['GetHomebrewCaskVersion']();
 }
@@ -166,6 +230,10 @@ export function GetLandingTab() {
   return window['go']['main']['App']['GetLandingTab']();
 }
 
+export function GetLastUndoableOperation() {
+  return window['go']['main']['App']['GetLastUndoableOperation']();
+}
+
 export function GetMacOSReleaseName() {
   return window['go']['main']['App']['GetMacOSReleaseName']();
 }
@@ -178,10 +246,26 @@ export function GetMirrorSource() {
   return window['go']['main']['App']['GetMirrorSource']();
 }
 
+export function GetNextOutdatedCheck() {
+  return window['go']['main']['App']['GetNextOutdatedCheck']();
+}
+
 export function GetNoQuarantine() {
   return window['go']['main']['App']['GetNoQuarantine']();
 }
 
+export function GetOperationHistory(arg1) {
+  return window['go']['main']['App']['GetOperationHistory'](arg1);
+}
+
+export function GetOperationQueue() {
+  return window['go']['main']['App']['GetOperationQueue']();
+}
+
+export function GetOutdatedCheckInterval() {
+  return window['go']['main']['App']['GetOutdatedCheckInterval']();
+}
+
 export function GetOutdatedFlag() {
   return window['go']['main']['App']['GetOutdatedFlag']();
 }
@@ -210,6 +294,10 @@ export function GetSystemArchitecture() {
   return window['go']['main']['App']['GetSystemArchitecture']();
 }
 
+export function GetTransitiveDependents(arg1) {
+  return window['go']['main']['App']['GetTransitiveDependents'](arg1);
+}
+
 export function GetTranslation(arg1, arg2) {
   return window['go']['main']['App']['GetTranslation'](arg1, arg2);
 }
@@ -218,10 +306,38 @@ export function GetUninstallCaskWithZap() {
   return window['go']['main']['App']['GetUninstallCaskWithZap']();
 }
 
+export function GetUpgradePolicy(arg1) {
+  return window['go']['main']['App']['GetUpgradePolicy'](arg1);
+}
+
+export function ImportSnapshot() {
+  return window['go']['main']['App']['ImportSnapshot']();
+}
+
 export function InstallBrewPackage(arg1) {
   return window['go']['main']['App']['InstallBrewPackage'](arg1);
 }
 
+export function InstallBrewPackageVersion(arg1, arg2, arg3) {
+  return window['go']['main']['App']['InstallBrewPackageVersion'](arg1, arg2, arg3);
+}
+
+export function LinkBrewPackage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['LinkBrewPackage'](arg1, arg2, arg3);
+}
+
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
+export function MarkInstalledOnRequest(arg1) {
+  return window['go']['main']['App']['MarkInstalledOnRequest'](arg1);
+}
+
+export function MoveQueuedOperation(arg1, arg2) {
+  return window['go']['main']['App']['MoveQueuedOperation'](arg1, arg2);
+}
+
 export function OpenConfigFile() {
   return window['go']['main']['App']['OpenConfigFile']();
 }
@@ -234,14 +350,46 @@ export function ParseNewPackagesFromUpdateOutput(arg1) {
   return window['go']['main']['App']['ParseNewPackagesFromUpdateOutput'](arg1);
 }
 
+export function PinBrewPackage(arg1) {
+  return window['go']['main']['App']['PinBrewPackage'](arg1);
+}
+
+export function PlanUninstall(arg1) {
+  return window['go']['main']['App']['PlanUninstall'](arg1);
+}
+
 export function PreviewBrewCommand(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['PreviewBrewCommand'](arg1, arg2, arg3, arg4);
 }
 
+export function PreviewBrewfile(arg1) {
+  return window['go']['main']['App']['PreviewBrewfile'](arg1);
+}
+
+export function PreviewInstall(arg1) {
+  return window['go']['main']['App']['PreviewInstall'](arg1);
+}
+
+export function PreviewLink(arg1) {
+  return window['go']['main']['App']['PreviewLink'](arg1);
+}
+
+export function PreviewUpgrade(arg1) {
+  return window['go']['main']['App']['PreviewUpgrade'](arg1);
+}
+
+export function ReinstallBrewPackage(arg1) {
+  return window['go']['main']['App']['ReinstallBrewPackage'](arg1);
+}
+
 export function RemoveBrewPackage(arg1, arg2) {
   return window['go']['main']['App']['RemoveBrewPackage'](arg1, arg2);
 }
 
+export function RemoveQueuedOperation(arg1) {
+  return window['go']['main']['App']['RemoveQueuedOperation'](arg1);
+}
+
 export function RestartApp() {
   return window['go']['main']['App']['RestartApp']();
 }
@@ -250,6 +398,14 @@ export function RestartBrewService(arg1) {
   return window['go']['main']['App']['RestartBrewService'](arg1);
 }
 
+export function RestoreSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1);
+}
+
+export function RunAutoremove() {
+  return window['go']['main']['App']['RunAutoremove']();
+}
+
 export function RunBrewCleanup() {
   return window['go']['main']['App']['RunBrewCleanup']();
 }
@@ -266,6 +422,10 @@ export function RunBrewService(arg1) {
   return window['go']['main']['App']['RunBrewService'](arg1);
 }
 
+export function RunBrewfileCleanup(arg1, arg2) {
+  return window['go']['main']['App']['RunBrewfileCleanup'](arg1, arg2);
+}
+
 export function SaveWindowGeometry(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['SaveWindowGeometry'](arg1, arg2, arg3, arg4, arg5);
 }
@@ -286,6 +446,10 @@ export function SetAutoRelaunch(arg1) {
   return window['go']['main']['App']['SetAutoRelaunch'](arg1);
 }
 
+export function SetAutoUpgrade(arg1) {
+  return window['go']['main']['App']['SetAutoUpgrade'](arg1);
+}
+
 export function SetBrewPath(arg1) {
   return window['go']['main']['App']['SetBrewPath'](arg1);
 }
@@ -334,6 +498,10 @@ export function SetNoQuarantine(arg1) {
   return window['go']['main']['App']['SetNoQuarantine'](arg1);
 }
 
+export function SetOutdatedCheckInterval(arg1) {
+  return window['go']['main']['App']['SetOutdatedCheckInterval'](arg1);
+}
+
 export function SetOutdatedFlag(arg1) {
   return window['go']['main']['App']['SetOutdatedFlag'](arg1);
 }
@@ -350,6 +518,10 @@ export function SetUninstallCaskWithZap(arg1) {
   return window['go']['main']['App']['SetUninstallCaskWithZap'](arg1);
 }
 
+export function SetUpgradePolicy(arg1, arg2) {
+  return window['go']['main']['App']['SetUpgradePolicy'](arg1, arg2);
+}
+
 export function SetWindowTheme(arg1) {
   return window['go']['main']['App']['SetWindowTheme'](arg1);
 }
@@ -362,6 +534,10 @@ export function StopBrewService(arg1) {
   return window['go']['main']['App']['StopBrewService'](arg1);
 }
 
+export function SwitchFormulaVersion(arg1, arg2) {
+  return window['go']['main']['App']['SwitchFormulaVersion'](arg1, arg2);
+}
+
 export function TapBrewRepository(arg1, arg2) {
   return window['go']['main']['App']['TapBrewRepository'](arg1, arg2);
 }
@@ -378,6 +554,18 @@ export function TrustBrewTap(arg1) {
   return window['go']['main']['App']['TrustBrewTap'](arg1);
 }
 
+export function UndoLastOperation() {
+  return window['go']['main']['App']['UndoLastOperation']();
+}
+
+export function UnlinkBrewPackage(arg1) {
+  return window['go']['main']['App']['UnlinkBrewPackage'](arg1);
+}
+
+export function UnpinBrewPackage(arg1) {
+  return window['go']['main']['App']['UnpinBrewPackage'](arg1);
+}
+
 export function UntapBrewRepository(arg1) {
   return window['go']['main']['App']['UntapBrewRepository'](arg1);
 }
@@ -1,5 +1,460 @@
 export namespace brew {
 	
+	export class AutoUpgradeRun {
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    finishedAt: any;
+	    packages: string[];
+	    deferred: string[];
+	    success: boolean;
+	    failed?: string[];
+	    message: string;
+	    cleanup?: string;
+	    acknowledged?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoUpgradeRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.finishedAt = this.convertValues(source["finishedAt"], null);
+	        this.packages = source["packages"];
+	        this.deferred = source["deferred"];
+	        this.success = source["success"];
+	        this.failed = source["failed"];
+	        this.message = source["message"];
+	        this.cleanup = source["cleanup"];
+	        this.acknowledged = source["acknowledged"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BrewfileCleanupItem {
+	    name: string;
+	    type: string;
+	    size: string;
+	    dependents: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BrewfileCleanupItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.size = source["size"];
+	        this.dependents = source["dependents"];
+	    }
+	}
+	export class BrewfileEntryStatus {
+	    name: string;
+	    type: string;
+	    status: string;
+	    conditional?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrewfileEntryStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.status = source["status"];
+	        this.conditional = source["conditional"];
+	    }
+	}
+	export class BrewfilePreview {
+	    path: string;
+	    command: string;
+	    entries: BrewfileEntryStatus[];
+	    taps: string[];
+	    install: string[];
+	    upgrade: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BrewfilePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.command = source["command"];
+	        this.entries = this.convertValues(source["entries"], BrewfileEntryStatus);
+	        this.taps = source["taps"];
+	        this.install = source["install"];
+	        this.upgrade = source["upgrade"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DependencyNode {
+	    name: string;
+	    dependencies: DependencyNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DependencyNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.dependencies = this.convertValues(source["dependencies"], DependencyNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FamilyMember {
+	    name: string;
+	    version: string;
+	    installed: boolean;
+	    linked: boolean;
+	    kegOnly: boolean;
+	    serviceRunning: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FamilyMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.installed = source["installed"];
+	        this.linked = source["linked"];
+	        this.kegOnly = source["kegOnly"];
+	        this.serviceRunning = source["serviceRunning"];
+	    }
+	}
+	export class FormulaFamily {
+	    name: string;
+	    members: FamilyMember[];
+	    linked: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormulaFamily(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.members = this.convertValues(source["members"], FamilyMember);
+	        this.linked = source["linked"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InstallPreviewEntry {
+	    name: string;
+	    type: string;
+	    version: string;
+	    dependency: boolean;
+	    bottle: boolean;
+	    bottleSize: string;
+	    cached: boolean;
+	    caveats: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InstallPreviewEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.version = source["version"];
+	        this.dependency = source["dependency"];
+	        this.bottle = source["bottle"];
+	        this.bottleSize = source["bottleSize"];
+	        this.cached = source["cached"];
+	        this.caveats = source["caveats"];
+	    }
+	}
+	export class InstallPreview {
+	    name: string;
+	    command: string;
+	    alreadyInstalled: boolean;
+	    packages: InstallPreviewEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new InstallPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.command = source["command"];
+	        this.alreadyInstalled = source["alreadyInstalled"];
+	        this.packages = this.convertValues(source["packages"], InstallPreviewEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class JournalChange {
+	    name: string;
+	    from: string;
+	    to: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class JournalCommand {
+	    args: string[];
+	    exitCode: number;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.args = source["args"];
+	        this.exitCode = source["exitCode"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class JournalInverse {
+	    kind: string;
+	    target: string;
+	    version?: string;
+	    url?: string;
+	    args: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalInverse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.target = source["target"];
+	        this.version = source["version"];
+	        this.url = source["url"];
+	        this.args = source["args"];
+	    }
+	}
+	export class JournalEntry {
+	    id: string;
+	    kind: string;
+	    target: string;
+	    user: string;
+	    // Go type: time
+	    startedAt: any;
+	    durationMs: number;
+	    success: boolean;
+	    result: string;
+	    commands: JournalCommand[];
+	    changes: JournalChange[];
+	    inverse?: JournalInverse;
+	    undoOf?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.target = source["target"];
+	        this.user = source["user"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.durationMs = source["durationMs"];
+	        this.success = source["success"];
+	        this.result = source["result"];
+	        this.commands = this.convertValues(source["commands"], JournalCommand);
+	        this.changes = this.convertValues(source["changes"], JournalChange);
+	        this.inverse = this.convertValues(source["inverse"], JournalInverse);
+	        this.undoOf = source["undoOf"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JournalFilter {
+	    package: string;
+	    kind: string;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.package = source["package"];
+	        this.kind = source["kind"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	}
+	
+	export class JournalPage {
+	    entries: JournalEntry[];
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JournalPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], JournalEntry);
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class LinkPreview {
+	    name: string;
+	    links: string[];
+	    conflicts: string[];
+	    kegOnly: boolean;
+	    alreadyLinked: boolean;
+	    command: string;
+	    overwriteCommand: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LinkPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.links = source["links"];
+	        this.conflicts = source["conflicts"];
+	        this.kegOnly = source["kegOnly"];
+	        this.alreadyLinked = source["alreadyLinked"];
+	        this.command = source["command"];
+	        this.overwriteCommand = source["overwriteCommand"];
+	    }
+	}
 	export class NewPackagesInfo {
 	    newFormulae: string[];
 	    newCasks: string[];
@@ -14,6 +469,254 @@ export namespace brew {
 	        this.newCasks = source["newCasks"];
 	    }
 	}
+	export class Operation {
+	    id: string;
+	    kind: string;
+	    target: string;
+	    status: string;
+	    // Go type: time
+	    queuedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Operation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.target = source["target"];
+	        this.status = source["status"];
+	        this.queuedAt = this.convertValues(source["queuedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OrphanedFormula {
+	    name: string;
+	    size: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrphanedFormula(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	    }
+	}
+	export class ServiceEntry {
+	    name: string;
+	    status: string;
+	    user: string;
+	    file: string;
+	    exit_code?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ServiceEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.user = source["user"];
+	        this.file = source["file"];
+	        this.exit_code = source["exit_code"];
+	    }
+	}
+	export class SnapshotNameDiff {
+	    added: string[];
+	    removed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotNameDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	    }
+	}
+	export class SnapshotPackageChange {
+	    name: string;
+	    fromVersion: string;
+	    toVersion: string;
+	    fromPinned: boolean;
+	    toPinned: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotPackageChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.fromVersion = source["fromVersion"];
+	        this.toVersion = source["toVersion"];
+	        this.fromPinned = source["fromPinned"];
+	        this.toPinned = source["toPinned"];
+	    }
+	}
+	export class SnapshotPackage {
+	    name: string;
+	    version: string;
+	    pinned?: boolean;
+	    onRequest?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotPackage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.pinned = source["pinned"];
+	        this.onRequest = source["onRequest"];
+	    }
+	}
+	export class SnapshotPackageDiff {
+	    added: SnapshotPackage[];
+	    removed: SnapshotPackage[];
+	    changed: SnapshotPackageChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotPackageDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = this.convertValues(source["added"], SnapshotPackage);
+	        this.removed = this.convertValues(source["removed"], SnapshotPackage);
+	        this.changed = this.convertValues(source["changed"], SnapshotPackageChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SnapshotDiff {
+	    formulae: SnapshotPackageDiff;
+	    casks: SnapshotPackageDiff;
+	    taps: SnapshotNameDiff;
+	    services: SnapshotNameDiff;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.formulae = this.convertValues(source["formulae"], SnapshotPackageDiff);
+	        this.casks = this.convertValues(source["casks"], SnapshotPackageDiff);
+	        this.taps = this.convertValues(source["taps"], SnapshotNameDiff);
+	        this.services = this.convertValues(source["services"], SnapshotNameDiff);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	
+	export class SnapshotSummary {
+	    id: string;
+	    label: string;
+	    // Go type: time
+	    createdAt: any;
+	    host: string;
+	    auto: boolean;
+	    imported: boolean;
+	    formulae: number;
+	    casks: number;
+	    taps: number;
+	    services: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.host = source["host"];
+	        this.auto = source["auto"];
+	        this.imported = source["imported"];
+	        this.formulae = source["formulae"];
+	        this.casks = source["casks"];
+	        this.taps = source["taps"];
+	        this.services = source["services"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class StartupData {
 	    packages: string[][];
 	    casks: string[][];
@@ -34,6 +737,151 @@ export namespace brew {
 	        this.taps = source["taps"];
 	    }
 	}
+	export class UninstallBlocker {
+	    name: string;
+	    dependents: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UninstallBlocker(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.dependents = source["dependents"];
+	    }
+	}
+	export class UninstallPlan {
+	    packages: string[];
+	    notInstalled: string[];
+	    blockers: UninstallBlocker[];
+	    blocked: boolean;
+	    orphans: OrphanedFormula[];
+	    services: ServiceEntry[];
+	    reclaimedSize: string;
+	    orphanedSize: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UninstallPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.packages = source["packages"];
+	        this.notInstalled = source["notInstalled"];
+	        this.blockers = this.convertValues(source["blockers"], UninstallBlocker);
+	        this.blocked = source["blocked"];
+	        this.orphans = this.convertValues(source["orphans"], OrphanedFormula);
+	        this.services = this.convertValues(source["services"], ServiceEntry);
+	        this.reclaimedSize = source["reclaimedSize"];
+	        this.orphanedSize = source["orphanedSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpgradePreviewEntry {
+	    name: string;
+	    type: string;
+	    fromVersion: string;
+	    toVersion: string;
+	    dependencyOnly: boolean;
+	    requiredBy?: string;
+	    greedy: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpgradePreviewEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.fromVersion = source["fromVersion"];
+	        this.toVersion = source["toVersion"];
+	        this.dependencyOnly = source["dependencyOnly"];
+	        this.requiredBy = source["requiredBy"];
+	        this.greedy = source["greedy"];
+	    }
+	}
+	export class UpgradePreview {
+	    command: string;
+	    packages: UpgradePreviewEntry[];
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UpgradePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.command = source["command"];
+	        this.packages = this.convertValues(source["packages"], UpgradePreviewEntry);
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace config {
+	
+	export class AutoUpgrade {
+	    enabled: boolean;
+	    days?: string[];
+	    windowStart: string;
+	    windowEnd: string;
+	    allow?: string[];
+	    deny?: string[];
+	    maxPackages?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoUpgrade(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.days = source["days"];
+	        this.windowStart = source["windowStart"];
+	        this.windowEnd = source["windowEnd"];
+	        this.allow = source["allow"];
+	        this.deny = source["deny"];
+	        this.maxPackages = source["maxPackages"];
+	    }
+	}
 
 }
 